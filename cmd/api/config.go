@@ -0,0 +1,306 @@
+package main
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/datastores/sqlite3"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/logging"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// We declare the components that can be selected in the configuration.
+const (
+	nomadExecutor  = "nomad"
+	dockerExecutor = "docker"
+	mockExecutor   = "mock"
+
+	topologicalGroupedScheduler = "topologicalgrouped"
+
+	sqlite3Datastore = "sqlite3"
+	memoryDatastore  = "memory"
+)
+
+// validChoices contains the valid values of the fields that select a component.
+var validChoices = map[string][]string{
+	"executor":  {dockerExecutor, mockExecutor, nomadExecutor},
+	"scheduler": {topologicalGroupedScheduler},
+	"datastore": {memoryDatastore, sqlite3Datastore},
+}
+
+// configEnv is the environment variable that contains the path of the configuration file.
+const configEnv = "HECTOR_CONFIG"
+
+// nomadConfig contains the settings of the nomad executor, which are also applied to the exported
+// nomad jobs, together with those of the tasks that make the exported jobs wait for their dependencies.
+type nomadConfig struct {
+	Address      string   `json:"address"`
+	Region       string   `json:"region"`
+	Datacenters  []string `json:"datacenters"`
+	AwaitImage   string   `json:"awaitImage"`
+	AwaitAddress string   `json:"awaitAddress"`
+}
+
+// webhooksConfig contains the settings of the webhooks: those notified for all the definitions, the
+// secret used to sign their payloads (the webhooks are disabled without it) and the hosts that the
+// webhooks of the definitions can point to.
+type webhooksConfig struct {
+	Urls         []string `json:"urls"`
+	Secret       string   `json:"secret"`
+	AllowedHosts []string `json:"allowedHosts"`
+}
+
+// config contains the settings of the server. They are read from the configuration file (JSON or
+// YAML), then from the environment variables and finally from the flags of the command line, each
+// of them overriding the previous ones.
+type config struct {
+	Listen       string         `json:"listen"`
+	Executor     string         `json:"executor"`
+	Scheduler    string         `json:"scheduler"`
+	Datastore    string         `json:"datastore"`
+	DatabasePath string         `json:"databasePath"`
+	Workers      int            `json:"workers"` // Number of definitions that can be executed simultaneously
+	Nomad        nomadConfig    `json:"nomad"`
+	Webhooks     webhooksConfig `json:"webhooks"`
+	AdminToken   string         `json:"adminToken"` // Secret of the bootstrap admin token
+	LogLevel     string         `json:"logLevel"`
+	LogFormat    string         `json:"logFormat"`
+	TraceFile    string         `json:"traceFile"`
+}
+
+// defaultConfig function returns the settings used when they are not configured.
+func defaultConfig() config {
+	return config{
+		Listen:       ":8080",
+		Executor:     nomadExecutor,
+		Scheduler:    topologicalGroupedScheduler,
+		Datastore:    sqlite3Datastore,
+		DatabasePath: sqlite3.DefaultPath,
+		Workers:      4,
+		LogLevel:     "info",
+		LogFormat:    logging.JSONFormat,
+	}
+}
+
+// setting describes a field of the configuration that can be overridden by an environment variable
+// and a flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	value func(*config) *string
+}
+
+// settings function returns the fields of the configuration that can be overridden.
+func settings() []setting {
+	return []setting{
+		{"HECTOR_LISTEN", "listen", "address where the api listens (e.g. :8080)", func(c *config) *string { return &c.Listen }},
+		{"HECTOR_EXECUTOR", "executor", "executor of the jobs (" + strings.Join(validChoices["executor"], ", ") + ")", func(c *config) *string { return &c.Executor }},
+		{"HECTOR_SCHEDULER", "scheduler", "scheduler of the specifications (" + strings.Join(validChoices["scheduler"], ", ") + ")", func(c *config) *string { return &c.Scheduler }},
+		{"HECTOR_DATASTORE", "datastore", "datastore of the elements (" + strings.Join(validChoices["datastore"], ", ") + ")", func(c *config) *string { return &c.Datastore }},
+		{"HECTOR_DATABASE_PATH", "db", "path of the sqlite3 database file", func(c *config) *string { return &c.DatabasePath }},
+		{"HECTOR_NOMAD_ADDRESS", "nomad-address", "address of the nomad agent (e.g. http://127.0.0.1:4646)", func(c *config) *string { return &c.Nomad.Address }},
+		{"HECTOR_NOMAD_REGION", "nomad-region", "nomad region of the jobs", func(c *config) *string { return &c.Nomad.Region }},
+		{"HECTOR_NOMAD_AWAIT_IMAGE", "nomad-await-image", "image of the tasks that make the exported nomad jobs wait for their dependencies (needs sh, curl and grep)", func(c *config) *string { return &c.Nomad.AwaitImage }},
+		{"HECTOR_NOMAD_AWAIT_ADDRESS", "nomad-await-address", "address of the nomad api seen from the tasks of the exported jobs when there is no task api socket", func(c *config) *string { return &c.Nomad.AwaitAddress }},
+		{"HECTOR_WEBHOOK_SECRET", "webhook-secret", "secret used to sign the webhook payloads (the webhooks are disabled if empty)", func(c *config) *string { return &c.Webhooks.Secret }},
+		{"HECTOR_ADMIN_TOKEN", "admin-token", "secret of the bootstrap admin token (it is revoked if empty)", func(c *config) *string { return &c.AdminToken }},
+		{"HECTOR_LOG_LEVEL", "log-level", "minimum level of the logged lines (debug, info, warn, error)", func(c *config) *string { return &c.LogLevel }},
+		{"HECTOR_LOG_FORMAT", "log-format", "format of the logged lines (" + strings.Join(logging.Formats, ", ") + ")", func(c *config) *string { return &c.LogFormat }},
+		{"HECTOR_TRACE_FILE", "trace-file", "file where the spans are written as JSON lines (tracing is disabled if empty)", func(c *config) *string { return &c.TraceFile }},
+	}
+}
+
+// listSetting describes a field of the configuration that is a list, which the environment variable
+// and the flag give as a comma separated list.
+type listSetting struct {
+	env   string
+	flag  string
+	usage string
+	value func(*config) *[]string
+}
+
+// listSettings function returns the fields of the configuration that are lists and can be overridden.
+func listSettings() []listSetting {
+	return []listSetting{
+		{"HECTOR_NOMAD_DATACENTERS", "nomad-datacenters", "comma separated nomad datacenters of the jobs", func(c *config) *[]string { return &c.Nomad.Datacenters }},
+		{"HECTOR_WEBHOOK_URLS", "webhook-urls", "comma separated webhooks notified for all the definitions", func(c *config) *[]string { return &c.Webhooks.Urls }},
+		{"HECTOR_WEBHOOK_ALLOWED_HOSTS", "webhook-allowed-hosts", "comma separated hosts that the webhooks of the definitions can point to (any public host if empty)", func(c *config) *[]string { return &c.Webhooks.AllowedHosts }},
+	}
+}
+
+// We declare the override of the number of workers, which is a number.
+const (
+	workersEnv  = "HECTOR_WORKERS"
+	workersFlag = "workers"
+)
+
+// loadConfig function builds the configuration of the server from the defaults, the configuration
+// file, the environment variables and the flags, and validates it. The file is taken from the
+// -config flag or the HECTOR_CONFIG environment variable (none is read if neither is set). It takes
+// as input the arguments of the command line, the function that returns the environment variables
+// and the writer of the usage message. Returns the configuration and an error variable to report
+// any problems.
+func loadConfig(args []string, getenv func(string) string, usage io.Writer) (*config, error) {
+	cfg := defaultConfig()
+
+	// We declare the flags, which are applied once the file and the environment have been read
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	flags.SetOutput(usage)
+	configPath := flags.String("config", getenv(configEnv), "path of the configuration file (json or yaml)")
+	flagValues := map[string]*string{}
+	for _, s := range settings() {
+		flagValues[s.flag] = flags.String(s.flag, "", s.usage+" [$"+s.env+"]")
+	}
+	for _, s := range listSettings() {
+		flagValues[s.flag] = flags.String(s.flag, "", s.usage+" [$"+s.env+"]")
+	}
+	workers := flags.Int(workersFlag, 0, "number of definitions that can be executed simultaneously [$"+workersEnv+"]")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %s", strings.Join(flags.Args(), " "))
+	}
+
+	// 1. Configuration file
+	if *configPath != "" {
+		content, err := pkg.ReadFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := formats.Unmarshal(content, formats.FromFileName(*configPath), &cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", *configPath, err)
+		}
+		// We reject the unknown fields, which are usually misspelled settings
+		if err := yaml.UnmarshalStrict(content, &config{}); err != nil {
+			return nil, fmt.Errorf("%s: %w", *configPath, err)
+		}
+	}
+
+	// 2. Environment variables and 3. flags
+	for _, s := range settings() {
+		if value := getenv(s.env); value != "" {
+			*s.value(&cfg) = value
+		}
+	}
+	for _, s := range listSettings() {
+		if value := getenv(s.env); value != "" {
+			*s.value(&cfg) = splitList(value)
+		}
+	}
+	if value := getenv(workersEnv); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q", workersEnv, value)
+		}
+		cfg.Workers = number
+	}
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings() {
+			if s.flag == f.Name {
+				*s.value(&cfg) = *flagValues[f.Name]
+			}
+		}
+		for _, s := range listSettings() {
+			if s.flag == f.Name {
+				*s.value(&cfg) = splitList(*flagValues[f.Name])
+			}
+		}
+		if f.Name == workersFlag {
+			cfg.Workers = *workers
+		}
+	})
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// splitList function splits a comma separated list, trimming the spaces around its items.
+func splitList(value string) []string {
+	items := strings.Split(value, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// validate function checks that the configuration can be used to raise the server, collecting all
+// its problems. Returns an error variable that lists them (nil if there is none).
+func (c *config) validate() error {
+	var problems []string
+
+	// We check the selected components
+	for _, field := range []struct{ name, value string }{{"executor", c.Executor}, {"scheduler", c.Scheduler}, {"datastore", c.Datastore}} {
+		choices := validChoices[field.name]
+		if !pkg.Contains(choices, field.value) {
+			problems = append(problems, fmt.Sprintf("%s: unknown %s %q (the valid ones are %s)", field.name, field.name, field.value, strings.Join(choices, ", ")))
+		}
+	}
+	if c.Datastore == sqlite3Datastore && c.DatabasePath == "" {
+		problems = append(problems, "databasePath: the sqlite3 datastore needs the path of the database file")
+	}
+
+	// We check the number of workers
+	if c.Workers < 1 {
+		problems = append(problems, fmt.Sprintf("workers: invalid number %d (at least one worker is needed)", c.Workers))
+	}
+
+	// We check the listen address
+	if _, port, err := net.SplitHostPort(c.Listen); err != nil || port == "" {
+		problems = append(problems, fmt.Sprintf("listen: invalid address %q (expected host:port or :port)", c.Listen))
+	}
+
+	// We check the nomad settings
+	if c.Nomad.Address != "" {
+		if address, err := url.Parse(c.Nomad.Address); err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+			problems = append(problems, fmt.Sprintf("nomad.address: invalid address %q (expected an http or https url)", c.Nomad.Address))
+		}
+	}
+	if c.Nomad.AwaitAddress != "" && !strings.HasPrefix(c.Nomad.AwaitAddress, "http://") && !strings.HasPrefix(c.Nomad.AwaitAddress, "https://") {
+		problems = append(problems, fmt.Sprintf("nomad.awaitAddress: invalid address %q (expected an http or https url)", c.Nomad.AwaitAddress))
+	}
+	for i, datacenter := range c.Nomad.Datacenters {
+		if strings.TrimSpace(datacenter) == "" {
+			problems = append(problems, fmt.Sprintf("nomad.datacenters[%d]: empty datacenter", i))
+		}
+	}
+
+	// We check the webhook settings
+	for i, webhookUrl := range c.Webhooks.Urls {
+		if parsed, err := url.Parse(webhookUrl); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("webhooks.urls[%d]: invalid url %q (expected an http or https url)", i, webhookUrl))
+		}
+	}
+	if len(c.Webhooks.Urls) > 0 && c.Webhooks.Secret == "" {
+		problems = append(problems, "webhooks.secret: the webhooks need a secret to sign the payloads")
+	}
+	for i, host := range c.Webhooks.AllowedHosts {
+		if strings.TrimSpace(host) == "" {
+			problems = append(problems, fmt.Sprintf("webhooks.allowedHosts[%d]: empty host", i))
+		}
+	}
+
+	// We check the logging settings
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		problems = append(problems, fmt.Sprintf("logLevel: unknown level %q (the valid ones are debug, info, warn, error)", c.LogLevel))
+	}
+	if !pkg.Contains(logging.Formats, c.LogFormat) {
+		problems = append(problems, fmt.Sprintf("logFormat: unknown format %q (the valid ones are %s)", c.LogFormat, strings.Join(logging.Formats, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "hector.yaml")
+	os.WriteFile(yamlFile, []byte("executor: docker\ndatabasePath: /data/hector.sqlite\nworkers: 8\nnomad:\n  address: http://nomad:4646\n  datacenters: [dc1, dc2]\nwebhooks:\n  urls: [https://hooks.example.com]\n  secret: file-secret\nadminToken: file-admin\n"), 0600)
+	typoFile := filepath.Join(dir, "typo.json")
+	os.WriteFile(typoFile, []byte(`{"executr": "docker"}`), 0600)
+
+	defaults := defaultConfig()
+	fromFile := defaultConfig()
+	fromFile.Executor, fromFile.DatabasePath = dockerExecutor, "/data/hector.sqlite"
+	fromFile.Workers, fromFile.AdminToken = 8, "file-admin"
+	fromFile.Nomad = nomadConfig{Address: "http://nomad:4646", Datacenters: []string{"dc1", "dc2"}}
+	fromFile.Webhooks = webhooksConfig{Urls: []string{"https://hooks.example.com"}, Secret: "file-secret"}
+	fromEnv := fromFile
+	fromEnv.Executor, fromEnv.Listen, fromEnv.Nomad.Region = nomadExecutor, ":1", "eu"
+	fromEnv.Workers, fromEnv.AdminToken = 2, "env-admin"
+	fromEnv.Nomad.Datacenters = []string{"dc3"}
+	fromEnv.Webhooks = webhooksConfig{Urls: []string{"https://a.example.com", "https://b.example.com"}, Secret: "env-secret", AllowedHosts: []string{"a.example.com"}}
+	overridden := fromFile
+	overridden.Executor, overridden.Listen, overridden.Nomad.Region = mockExecutor, "127.0.0.1:9090", "eu"
+	overridden.Workers, overridden.AdminToken = 1, "flag-admin"
+	overridden.Nomad.Datacenters = []string{"dc3"}
+	overridden.Webhooks.Secret = "flag-secret"
+
+	var tests = []struct {
+		args []string
+		env  map[string]string
+		want *config
+		err  string
+	}{
+		{nil, nil, &defaults, ""},
+		{[]string{"-config", yamlFile}, nil, &fromFile, ""},
+		{nil, map[string]string{configEnv: yamlFile, "HECTOR_EXECUTOR": "nomad", "HECTOR_LISTEN": ":1", "HECTOR_NOMAD_REGION": "eu", "HECTOR_NOMAD_DATACENTERS": "dc3", workersEnv: "2", "HECTOR_ADMIN_TOKEN": "env-admin", "HECTOR_WEBHOOK_URLS": "https://a.example.com, https://b.example.com", "HECTOR_WEBHOOK_SECRET": "env-secret", "HECTOR_WEBHOOK_ALLOWED_HOSTS": "a.example.com"}, &fromEnv, ""},
+		{[]string{"-listen", "127.0.0.1:9090", "-executor", "mock", "-nomad-datacenters", "dc3", "-workers", "1", "-admin-token", "flag-admin", "-webhook-secret", "flag-secret"}, map[string]string{configEnv: yamlFile, "HECTOR_EXECUTOR": "nomad", "HECTOR_NOMAD_REGION": "eu", workersEnv: "2", "HECTOR_ADMIN_TOKEN": "env-admin"}, &overridden, ""},
+		{[]string{"-executor", "k8s", "-listen", "8080", "-nomad-address", "nomad:4646", "-datastore", "sqlite3", "-db", ""}, nil, nil, "invalid configuration:\n  - executor: unknown executor \"k8s\" (the valid ones are docker, mock, nomad)\n  - databasePath: the sqlite3 datastore needs the path of the database file\n  - listen: invalid address \"8080\" (expected host:port or :port)\n  - nomad.address: invalid address \"nomad:4646\" (expected an http or https url)"},
+		{[]string{"-log-level", "verbose"}, map[string]string{"HECTOR_LOG_FORMAT": "xml"}, nil, "invalid configuration:\n  - logLevel: unknown level \"verbose\" (the valid ones are debug, info, warn, error)\n  - logFormat: unknown format \"xml\" (the valid ones are json, text)"},
+		{[]string{"-workers", "0", "-webhook-urls", "ftp://hooks.example.com,", "-webhook-allowed-hosts", " "}, nil, nil, "invalid configuration:\n  - workers: invalid number 0 (at least one worker is needed)\n  - webhooks.urls[0]: invalid url \"ftp://hooks.example.com\" (expected an http or https url)\n  - webhooks.urls[1]: invalid url \"\" (expected an http or https url)\n  - webhooks.secret: the webhooks need a secret to sign the payloads\n  - webhooks.allowedHosts[0]: empty host"},
+		{nil, map[string]string{workersEnv: "four"}, nil, `HECTOR_WORKERS: invalid number "four"`},
+		{[]string{"-config", typoFile}, nil, nil, `unknown field "executr"`},
+		{[]string{"-config", filepath.Join(dir, "missing.yaml")}, nil, nil, "no such file or directory"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			cfg, err := loadConfig(tt.args, func(name string) string { return tt.env[name] }, io.Discard)
+
+			if err == nil && tt.err != "" {
+				t.Fatal("got no error, want ", tt.err)
+			} else if err != nil && (tt.err == "" || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatal("got error ", err, ", want ", tt.err)
+			}
+			if tt.want != nil && !reflect.DeepEqual(cfg, tt.want) {
+				t.Error("got ", *cfg, ", want ", *tt.want)
+			}
+		})
+	}
+}
+
+func TestNewScheduler(t *testing.T) {
+	var tests = []struct {
+		scheduler string
+		err       string
+	}{
+		{topologicalGroupedScheduler, ""},
+		{"random", "unknown scheduler random"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Scheduler = tt.scheduler
+			scheduler, err := newScheduler(&cfg)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Error("got ", err, ", want ", tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if _, err := scheduler.Plan(&specifications.Specification{}); err != nil {
+				t.Error(err.Error())
+			}
+		})
+	}
+}
+
+func TestBootstrapAdminToken(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	user, _ := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(user)
+	datastore.AddToken(&tokens.Token{Id: bootstrapTokenId + "-1234abcd", Name: "admin", Role: tokens.Admin, Hash: tokens.HashSecret("legacy")})
+
+	var tests = []struct {
+		secret  string
+		valid   []string
+		revoked []string
+	}{
+		{"first", []string{"first"}, []string{"legacy"}},
+		{"first", []string{"first"}, nil},
+		{"second", []string{"second"}, []string{"first"}},
+		{"", nil, []string{"second"}},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if err := bootstrapAdminToken(&datastore, tt.secret); err != nil {
+				t.Fatal(err.Error())
+			}
+			for _, secret := range tt.valid {
+				if token, err := datastore.GetTokenByHash(tokens.HashSecret(secret)); err != nil || token.Id != bootstrapTokenId || token.Role != tokens.Admin {
+					t.Error("The secret " + secret + " must be a valid admin token")
+				}
+			}
+			for _, secret := range tt.revoked {
+				if _, err := datastore.GetTokenByHash(tokens.HashSecret(secret)); err == nil {
+					t.Error("The secret " + secret + " must have been revoked")
+				}
+			}
+
+			// The rest of tokens are kept
+			if _, err := datastore.GetTokenByHash(user.Hash); err != nil {
+				t.Error("The token of alice must be kept")
+			}
+		})
+	}
+}
@@ -1,56 +1,268 @@
 package main
 
 import (
+	"context"
 	"dag/hector/golang/module/pkg/api"
 	"dag/hector/golang/module/pkg/controllers"
 	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
 	"dag/hector/golang/module/pkg/datastores/sqlite3"
+	"dag/hector/golang/module/pkg/dispatchers"
 	"dag/hector/golang/module/pkg/executors"
+	"dag/hector/golang/module/pkg/executors/execgolang"
+	"dag/hector/golang/module/pkg/executors/execmock"
 	"dag/hector/golang/module/pkg/executors/nomad"
+	"dag/hector/golang/module/pkg/logging"
 	"dag/hector/golang/module/pkg/schedulers"
 	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/tracing"
 	"dag/hector/golang/module/pkg/validators"
-	"log"
+	"flag"
+	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// We declare the time given to the ongoing requests and to the ongoing invocations to end when the
+// server is stopped. Each one has its own deadline, so that slow requests do not consume the time of
+// the invocations. The invocations that do not end in time are resumed on the next start.
+const (
+	requestsShutdownTimeout    = 10 * time.Second
+	invocationsShutdownTimeout = 30 * time.Second
 )
 
+// bootstrapTokenId is the identifier of the bootstrap admin token. There is a single one, so that
+// the secrets that are no longer configured are revoked.
+const bootstrapTokenId = "bootstrap-admin"
+
+// bootstrapAdminToken function ensures that the configured secret (adminToken, if any) is the only
+// valid bootstrap admin token, so that the first tokens can be created through the api. The token of a previous secret is replaced, and it is revoked if the secret is no
+// longer set. It takes as input the pointer of the datastore and the secret. Returns an error variable
+// to report any problems.
+func bootstrapAdminToken(datastore *datastores.Datastore, secret string) error {
+	hash := tokens.HashSecret(secret)
+	if secret != "" {
+		if current, err := (*datastore).GetTokenByHash(hash); err == nil && current.Id == bootstrapTokenId {
+			return nil
+		}
+	}
+
+	// We revoke the previous bootstrap tokens, including those identified by their hash by older versions
+	var revoked []string
+	options := datastores.ListOptions{Limit: datastores.MaxListLimit}
+	for {
+		page, err := (*datastore).ListTokens(&options)
+		if err != nil {
+			return err
+		}
+		for _, token := range page.Items {
+			if token.Id == bootstrapTokenId || strings.HasPrefix(token.Id, bootstrapTokenId+"-") {
+				revoked = append(revoked, token.Id)
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		options.Cursor = page.NextCursor
+	}
+	for _, id := range revoked {
+		if err := (*datastore).DeleteToken(id); err != nil {
+			return err
+		}
+	}
+
+	if secret == "" {
+		return nil
+	}
+	return (*datastore).AddToken(&tokens.Token{Id: bootstrapTokenId, Name: "admin", Role: tokens.Admin, Hash: hash})
+}
+
+// newExecutor function creates the executor selected in the configuration. It takes as input the
+// configuration and the logger of the executor. Returns the executor and an error variable to report
+// any problems.
+func newExecutor(cfg *config, logger logrus.FieldLogger) (executors.Executor, error) {
+	switch cfg.Executor {
+	case dockerExecutor:
+		executor := execgolang.NewExecGolang()
+		executor.Logger = logger
+		return executor, nil
+	case mockExecutor:
+		executor := execmock.NewExecMock()
+		executor.Logger = logger
+		return executor, nil
+	default:
+		executor, err := nomad.NewNomad(cfg.Nomad.Address, cfg.Nomad.Region, cfg.Nomad.Datacenters)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the nomad client: %w", err)
+		}
+		executor.Logger = logger
+		return executor, nil
+	}
+}
+
+// newScheduler function creates the scheduler selected in the configuration. It takes as input the
+// configuration and returns the scheduler and an error variable to report any problems.
+func newScheduler(cfg *config) (schedulers.Scheduler, error) {
+	switch cfg.Scheduler {
+	case topologicalGroupedScheduler:
+		return topologicalgrouped.NewTopologicalGrouped(), nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler %s", cfg.Scheduler)
+	}
+}
+
+// newDatastore function creates the datastore selected in the configuration. It takes as input the
+// configuration and returns the datastore and an error variable to report any problems.
+func newDatastore(cfg *config) (datastores.Datastore, error) {
+	switch cfg.Datastore {
+	case memoryDatastore:
+		return dbmock.NewDBMock(), nil
+	default:
+		datastore, err := sqlite3.NewSQLite3(cfg.DatabasePath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open the database %s: %w", cfg.DatabasePath, err)
+		}
+		return datastore, nil
+	}
+}
+
 func main() {
+	// Read the configuration
+	cfg, err := loadConfig(os.Args[1:], os.Getenv, os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "hector: "+err.Error())
+		os.Exit(2)
+	}
+
+	// Create Logger
+	logger, err := logging.NewLogger(cfg.LogLevel, cfg.LogFormat, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "hector: "+err.Error())
+		os.Exit(2)
+	}
+
 	// Create Executor
-	var executor executors.Executor = nomad.NewNomad()
+	executor, err := newExecutor(cfg, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
 	// Create Scheduler
-	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	scheduler, err := newScheduler(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
 	// Create Datastore
-	var datastore datastores.Datastore
-	var err error
-	datastore, err = sqlite3.NewSQLite3()
+	datastore, err := newDatastore(cfg)
 	if err != nil {
-		panic(err)
+		logger.Fatal(err)
+	}
+
+	// Register the bootstrap admin token
+	if err := bootstrapAdminToken(&datastore, cfg.AdminToken); err != nil {
+		logger.Fatal(err)
 	}
 
 	// Create Validator
 	validator := validators.NewValidator()
 
 	// Create controller
-	controller := &controllers.Controller{Executor: &executor, Scheduler: &scheduler, Datastore: &datastore, Validator: validator}
+	controller := controllers.NewController(&executor, &scheduler, &datastore, validator)
+	controller.Logger = logger
+
+	// Record the spans of the requests and the invocations if tracing is enabled
+	if cfg.TraceFile != "" {
+		exporter, err := tracing.NewJSONFileExporter(cfg.TraceFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer exporter.Close()
+		controller.Tracer = tracing.NewTracer(exporter)
+		controller.Tracer.Logger = logger
+	}
+
+	// Deliver the webhooks of the finished jobs and definitions (if they are enabled)
+	if cfg.Webhooks.Secret != "" {
+		dispatcher, err := dispatchers.NewDispatcher(&datastore, cfg.Webhooks.Secret, cfg.Webhooks.Urls, cfg.Webhooks.AllowedHosts)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		dispatcher.Logger = logger
+		dispatcher.Start()
+		controller.UseDispatcher(dispatcher)
+	} else {
+		logger.Warn("webhooks are disabled, configure their secret (webhooks.secret) to enable them")
+	}
+
+	// Queue again the definitions interrupted by a previous stop of the server
+	recovered, err := controller.Recover()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if recovered > 0 {
+		logger.WithField("definitions", recovered).Info("resuming interrupted definitions")
+	}
+
+	// Start the workers that drain the execution queue
+	controller.StartWorkers(cfg.Workers)
 
 	// Create API
 	api, err := api.NewApi(controller)
 	if err != nil {
-		panic(err)
+		logger.Fatal(err)
 	}
+	api.NomadExport = nomad.ExportOptions{Region: cfg.Nomad.Region, Datacenters: cfg.Nomad.Datacenters, AwaitImage: cfg.Nomad.AwaitImage, AwaitAddress: cfg.Nomad.AwaitAddress}
 
-	// Raise the API
-	log.Fatal(http.ListenAndServe(":8080", api.Router))
+	// Raise the API (the watch streams, which last as long as the invocations, are ended on shutdown)
+	server := &http.Server{Addr: cfg.Listen, Handler: api.Router}
+	server.RegisterOnShutdown(api.CloseStreams)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	logger.WithFields(logrus.Fields{
+		"listen":    cfg.Listen,
+		"executor":  cfg.Executor,
+		"scheduler": cfg.Scheduler,
+		"datastore": cfg.Datastore,
+	}).Info("listening")
 
-	// Set pending definitions to execute
-	pendingDefinitions, err := (*controller.Datastore).GetDefinitionsWithWaitings()
-	if err != nil {
-		panic(err)
+	// Wait for a termination signal
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	select {
+	case err := <-serverErr:
+		logger.Fatal(err)
+	case sig := <-signals:
+		logger.WithField("signal", sig.String()).Info("shutting down")
 	}
-	for _, def := range *pendingDefinitions {
-		controller.Invoke(&def)
+
+	// Stop accepting requests and let the ongoing ones end
+	serverCtx, cancelServer := context.WithTimeout(context.Background(), requestsShutdownTimeout)
+	defer cancelServer()
+	if err := server.Shutdown(serverCtx); err != nil {
+		logger.WithError(err).Error("error while shutting down the api")
 	}
 
+	// Let the ongoing invocations end (or checkpoint them)
+	controllerCtx, cancelController := context.WithTimeout(context.Background(), invocationsShutdownTimeout)
+	defer cancelController()
+	if err := controller.Shutdown(controllerCtx); err != nil {
+		logger.WithError(err).Warn("some definitions are still running, they will be resumed on the next start")
+	}
+
+	// Store the deliveries of the last notifications, so that they are sent on the next start
+	if err := controller.Dispatcher.Flush(controllerCtx); err != nil {
+		logger.WithError(err).Warn("some webhook notifications could not be stored")
+	}
 }
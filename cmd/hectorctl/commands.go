@@ -0,0 +1,288 @@
+package main
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/client"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/results"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// usageErr reports a command invoked with wrong arguments.
+type usageErr struct {
+	message string
+}
+
+// Error function applied on a variable of type usageErr
+// returns the corresponding error message in the form of string.
+func (e *usageErr) Error() string {
+	return e.message + " (run hectorctl -h for help)"
+}
+
+// execute function runs a command of the client. It takes as input the name of the command and its
+// arguments. Returns the exit code of the program and an error variable to report any problems.
+func (c *cli) execute(command string, args []string) (int, error) {
+	var err error
+	switch command {
+	case "submit":
+		err = c.submit(args)
+	case "run":
+		return c.run(args)
+	case "get":
+		err = c.get(args)
+	case "list":
+		err = c.list(args)
+	case "watch":
+		if len(args) != 1 {
+			return 2, &usageErr{"watch expects the identifier of a definition"}
+		}
+		return c.watch(args[0])
+	case "logs":
+		err = c.logs(args)
+	default:
+		err = &usageErr{"unknown command " + command}
+	}
+
+	if _, ok := err.(*usageErr); ok {
+		return 2, err
+	} else if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+// submit function sends components or specifications from files, in the format of their extension.
+func (c *cli) submit(args []string) error {
+	if len(args) < 2 {
+		return &usageErr{"submit expects the kind of the elements (component or spec) and their files"}
+	}
+
+	var send func([]byte, formats.Format) error
+	switch args[0] {
+	case "component", "components":
+		send = c.client.SubmitComponent
+	case "spec", "specs", "specification", "specifications":
+		send = c.client.SubmitSpecification
+	default:
+		return &usageErr{"unknown kind " + args[0] + " (the valid ones are component and spec)"}
+	}
+
+	for _, file := range args[1:] {
+		content, err := pkg.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := send(content, formats.FromFileName(file)); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if c.output == tableOutput {
+			fmt.Fprintln(c.out, "submitted "+file)
+		}
+	}
+	return nil
+}
+
+// run function queues a definition from a file and prints its identifier, following its execution
+// if requested.
+func (c *cli) run(args []string) (int, error) {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	watch := flags.Bool("watch", false, "follow the execution of the definition")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return 2, &usageErr{"run expects the file of a definition"}
+	}
+
+	file := flags.Arg(0)
+	content, err := pkg.ReadFile(file)
+	if err != nil {
+		return 1, err
+	}
+	id, err := c.client.ExecuteDefinition(content, formats.FromFileName(file))
+	if err != nil {
+		return 1, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if c.output == jsonOutput {
+		c.printJSON(map[string]string{"id": id})
+	} else {
+		fmt.Fprintln(c.out, id)
+	}
+	if *watch {
+		return c.watch(id)
+	}
+	return 0, nil
+}
+
+// get function prints an element of the namespace.
+func (c *cli) get(args []string) error {
+	if len(args) != 2 {
+		return &usageErr{"get expects the kind of the element and its identifier"}
+	}
+
+	kind, id := args[0], args[1]
+	switch kind {
+	case "component", "components":
+		component, err := c.client.GetComponent(id)
+		if err != nil {
+			return err
+		}
+		c.print(component, func(t *table) { componentRows(t, *component) })
+	case "spec", "specs", "specification", "specifications":
+		specification, err := c.client.GetSpecification(id)
+		if err != nil {
+			return err
+		}
+		c.print(specification, func(t *table) { specificationRows(t, *specification) })
+	case "definition", "definitions":
+		definition, err := c.client.GetDefinition(id)
+		if err != nil {
+			return err
+		}
+		c.print(definition, func(t *table) { definitionRows(t, *definition) })
+	case "result", "results":
+		resultDefinition, err := c.client.GetResultDefinition(id)
+		if err != nil {
+			return err
+		}
+		c.print(resultDefinition, func(t *table) { resultJobRows(t, resultDefinition.ResultJobs) })
+	default:
+		return &usageErr{"unknown kind " + kind + " (the valid ones are component, spec, definition and result)"}
+	}
+	return nil
+}
+
+// list function prints a page of the elements of the namespace, followed by the cursor of the next
+// page (if any).
+func (c *cli) list(args []string) error {
+	if len(args) == 0 {
+		return &usageErr{"list expects the kind of the elements"}
+	}
+
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	options := client.ListOptions{}
+	flags.IntVar(&options.Limit, "limit", 0, "maximum number of elements")
+	flags.StringVar(&options.Cursor, "cursor", "", "cursor of the page")
+	flags.StringVar(&options.Sort, "sort", "", "field used to sort the elements")
+	flags.BoolVar(&options.Descending, "desc", false, "sort in descending order")
+	flags.StringVar(&options.Name, "name", "", "prefix of the names")
+	flags.StringVar(&options.SpecificationId, "spec", "", "identifier of the specification")
+	flags.StringVar(&options.Status, "status", "", "status of the results")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 {
+		return &usageErr{"invalid options for list"}
+	}
+
+	var nextCursor string
+	switch kind := args[0]; kind {
+	case "component", "components":
+		page, err := c.client.ListComponents(&options)
+		if err != nil {
+			return err
+		}
+		nextCursor = page.NextCursor
+		c.print(page, func(t *table) { componentRows(t, page.Items...) })
+	case "spec", "specs", "specification", "specifications":
+		page, err := c.client.ListSpecifications(&options)
+		if err != nil {
+			return err
+		}
+		nextCursor = page.NextCursor
+		c.print(page, func(t *table) { specificationRows(t, page.Items...) })
+	case "definition", "definitions":
+		page, err := c.client.ListDefinitions(&options)
+		if err != nil {
+			return err
+		}
+		nextCursor = page.NextCursor
+		c.print(page, func(t *table) { definitionRows(t, page.Items...) })
+	case "result", "results":
+		page, err := c.client.ListResultDefinitions(&options)
+		if err != nil {
+			return err
+		}
+		nextCursor = page.NextCursor
+		c.print(page, func(t *table) { resultDefinitionRows(t, page.Items...) })
+	default:
+		return &usageErr{"unknown kind " + kind + " (the valid ones are components, specs, definitions and results)"}
+	}
+
+	if c.output == tableOutput && nextCursor != "" {
+		fmt.Fprintln(c.out, "\nnext page: -cursor "+nextCursor)
+	}
+	return nil
+}
+
+// watch function prints the job statuses of a definition as they change until its execution ends.
+// The exit code is 1 if the definition has not been completed successfully.
+func (c *cli) watch(id string) (int, error) {
+	if c.output == tableOutput {
+		fmt.Fprintf(c.out, watchRowFormat, "JOB ID", "NAME", "STATUS")
+	}
+	resultDefinition, err := c.client.WatchResultDefinition(id, func(event events.Event) {
+		if c.output == jsonOutput {
+			c.printJSONLine(event)
+		} else {
+			fmt.Fprintf(c.out, watchRowFormat, event.ResultJob.Id, event.ResultJob.Name, event.ResultJob.Status)
+		}
+	})
+	if err != nil {
+		return 1, err
+	}
+
+	status := resultDefinition.Status()
+	if c.output == jsonOutput {
+		c.printJSONLine(resultDefinition)
+	} else {
+		fmt.Fprintf(c.out, "definition %s finished with status %s\n", id, status)
+	}
+	if status != results.Done {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// logs function prints the logs of the jobs of a definition, or those of one of them if its name is
+// given.
+func (c *cli) logs(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return &usageErr{"logs expects the identifier of a definition and, optionally, the name of a job"}
+	}
+
+	resultDefinition, err := c.client.GetResultDefinition(args[0])
+	if err != nil {
+		return err
+	}
+	resultJobs := resultDefinition.ResultJobs
+	if len(args) == 2 {
+		resultJobs = nil
+		for _, resultJob := range resultDefinition.ResultJobs {
+			if resultJob.Name == args[1] {
+				resultJobs = append(resultJobs, resultJob)
+			}
+		}
+		if len(resultJobs) == 0 {
+			return fmt.Errorf("the definition %s has no job named %s", args[0], args[1])
+		}
+	}
+
+	if c.output == jsonOutput {
+		c.printJSON(resultJobs)
+		return nil
+	}
+	for i, resultJob := range resultJobs {
+		if len(resultJobs) > 1 {
+			if i > 0 {
+				fmt.Fprintln(c.out)
+			}
+			fmt.Fprintf(c.out, "==> %s (%s) <==\n", resultJob.Name, resultJob.Status)
+		}
+		fmt.Fprint(c.out, resultJob.Logs)
+		if resultJob.Logs != "" && resultJob.Logs[len(resultJob.Logs)-1] != '\n' {
+			fmt.Fprintln(c.out)
+		}
+	}
+	return nil
+}
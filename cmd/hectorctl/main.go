@@ -0,0 +1,85 @@
+package main
+
+import (
+	"dag/hector/golang/module/pkg/client"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// usage is the help message of the command-line client.
+const usage = `hectorctl is the command-line client of the Hector api.
+
+Usage:
+  hectorctl [-config file] [-n namespace] [-o table|json] <command> [arguments]
+
+Commands:
+  submit component <file>...             Submit components (json or yaml files)
+  submit spec <file>...                  Submit specifications
+  run [-watch] <file>                    Queue a definition and print its identifier
+  get component|spec|definition|result <id>
+  list components|specs|definitions|results [-limit n] [-cursor c] [-sort field] [-desc] [-name prefix] [-spec id] [-status status]
+  watch <definition_id>                  Follow the job statuses of a run until it ends
+  logs <definition_id> [job_name]        Print the logs of the jobs of a run
+
+The url of the server, the token and the namespace are read from the configuration file
+(` + "`url`, `token` and `namespace`" + `), which defaults to $HECTORCTL_CONFIG or ~/.hector/config.yaml,
+and can be overridden with the HECTOR_URL, HECTOR_TOKEN and HECTOR_NAMESPACE environment variables.
+`
+
+// cli contains the state shared by the commands: the client of the api, the output format and the
+// writer where the output is printed.
+type cli struct {
+	client *client.Client
+	output string
+	out    io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run function parses the global flags, loads the configuration and executes the command. It takes
+// as input the arguments of the program and the writers of the output and the errors. Returns the
+// exit code of the program.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("hectorctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	configPath := flags.String("config", "", "path of the configuration file")
+	namespace := flags.String("n", "", "namespace of the elements (overrides the configuration)")
+	output := flags.String("o", tableOutput, "output format (table or json)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	if *output != tableOutput && *output != jsonOutput {
+		fmt.Fprintf(stderr, "unknown output format %s (the valid ones are %s and %s)\n", *output, tableOutput, jsonOutput)
+		return 2
+	}
+
+	// We load the configuration
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path = client.DefaultConfigPath()
+	}
+	config, err := client.LoadConfig(path, explicit)
+	if err != nil {
+		fmt.Fprintln(stderr, "error: "+err.Error())
+		return 1
+	}
+	if *namespace != "" {
+		config.Namespace = *namespace
+	}
+
+	c := cli{client: config.NewClient(), output: *output, out: stdout}
+	code, err := c.execute(flags.Arg(0), flags.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(stderr, "error: "+err.Error())
+	}
+	return code
+}
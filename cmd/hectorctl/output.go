@@ -0,0 +1,114 @@
+package main
+
+import (
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/specifications"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// We declare the output formats of the client.
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+)
+
+// watchRowFormat is the format of the rows printed while watching a definition, which are written
+// as the events arrive and therefore cannot be aligned afterwards.
+const watchRowFormat = "%-22s %-32s %s\n"
+
+// table collects the rows of a table, whose columns are aligned when it is printed.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// row function adds a row to the table.
+func (t *table) row(values ...any) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = fmt.Sprint(value)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// print function writes a value in the output format: the JSON encoding of the value or a table
+// built by the given function.
+func (c *cli) print(v any, build func(*table)) {
+	if c.output == jsonOutput {
+		c.printJSON(v)
+		return
+	}
+
+	t := table{}
+	build(&t)
+	w := tabwriter.NewWriter(c.out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// printJSON function writes the indented JSON encoding of a value.
+func (c *cli) printJSON(v any) {
+	content, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(c.out, string(content))
+}
+
+// printJSONLine function writes the JSON encoding of a value in a single line, so that streams can
+// be processed line by line.
+func (c *cli) printJSONLine(v any) {
+	content, _ := json.Marshal(v)
+	fmt.Fprintln(c.out, string(content))
+}
+
+// componentRows function fills a table with components.
+func componentRows(t *table, items ...components.Component) {
+	t.header = []string{"ID", "NAME", "IMAGE", "INPUTS", "OUTPUTS"}
+	for _, component := range items {
+		t.row(component.Id, component.Name, component.ContainerImage, len(component.Inputs), len(component.Outputs))
+	}
+}
+
+// specificationRows function fills a table with specifications.
+func specificationRows(t *table, items ...specifications.Specification) {
+	t.header = []string{"ID", "NAME", "TASKS"}
+	for _, specification := range items {
+		t.row(specification.Id, specification.Name, len(specification.Spec.Dag.Tasks))
+	}
+}
+
+// definitionRows function fills a table with definitions.
+func definitionRows(t *table, items ...definitions.Definition) {
+	t.header = []string{"ID", "NAME", "SPECIFICATION", "SUBMITTED BY"}
+	for _, definition := range items {
+		t.row(definition.Id, definition.Name, definition.SpecificationId, definition.SubmittedBy)
+	}
+}
+
+// resultDefinitionRows function fills a table with results, counting their completed jobs.
+func resultDefinitionRows(t *table, items ...results.ResultDefinition) {
+	t.header = []string{"ID", "NAME", "SPECIFICATION", "STATUS", "JOBS"}
+	for _, resultDefinition := range items {
+		done := 0
+		for _, resultJob := range resultDefinition.ResultJobs {
+			if resultJob.Status == results.Done {
+				done++
+			}
+		}
+		t.row(resultDefinition.Id, resultDefinition.Name, resultDefinition.SpecificationId, resultDefinition.Status(), fmt.Sprintf("%d/%d", done, len(resultDefinition.ResultJobs)))
+	}
+}
+
+// resultJobRows function fills a table with the jobs of a result.
+func resultJobRows(t *table, resultJobs []results.ResultJob) {
+	t.header = []string{"JOB ID", "NAME", "STATUS"}
+	for _, resultJob := range resultJobs {
+		t.row(resultJob.Id, resultJob.Name, resultJob.Status)
+	}
+}
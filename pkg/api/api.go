@@ -1,30 +1,51 @@
 package api
 
 import (
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/components"
 	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
 	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/executors/nomad"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/namespaces"
 	"dag/hector/golang/module/pkg/results"
 	"dag/hector/golang/module/pkg/specifications"
-	"encoding/json"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/tracing"
+	"dag/hector/golang/module/pkg/validators"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/xid"
+	"github.com/sirupsen/logrus"
 )
 
 // Api is a structured type containing a field with a router, datastore, validator and task executor.
 type Api struct {
 	Router     http.Handler
 	Controller *controllers.Controller
+	OpenAPI    *OpenAPIDocument
+	Logger     logrus.FieldLogger
+	Tracer     *tracing.Tracer
+
+	// NomadExport contains the settings of the cluster where the exported nomad jobs are run.
+	NomadExport nomad.ExportOptions
+
+	// closing is closed when the server shuts down, which ends the watch streams.
+	closing   chan struct{}
+	closeOnce sync.Once
 }
 
 // Element is an interface that encompasses all the types collected in the datastore.
 type Element interface {
-	components.Component | specifications.Specification | [][]string | definitions.Definition | results.ResultDefinition
+	components.Component | specifications.Specification | [][]string | definitions.Definition | results.ResultDefinition | tokens.Token | bundles.Bundle
 }
 
 // getElement function implements a generic procedure that is in charge of answering
@@ -33,72 +54,236 @@ type Element interface {
 // Finally, it records the result in the body of the response. It takes as input the
 // request, the get function that communicates with the datastore and the variable type
 // ResponseWriter where the output is registered.
-func getElement[V Element](f func(string) (*V, error), w http.ResponseWriter, r *http.Request) {
+func getElement[V Element](f func(string, string) (*V, error), w http.ResponseWriter, r *http.Request) {
 
-	// We collect the ID of the url
+	// We collect the namespace and the ID of the url
 	vars := mux.Vars(r)
-	id := vars["ID"]
+	namespace, id := vars["NS"], vars["ID"]
 
 	// We launch a query to the datastore
-	datastoreElement, err := f(id)
+	datastoreElement, err := f(namespace, id)
 	if err != nil {
-		log.Printf("Invalid id: %s", err.Error())
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
 	// We write the output in the response writer
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(*datastoreElement)
+	writeResponse(w, r, http.StatusOK, *datastoreElement)
+}
+
+// listElements function implements a generic procedure that is in charge of answering requests
+// that ask for the elements of a certain type stored in a namespace of the datastore. To do so, it
+// reads the listing options from the query parameters and requires the function in charge of
+// performing the listing in the datastore. Finally, it records the resulting page in the body of the
+// response. It takes as input the listing function, the variable type ResponseWriter where the output
+// is registered and the request.
+func listElements[V Element](f func(string, *datastores.ListOptions) (*datastores.Page[V], error), w http.ResponseWriter, r *http.Request) {
+
+	// We collect the listing options from the url
+	options, err := readListOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// We launch a query to the datastore
+	page, err := f(mux.Vars(r)["NS"], options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// We write the output in the response writer
+	writeResponse(w, r, http.StatusOK, *page)
+}
+
+// readListOptions function extracts the listing options from the query parameters of the
+// request (limit, cursor, sort, order, name, specificationId and status). It takes as input
+// the request and returns the pointer to the options and an error variable notifying of any
+// problem.
+func readListOptions(r *http.Request) (*datastores.ListOptions, error) {
+	query := r.URL.Query()
+
+	options := datastores.ListOptions{
+		Cursor:          query.Get("cursor"),
+		SortBy:          query.Get("sort"),
+		NamePrefix:      query.Get("name"),
+		SpecificationId: query.Get("specificationId"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, &errors.InvalidRequestErr{Field: "limit", Message: "invalid limit " + limit}
+		}
+		options.Limit = value
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		options.Descending = true
+	default:
+		return nil, &errors.InvalidRequestErr{Field: "order", Message: "invalid order " + order}
+	}
+
+	if status := query.Get("status"); status != "" {
+		value, err := results.ParseStatus(status)
+		if err != nil {
+			return nil, &errors.InvalidRequestErr{Field: "status", Message: err.Error()}
+		}
+		options.Status = &value
+	}
+
+	return &options, nil
+}
+
+// deleteElement function implements a generic procedure that is in charge of answering requests
+// that ask for the removal of a certain element from the datastore. To do so, it requires the
+// function in charge of performing the removal in the datastore. It takes as input the delete
+// function, the variable type ResponseWriter where the result is notified and the request.
+func deleteElement(f func(string, string) error, w http.ResponseWriter, r *http.Request) {
+
+	// We collect the namespace and the ID of the url
+	vars := mux.Vars(r)
+	namespace, id := vars["NS"], vars["ID"]
+
+	// We launch the removal to the datastore
+	err := f(namespace, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 }
 
 // readAndValidateElement function implements a generic procedure that reads the content of
 // an element in the request body and validates its structure. To do so, it requires the
-// function in charge of performing such validation. It takes as input the request and a
-// validation function. It provides in the output the element read and an error type variable
-// notifying of any problem.
+// function in charge of performing such validation. The body is read as YAML if the request
+// declares a YAML content type and as JSON otherwise; the errors found in YAML bodies report
+// the line of the invalid field. It takes as input the request and a validation function. It
+// provides in the output the element read and an error type variable notifying of any problem.
 func readAndValidateElement[V Element](f func(*V) error, r *http.Request) (V, error) {
 
 	// Read element from body
 	var element V
 	content, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return element, fmt.Errorf("invalid request: %s", err.Error())
+		return element, &errors.InvalidRequestErr{Message: "invalid request: " + err.Error()}
 	}
-	if err := json.Unmarshal(content, &element); err != nil {
-		return element, fmt.Errorf("invalid request: %s", err.Error())
+	format := formats.FromMediaType(r.Header.Get("Content-Type"))
+	if err := decodeBody(content, format, &element); err != nil {
+		return element, err
 	}
 
 	// Validate element scheme
 	schemeErr := f(&element)
 	if schemeErr != nil {
-		return element, fmt.Errorf("invalid scheme: %s", schemeErr.Error())
+		locateViolations(schemeErr, content, format)
+		return element, fmt.Errorf("invalid scheme: %w", schemeErr)
 	}
 	return element, nil
 }
 
+// locateViolations function records the line of the YAML document where each violation of a
+// validation is found. It takes as input the error of the validation, the content of the document
+// and its format (other formats are ignored).
+func locateViolations(err error, content []byte, format formats.Format) {
+	violations, ok := err.(validators.Violations)
+	if !ok || format != formats.YAML {
+		return
+	}
+	for i, violation := range violations {
+		violations[i].Line = formats.Line(content, violation.Field())
+	}
+}
+
+// setNamespace function assigns the namespace of the url to an element read from the request body.
+// The body may omit the namespace, but if it is given it must match the one of the url. It takes as
+// input the request and the pointer to the namespace field of the element. Returns an error variable
+// to report any problems.
+func setNamespace(r *http.Request, namespace *string) error {
+	urlNamespace := mux.Vars(r)["NS"]
+	if *namespace != "" && *namespace != urlNamespace {
+		return &errors.InvalidRequestErr{Field: "namespace", Message: fmt.Sprintf("the namespace %s does not match the url namespace %s", *namespace, urlNamespace)}
+	}
+	*namespace = urlNamespace
+	return nil
+}
+
+// checkNamespace function is the middleware that rejects the requests whose url contains an invalid
+// namespace name. It takes as input the next handler and returns the resulting handler.
+func checkNamespace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if namespace, namespaced := mux.Vars(r)["NS"]; namespaced {
+			if err := namespaces.Check(namespace); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// legacyNamespace function returns the middleware of the deprecated routes without namespace, which
+// act on the default namespace: it sets the namespace of the url (so that the authorization and the
+// handlers see it) and announces the deprecation in the Deprecation header of the response. It takes
+// as input whether each route (by name) is deprecated.
+func legacyNamespace(deprecated map[string]bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && deprecated[route.GetName()] {
+				vars := map[string]string{"NS": namespaces.Default}
+				for name, value := range mux.Vars(r) {
+					vars[name] = value
+				}
+				r = mux.SetURLVars(r, vars)
+				w.Header().Set("Deprecation", "true")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // NewApi function creates a new instance of type Api. It takes as input a controller.
 // It returns the pointer to the new instance of the api and an error variable to
 // report any problems.
 func NewApi(controller *controllers.Controller) (*Api, error) {
-	a := Api{}
+	a := Api{closing: make(chan struct{})}
 
+	// Register the routes together with the role required by each one of them
 	r := mux.NewRouter()
-	r.HandleFunc("/component/submit", a.submitComponent).Methods(http.MethodPost)
-	r.HandleFunc("/component/get/{ID}", a.getComponent).Methods(http.MethodGet)
-	r.HandleFunc("/specification/submit", a.submitSpecification).Methods(http.MethodPost)
-	r.HandleFunc("/specification/get/{ID}", a.getSpecification).Methods(http.MethodGet)
-	r.HandleFunc("/topologicalSort/get/{ID}", a.getTopologicalSort).Methods(http.MethodGet)
-	r.HandleFunc("/definition/execute", a.executeDefinition).Methods(http.MethodPost)
-	r.HandleFunc("/definition/get/{ID}", a.getDefinition).Methods(http.MethodGet)
-	r.HandleFunc("/result/get/{ID}", a.getResultDefinition).Methods(http.MethodGet)
+	roles := make(map[string]tokens.Role)
+	deprecated := make(map[string]bool)
+	for _, rt := range a.routes() {
+		name := operationId(rt)
+		r.HandleFunc(rt.Path, rt.Handler).Methods(rt.Method).Name(name)
+		roles[name] = rt.Role
+		deprecated[name] = rt.Deprecated
+	}
+	r.Use(legacyNamespace(deprecated), a.logRequests, a.traceRequests, a.authorization(roles), checkNamespace)
 	a.Router = r
 
+	// The requests are logged and traced with the logger and the tracer of the controller
 	a.Controller = controller
+	a.Logger = logging.Default()
+	if controller != nil {
+		a.Logger = controller.Logger
+		a.Tracer = controller.Tracer
+	}
+
+	// Generate the OpenAPI document from the registered routes
+	a.OpenAPI = newOpenAPIDocument(a.routes())
 
 	return &a, nil
 }
 
+// CloseStreams function ends the watch streams, which would otherwise last until the end of the
+// invocations that they follow. It must be called when the server shuts down (e.g. registered with
+// RegisterOnShutdown), so that the shutdown does not wait for them.
+func (a *Api) CloseStreams() {
+	a.closeOnce.Do(func() { close(a.closing) })
+}
+
 // submitComponent function is responsible for extracting the component element from
 // the request body and inserting it into the datastore. It takes as input the request
 // and the variable type ResponseWriter where the result of the operation is notified.
@@ -107,16 +292,18 @@ func (a *Api) submitComponent(w http.ResponseWriter, r *http.Request) {
 	// Read component from body and validate scheme
 	component, err := readAndValidateElement(a.Controller.Validator.ValidateComponentStruct, r)
 	if err != nil {
-		log.Print(err.Error())
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
+		return
+	}
+	if err := setNamespace(r, &component.Namespace); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	// Add component to datastore
 	datastoreErr := (*a.Controller.Datastore).AddComponent(&component)
 	if datastoreErr != nil {
-		log.Printf("error during insertion into the datastore %s", datastoreErr.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, fmt.Errorf("error during insertion into the datastore %w", datastoreErr))
 		return
 	}
 }
@@ -129,8 +316,17 @@ func (a *Api) submitSpecification(w http.ResponseWriter, r *http.Request) {
 	// Read specification from body and validate scheme
 	specification, err := readAndValidateElement(a.Controller.Validator.ValidateSpecificationStruct, r)
 	if err != nil {
-		log.Print(err.Error())
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
+		return
+	}
+
+	// The components can only be taken from the namespace of the specification or the shared one
+	if err := setNamespace(r, &specification.Namespace); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.Controller.Validator.ValidateComponentReferences(&specification); err != nil {
+		writeError(w, fmt.Errorf("invalid scheme: %w", err))
 		return
 	}
 
@@ -139,62 +335,284 @@ func (a *Api) submitSpecification(w http.ResponseWriter, r *http.Request) {
 	// Calculate topological sort
 	planning, err := (*a.Controller.Scheduler).Plan(&specification)
 	if err != nil {
-		log.Printf("error during planning calculation %s", err.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, fmt.Errorf("error during planning calculation %w", err))
 		return
 	}
 
 	// Add topological sort to datastore
-	datastorePlanningErr := (*a.Controller.Datastore).AddPlanning(&planning, specification.Id)
+	datastorePlanningErr := (*a.Controller.Datastore).AddPlanning(&planning, specification.Namespace, specification.Id)
 	if datastorePlanningErr != nil {
-		log.Printf("error during insertion into the datastore %s", datastorePlanningErr.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, fmt.Errorf("error during insertion into the datastore %w", datastorePlanningErr))
 		return
 	}
 
 	// Add specification to datastore
 	datastoreSpecErr := (*a.Controller.Datastore).AddSpecification(&specification)
 	if datastoreSpecErr != nil {
-		log.Printf("error during insertion into the datastore %s", datastoreSpecErr.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, fmt.Errorf("error during insertion into the datastore %w", datastoreSpecErr))
+		return
+	}
+}
+
+// updateComponent function is responsible for extracting the component element from the request
+// body and replacing the stored one with the same identifier. It takes as input the request and
+// the variable type ResponseWriter where the result of the operation is notified.
+func (a *Api) updateComponent(w http.ResponseWriter, r *http.Request) {
+
+	// Read component from body and validate scheme
+	component, err := readAndValidateElement(a.Controller.Validator.ValidateComponentStruct, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The identifier and the namespace of the body must match the ones of the url
+	if id := mux.Vars(r)["ID"]; component.Id != id {
+		writeError(w, &errors.InvalidRequestErr{Field: "id", Message: fmt.Sprintf("the component id %s does not match the url id %s", component.Id, id)})
+		return
+	}
+	if err := setNamespace(r, &component.Namespace); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Replace component in datastore
+	datastoreErr := (*a.Controller.Datastore).UpdateComponent(&component)
+	if datastoreErr != nil {
+		writeError(w, fmt.Errorf("error during update of the datastore %w", datastoreErr))
 		return
 	}
 }
 
+// updateSpecification function is responsible for extracting the specification element from the
+// request body, recalculating its planning and replacing both stored elements. It takes as input
+// the request and the variable type ResponseWriter where the result of the operation is notified.
+func (a *Api) updateSpecification(w http.ResponseWriter, r *http.Request) {
+
+	// Read specification from body and validate scheme
+	specification, err := readAndValidateElement(a.Controller.Validator.ValidateSpecificationStruct, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The identifier and the namespace of the body must match the ones of the url
+	if id := mux.Vars(r)["ID"]; specification.Id != id {
+		writeError(w, &errors.InvalidRequestErr{Field: "id", Message: fmt.Sprintf("the specification id %s does not match the url id %s", specification.Id, id)})
+		return
+	}
+	if err := setNamespace(r, &specification.Namespace); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.Controller.Validator.ValidateComponentReferences(&specification); err != nil {
+		writeError(w, fmt.Errorf("invalid scheme: %w", err))
+		return
+	}
+
+	// Check that the specification exists before recalculating its planning
+	if _, err := (*a.Controller.Datastore).GetSpecification(specification.Namespace, specification.Id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Calculate topological sort
+	planning, err := (*a.Controller.Scheduler).Plan(&specification)
+	if err != nil {
+		writeError(w, fmt.Errorf("error during planning calculation %w", err))
+		return
+	}
+
+	// Replace specification and topological sort in datastore at once
+	datastoreErr := (*a.Controller.Datastore).UpdateSpecificationWithPlanning(&specification, &planning)
+	if datastoreErr != nil {
+		writeError(w, fmt.Errorf("error during update of the datastore %w", datastoreErr))
+		return
+	}
+}
+
+// deleteComponent function is responsible for removing a particular Component element from the
+// datastore. The removal is refused while any stored specification references the component. It
+// takes as input the request and the ResponseWriter variable.
+func (a *Api) deleteComponent(w http.ResponseWriter, r *http.Request) {
+	deleteElement((*a.Controller.Datastore).DeleteComponent, w, r)
+}
+
+// deleteSpecification function is responsible for removing a particular Specification element and
+// its planning from the datastore. It takes as input the request and the ResponseWriter variable.
+func (a *Api) deleteSpecification(w http.ResponseWriter, r *http.Request) {
+	deleteElement((*a.Controller.Datastore).DeleteSpecification, w, r)
+}
+
+// executionResponse is the body returned to the client once a definition has been queued.
+type executionResponse struct {
+	Id string `json:"id"`
+}
+
 // executeDefinition function extracts the Definition element from the request body and sends
-// it to the controller for execution. Finally, it records the status of the operation in the
-// variable type ResponseWriter. It takes as input the request and the variable type ResponseWriter.
+// it to the controller queue for its later execution. Finally, it records the status of the
+// operation and the identifier of the new definition in the variable type ResponseWriter. It
+// takes as input the request and the variable type ResponseWriter.
 func (a *Api) executeDefinition(w http.ResponseWriter, r *http.Request) {
 
 	// Read definition from body and validate scheme
 	definition, err := readAndValidateElement(a.Controller.Validator.ValidateDefinitionStruct, r)
 	if err != nil {
-		log.Print(err.Error())
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, err)
+		return
+	}
+	if err := setNamespace(r, &definition.Namespace); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	// Generate random id
+	// Generate random id and record the submitting identity
 	definition.Id = xid.New().String()
+	definition.SubmittedBy = requestToken(r).Name
+	definition.SubmitterId = requestToken(r).Id
+	definition.RequestId = logging.RequestId(r.Context())
+	definition.Traceparent = tracing.Traceparent(r.Context())
+
+	// Add definition to the execution queue
+	_, subErr := a.Controller.Submit(&definition)
+	if subErr != nil {
+		writeError(w, fmt.Errorf("error during submission of the definition %w", subErr))
+		return
+	}
 
-	// Add definition to datastore
-	addDefErr := (*a.Controller.Datastore).AddDefinition(&definition)
-	if addDefErr != nil {
-		log.Printf("error while trying to insert the definition in the datastore %s", addDefErr.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+	// If everything has gone well, we notify that the definition has been accepted and return its id
+	writeResponse(w, r, http.StatusAccepted, executionResponse{Id: definition.Id})
+}
+
+// cancelRequest is the optional body of the cancellation requests.
+type cancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// defaultCancelReason is recorded in the cancelled jobs when the request does not provide a reason.
+const defaultCancelReason = "cancelled by user request"
+
+// cancelDefinition function stops the execution of the definition whose identifier is collected
+// from the url and marks its pending jobs as cancelled with the reason provided in the body (if
+// any). Finally, it records the resulting ResultDefinition in the variable type ResponseWriter. It
+// takes as input the request and the variable type ResponseWriter.
+func (a *Api) cancelDefinition(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the namespace and the ID of the url
+	vars := mux.Vars(r)
+	namespace, id := vars["NS"], vars["ID"]
+
+	// We read the reason from the body (which may be empty)
+	request := cancelRequest{}
+	if err := readOptionalBody(r, &request); err != nil {
+		writeError(w, err)
 		return
 	}
+	if request.Reason == "" {
+		request.Reason = defaultCancelReason
+	}
 
-	_, invErr := a.Controller.Invoke(&definition)
-	if invErr != nil {
-		log.Printf("error during invocation of the definition %s", invErr.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+	// We check that the identity can act on the definition
+	if err := a.checkOwnership(r, namespace, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// We cancel the definition
+	resultDefinition, err := a.Controller.Cancel(namespace, id, request.Reason)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	// If everything has gone well, we set the status to satisfactory and return the execution id
-	fmt.Println(definition.Id)
-	w.WriteHeader(http.StatusOK)
+	// We write the output in the response writer
+	writeResponse(w, r, http.StatusOK, *resultDefinition)
+}
+
+// retryRequest is the optional body of the retry requests. It contains the failed tasks whose
+// parameters are overridden.
+type retryRequest struct {
+	Tasks []definitions.DefinitionTask `json:"tasks"`
+}
+
+// retryDefinition function resets the failed and cancelled jobs of the definition whose identifier
+// is collected from the url and sends it back to the controller queue, optionally overriding the
+// parameters of the failed tasks with those provided in the body. Finally, it records the status
+// of the operation and the resulting ResultDefinition in the variable type ResponseWriter. It takes
+// as input the request and the variable type ResponseWriter.
+func (a *Api) retryDefinition(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the namespace and the ID of the url
+	vars := mux.Vars(r)
+	namespace, id := vars["NS"], vars["ID"]
+
+	// We read the overrides from the body (which may be empty)
+	request := retryRequest{}
+	if err := readOptionalBody(r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.Controller.Validator.ValidateDefinitionTasksStruct(&request.Tasks); err != nil {
+		writeError(w, validators.Prefix(err, "/tasks"))
+		return
+	}
+
+	// We check that the identity can act on the definition
+	if err := a.checkOwnership(r, namespace, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// We queue the definition again
+	resultDefinition, err := a.Controller.Retry(r.Context(), namespace, id, request.Tasks)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// If everything has gone well, we notify that the definition has been accepted again
+	writeResponse(w, r, http.StatusAccepted, *resultDefinition)
+}
+
+// readOptionalBody function decodes the JSON or YAML body of a request into the given variable,
+// leaving it untouched if the body is empty. It takes as input the request and the pointer to the
+// variable. Returns an error variable to report any problems.
+func readOptionalBody(r *http.Request, v any) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return &errors.InvalidRequestErr{Message: "invalid request: " + err.Error()}
+	}
+	if len(body) > 0 {
+		return decodeBody(body, formats.FromMediaType(r.Header.Get("Content-Type")), v)
+	}
+	return nil
+}
+
+// decodeBody function decodes the content of a request body in the given format into a variable.
+// It takes as input the content, the format and the pointer to the variable. Returns an
+// InvalidRequestErr, with the field and the line of the problem when they are known, if the
+// content cannot be decoded.
+func decodeBody(content []byte, format formats.Format, v any) error {
+	if err := formats.Unmarshal(content, format, v); err != nil {
+		syntaxErr := err.(*formats.SyntaxErr)
+		return &errors.InvalidRequestErr{Field: syntaxErr.Field, Line: syntaxErr.Line, Message: "invalid request: " + syntaxErr.Message}
+	}
+	return nil
+}
+
+// writeResponse function records a value in the body of the response, encoded in the format
+// accepted by the client (YAML if it is preferred in the Accept header and JSON otherwise). It
+// takes as input the variable type ResponseWriter, the request, the status code and the value.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	format := formats.Negotiate(r.Header.Get("Accept"))
+	content, err := formats.Marshal(v, format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", string(format))
+	w.WriteHeader(status)
+	w.Write(content)
 }
 
 // getComponent function is responsible for resolving requests for information about a particular
@@ -236,3 +654,32 @@ func (a *Api) getDefinition(w http.ResponseWriter, r *http.Request) {
 func (a *Api) getResultDefinition(w http.ResponseWriter, r *http.Request) {
 	getElement((*a.Controller.Datastore).GetResultDefinition, w, r)
 }
+
+// listComponents function is responsible for resolving requests for the list of Component elements.
+// To do so, it extracts the listing options from the query of the request and records the resulting
+// page in the ResponseWriter type variable. It takes as input the request and the ResponseWriter variable.
+func (a *Api) listComponents(w http.ResponseWriter, r *http.Request) {
+	listElements((*a.Controller.Datastore).ListComponents, w, r)
+}
+
+// listSpecifications function is responsible for resolving requests for the list of Specification elements.
+// To do so, it extracts the listing options from the query of the request and records the resulting
+// page in the ResponseWriter type variable. It takes as input the request and the ResponseWriter variable.
+func (a *Api) listSpecifications(w http.ResponseWriter, r *http.Request) {
+	listElements((*a.Controller.Datastore).ListSpecifications, w, r)
+}
+
+// listDefinitions function is responsible for resolving requests for the list of Definition elements.
+// To do so, it extracts the listing options from the query of the request and records the resulting
+// page in the ResponseWriter type variable. It takes as input the request and the ResponseWriter variable.
+func (a *Api) listDefinitions(w http.ResponseWriter, r *http.Request) {
+	listElements((*a.Controller.Datastore).ListDefinitions, w, r)
+}
+
+// listResultDefinitions function is responsible for resolving requests for the list of ResultDefinition
+// elements. To do so, it extracts the listing options from the query of the request and records the
+// resulting page in the ResponseWriter type variable. It takes as input the request and the ResponseWriter
+// variable.
+func (a *Api) listResultDefinitions(w http.ResponseWriter, r *http.Request) {
+	listElements((*a.Controller.Datastore).ListResultDefinitions, w, r)
+}
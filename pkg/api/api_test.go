@@ -0,0 +1,213 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/schedulers"
+	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSubmitSpecificationNamespaces(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	// The body of each specification is completed with its id, namespace and component reference
+	body := `{"id": "%ID", %NS"name": "Spec", "apiVersion": "hector/v1", "spec": {"dag": {"tasks": [{"name": "A", "component": "%COMP"}]}}}`
+
+	var tests = []struct {
+		id        string
+		namespace string
+		component string
+		status    int
+	}{
+		{"Spec-1", "", "Comp1-ID", http.StatusOK},
+		{"Spec-2", `"namespace": "team-a", `, "shared/Comp1-ID", http.StatusOK},
+		{"Spec-3", `"namespace": "team-b", `, "Comp1-ID", http.StatusBadRequest},
+		{"Spec-4", "", "team-b/Comp1-ID", http.StatusUnprocessableEntity},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			content := strings.NewReplacer("%ID", tt.id, "%NS", tt.namespace, "%COMP", tt.component).Replace(body)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/namespaces/team-a/specification/submit", strings.NewReader(content))
+			request.Header.Set("Authorization", "Bearer "+secret)
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			} else if _, err := datastore.GetSpecification("team-a", tt.id); (err == nil) != (tt.status == http.StatusOK) {
+				t.Error("The specification must only be stored in the namespace of the url if it is valid")
+			}
+		})
+	}
+}
+
+func TestLegacyRoutes(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Admin)
+	datastore.AddToken(token)
+	a, _ := NewApi(controllers.NewController(nil, nil, &datastore, validators.NewValidator()))
+	component := `{"id": "Comp1-ID", "name": "Component", "apiVersion": "hector/v1", "containerDockerfile": "Dockerfile", "containerImage": "image/name"}`
+
+	// The paths without namespace act on the default namespace and announce their deprecation
+	var tests = []struct {
+		method     string
+		path       string
+		body       string
+		status     int
+		deprecated bool
+	}{
+		{http.MethodPost, "/component/submit", component, http.StatusOK, true},
+		{http.MethodGet, "/namespaces/default/component/get/Comp1-ID", "", http.StatusOK, false},
+		{http.MethodGet, "/component/get/Comp1-ID", "", http.StatusOK, true},
+		{http.MethodGet, "/namespaces/team-a/component/get/Comp1-ID", "", http.StatusNotFound, false},
+		{http.MethodDelete, "/component/delete/Comp1-ID", "", http.StatusOK, true},
+		{http.MethodGet, "/namespaces/default/component/get/Comp1-ID", "", http.StatusNotFound, false},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Bearer "+secret)
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+			if deprecated := recorder.Header().Get("Deprecation") == "true"; deprecated != tt.deprecated {
+				t.Error("got the deprecation ", deprecated, ", want ", tt.deprecated)
+			}
+		})
+	}
+}
+
+func TestSubmitBundle(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	component := `{"id": "Comp-ID", "name": "Comp", "apiVersion": "hector/v1", "inputs": [{"name": "input_1", "type": "string"}], "containerDockerfile": "Dockerfile", "containerDockerfile": "Dockerfile", "containerImage": "image/name"}`
+	specification := `{"id": "Spec-ID", "name": "Spec", "apiVersion": "hector/v1", "spec": {"dag": {"tasks": [{"name": "A", "component": "Comp-ID"}]}}}`
+	definition := `{"name": "Def", "specificationId": "Spec-ID", "apiVersion": "hector/v1", "data": {"tasks": [{"name": "A", "inputs": [{"name": "input_1", "value": "%VALUE"}]}]}}`
+
+	// The bundle is sent as a tar archive whose definition is completed with the value of its input
+	newBundle := func(value string) *bytes.Buffer {
+		buffer := &bytes.Buffer{}
+		archive := tar.NewWriter(buffer)
+		for _, file := range [][2]string{{"components/comp.json", component}, {"specification.json", specification}, {"definition.json", strings.Replace(definition, "%VALUE", value, 1)}} {
+			archive.WriteHeader(&tar.Header{Name: file[0], Mode: 0600, Size: int64(len(file[1])), Typeflag: tar.TypeReg})
+			archive.Write([]byte(file[1]))
+		}
+		archive.Close()
+		return buffer
+	}
+
+	var tests = []struct {
+		body        *bytes.Buffer
+		contentType string
+		status      int
+	}{
+		{newBundle("value"), "application/x-tar", http.StatusCreated},
+		{newBundle("value"), "application/x-tar", http.StatusConflict},
+		{newBundle("value"), "application/json", http.StatusBadRequest},
+		{bytes.NewBufferString(`{"components": [], "specification": null}`), "application/json", http.StatusUnprocessableEntity},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/namespaces/team-a/bundle/submit", tt.body)
+			request.Header.Set("Authorization", "Bearer "+secret)
+			request.Header.Set("Content-Type", tt.contentType)
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+		})
+	}
+
+	// The elements of the accepted bundle are stored in the namespace of the url
+	if _, err := datastore.GetComponent("team-a", "Comp-ID"); err != nil {
+		t.Error(err)
+	}
+	if _, err := datastore.GetPlanning("team-a", "Spec-ID"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestYAMLNegotiation(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	// The type of the input of each component is replaced
+	body := "id: %ID\nname: Comp\napiVersion: hector/v1\ninputs:\n  - name: input_1\n    type: %TYPE\ncontainerDockerfile: Dockerfile\ncontainerImage: image/name\n"
+
+	var tests = []struct {
+		id        string
+		inputType string
+		status    int
+		want      string
+	}{
+		{"Comp-1", "string", http.StatusOK, ""},
+		{"Comp-2", "[1, 2]", http.StatusBadRequest, `"line":6`},
+		{"Comp-3", "unknown", http.StatusUnprocessableEntity, `"line":6`},
+		{"Comp-4", "string\n  bad: indentation", http.StatusBadRequest, `"line":6`},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			content := strings.NewReplacer("%ID", tt.id, "%TYPE", tt.inputType).Replace(body)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/namespaces/team-a/component/submit", strings.NewReader(content))
+			request.Header.Set("Authorization", "Bearer "+secret)
+			request.Header.Set("Content-Type", "application/yaml")
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.want) {
+				t.Error("got ", recorder.Body.String(), ", want ", tt.want)
+			}
+		})
+	}
+
+	// The stored component is returned as YAML if it is accepted
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/namespaces/team-a/component/get/Comp-1", nil)
+	request.Header.Set("Authorization", "Bearer "+secret)
+	request.Header.Set("Accept", "application/json;q=0.5, application/yaml")
+	a.Router.ServeHTTP(recorder, request)
+	if recorder.Header().Get("Content-Type") != "application/yaml" || !strings.Contains(recorder.Body.String(), "id: Comp-1\n") {
+		t.Error("got ", recorder.Header().Get("Content-Type"), ": ", recorder.Body.String())
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/argo"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/specifications"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// importResponse contains the Hector elements converted from an Argo workflow, ready to be submitted
+// as a bundle, and the warnings about the parts of the workflow that have been ignored.
+type importResponse struct {
+	Components    []components.Component        `json:"components"`
+	Specification *specifications.Specification `json:"specification"`
+	Definition    *definitions.Definition       `json:"definition"`
+	Warnings      []string                      `json:"warnings"`
+}
+
+// importArgoWorkflow function is responsible for converting the Argo workflow of the request body
+// (json or YAML) into Hector components, specification and definition, which belong to the namespace
+// of the url. Nothing is stored: the converted elements are recorded in the variable type
+// ResponseWriter so that they can be reviewed and submitted as a bundle. It takes as input the
+// request and the variable type ResponseWriter.
+func (a *Api) importArgoWorkflow(w http.ResponseWriter, r *http.Request) {
+
+	// Read workflow from body
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &errors.InvalidRequestErr{Message: "invalid request: " + err.Error()})
+		return
+	}
+	format := formats.FromMediaType(r.Header.Get("Content-Type"))
+	workflow := argo.Workflow{}
+	if err := decodeBody(content, format, &workflow); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Convert the workflow, locating the problems in YAML documents
+	bundle, warnings, err := argo.Import(&workflow)
+	if err != nil {
+		if conversionErr, ok := err.(*errors.ConversionErr); ok && format == formats.YAML {
+			for i, problem := range conversionErr.Problems {
+				conversionErr.Problems[i].Line = formats.Line(content, problem.Field)
+			}
+		}
+		writeError(w, err)
+		return
+	}
+
+	// All the elements belong to the namespace of the url
+	namespace := mux.Vars(r)["NS"]
+	for i := range bundle.Components {
+		bundle.Components[i].Namespace = namespace
+	}
+	bundle.Specification.Namespace = namespace
+	bundle.Definition.Namespace = namespace
+
+	if warnings == nil {
+		warnings = []string{}
+	}
+	writeResponse(w, r, http.StatusOK, importResponse{Components: bundle.Components, Specification: bundle.Specification, Definition: bundle.Definition, Warnings: warnings})
+}
+
+// exportArgoWorkflow function is responsible for rendering a stored definition as an Argo workflow
+// manifest, which is recorded in the variable type ResponseWriter (as YAML if the client accepts
+// it). It takes as input the request and the variable type ResponseWriter.
+func (a *Api) exportArgoWorkflow(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the namespace and the ID of the url
+	vars := mux.Vars(r)
+	workflow, err := a.Controller.ExportArgo(vars["NS"], vars["ID"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, *workflow)
+}
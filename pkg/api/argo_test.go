@@ -0,0 +1,123 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/schedulers"
+	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestImportArgoWorkflow(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	example, err := os.ReadFile("../../data/argo/example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{string(example), http.StatusOK, "id: dag-diamond-echo\n"},
+		{strings.Replace(string(example), "kind: Workflow", "kind: CronWorkflow", 1), http.StatusUnprocessableEntity, `"problems":[{"field":"kind","line":2,`},
+		{"kind: [", http.StatusBadRequest, `"code":"invalid_request"`},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/namespaces/team-a/argo/import", strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Bearer "+secret)
+			request.Header.Set("Content-Type", "application/yaml")
+			request.Header.Set("Accept", "application/yaml")
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.want) {
+				t.Error("got ", recorder.Body.String(), ", want ", tt.want)
+			}
+		})
+	}
+
+	// Nothing is stored by the conversion
+	if _, err := datastore.GetComponent("team-a", "dag-diamond-echo"); err == nil {
+		t.Error("The converted elements must not be stored")
+	}
+}
+
+func TestExportArgoWorkflow(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	// We store a definition through a bundle
+	definitionId := submitTestBundle(t, a, secret)
+
+	var tests = []struct {
+		id     string
+		status int
+		want   string
+	}{
+		{definitionId, http.StatusOK, "args:\n      - --input_1\n      - '{{inputs.parameters.input_1}}'\n      image: image/name\n"},
+		{definitionId, http.StatusOK, "dependencies:\n        - task-a\n        name: task-b\n"},
+		{"Unknown-ID", http.StatusNotFound, `"code":"not_found"`},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/namespaces/team-a/argo/export/"+tt.id, nil)
+			request.Header.Set("Authorization", "Bearer "+secret)
+			request.Header.Set("Accept", "application/yaml")
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.want) {
+				t.Error("got ", recorder.Body.String(), ", want ", tt.want)
+			}
+		})
+	}
+}
+
+// submitTestBundle function stores in the team-a namespace a definition of two tasks (Task B
+// depends on Task A) through a bundle. It returns the identifier of the definition.
+func submitTestBundle(t *testing.T, a *Api, secret string) string {
+	bundle := `{"components": [{"id": "Comp-ID", "name": "Comp", "apiVersion": "hector/v1", "inputs": [{"name": "input_1", "type": "string"}], "containerDockerfile": "Dockerfile", "containerImage": "image/name"}],
+		"specification": {"id": "Spec-ID", "name": "Spec", "apiVersion": "hector/v1", "spec": {"dag": {"tasks": [{"name": "Task A", "component": "Comp-ID"}, {"name": "Task B", "component": "Comp-ID", "dependencies": ["Task A"]}]}}},
+		"definition": {"name": "Def", "specificationId": "Spec-ID", "apiVersion": "hector/v1", "data": {"tasks": [{"name": "Task A", "inputs": [{"name": "input_1", "value": "a"}]}, {"name": "Task B", "inputs": [{"name": "input_1", "value": "b"}]}]}}}`
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/namespaces/team-a/bundle/submit", strings.NewReader(bundle))
+	request.Header.Set("Authorization", "Bearer "+secret)
+	a.Router.ServeHTTP(recorder, request)
+	response := bundleResponse{}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil || response.Definition == "" {
+		t.Fatal("the bundle could not be submitted: ", err)
+	}
+	return response.Definition
+}
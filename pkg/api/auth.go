@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/tokens"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// contextKey is the type of the keys under which the api stores values in the request context.
+type contextKey int
+
+// tokenContextKey is the key of the token that authenticated the request.
+const tokenContextKey contextKey = iota
+
+// tokenResponse is returned when a token is created. It is the only time the secret is revealed.
+type tokenResponse struct {
+	Token  tokens.Token `json:"token"`
+	Secret string       `json:"secret"`
+}
+
+// authorization function builds the middleware that authenticates the requests through the bearer
+// token of the Authorization header and checks that its role is allowed to use the requested route.
+// Routes without a required role are public. It takes as input the role required by each route,
+// indexed by route name. Returns the middleware.
+func (a *Api) authorization(roles map[string]tokens.Role) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			// We find the role required by the route
+			required := roles[mux.CurrentRoute(r).GetName()]
+			if required == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// We authenticate the token
+			header := r.Header.Get("Authorization")
+			secret := strings.TrimPrefix(header, "Bearer ")
+			if secret == header || secret == "" {
+				writeError(w, &errors.UnauthorizedErr{Message: "a bearer token is required"})
+				return
+			}
+			token, err := (*a.Controller.Datastore).GetTokenByHash(tokens.HashSecret(secret))
+			if err != nil {
+				if _, notFound := err.(*errors.ElementNotFoundErr); notFound {
+					err = &errors.UnauthorizedErr{Message: "invalid token"}
+				}
+				writeError(w, err)
+				return
+			}
+
+			// We check its role
+			if !token.Role.Allows(required) {
+				writeError(w, &errors.ForbiddenErr{Message: "the " + string(token.Role) + " role is not allowed to perform this operation, which requires the " + string(required) + " role"})
+				return
+			}
+
+			// We check its namespaces (the routes outside the namespaces require an unrestricted token)
+			if namespace, namespaced := mux.Vars(r)["NS"]; namespaced && !token.AllowsNamespace(namespace) {
+				writeError(w, &errors.ForbiddenErr{Message: "the token is not allowed to access the namespace " + namespace})
+				return
+			} else if !namespaced && !token.Unrestricted() {
+				writeError(w, &errors.ForbiddenErr{Message: "this operation requires a token that is not restricted to some namespaces"})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey, token)))
+		})
+	}
+}
+
+// requestToken function returns the token that authenticated a request (nil for public routes).
+func requestToken(r *http.Request) *tokens.Token {
+	token, _ := r.Context().Value(tokenContextKey).(*tokens.Token)
+	return token
+}
+
+// checkOwnership function ensures that the identity of a request can act on a given definition.
+// Admins can act on any definition, while the rest of roles can only act on the definitions submitted
+// with the same token (several identities may share a name). It takes as input the request and the
+// namespace and identifier of the definition. Returns an error variable to report any problems.
+func (a *Api) checkOwnership(r *http.Request, namespace string, definitionId string) error {
+	token := requestToken(r)
+	if token.Role.Allows(tokens.Admin) {
+		return nil
+	}
+	definition, err := (*a.Controller.Datastore).GetDefinition(namespace, definitionId)
+	if err != nil {
+		return err
+	}
+	if definition.SubmitterId == "" || definition.SubmitterId != token.Id {
+		return &errors.ForbiddenErr{Message: "the definition " + definitionId + " was submitted by another identity"}
+	}
+	return nil
+}
+
+// createToken function extracts the Token element from the request body, generates its secret
+// and inserts it into the datastore. Finally, it records the token together with its secret in
+// the variable type ResponseWriter. It takes as input the request and the variable type
+// ResponseWriter.
+func (a *Api) createToken(w http.ResponseWriter, r *http.Request) {
+
+	// Read token from body and validate scheme
+	request, err := readAndValidateElement(a.Controller.Validator.ValidateTokenStruct, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Generate the token and its secret
+	token, secret := tokens.NewToken(request.Name, request.Role)
+	token.Namespaces = request.Namespaces
+
+	// Add token to datastore
+	if err := (*a.Controller.Datastore).AddToken(token); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// We return the secret, which will not be shown again
+	token.Hash = ""
+	writeResponse(w, r, http.StatusCreated, tokenResponse{Token: *token, Secret: secret})
+}
+
+// listTokens function is responsible for resolving requests for the list of Token elements. The
+// hashes of the secrets are not included. It takes as input the request and the ResponseWriter
+// variable.
+func (a *Api) listTokens(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the listing options from the url
+	options, err := readListOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// We launch a query to the datastore
+	page, err := (*a.Controller.Datastore).ListTokens(options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range page.Items {
+		page.Items[i].Hash = ""
+	}
+
+	// We write the output in the response writer
+	writeResponse(w, r, http.StatusOK, *page)
+}
+
+// deleteToken function is responsible for revoking a Token. It takes as input the request and
+// the ResponseWriter variable.
+func (a *Api) deleteToken(w http.ResponseWriter, r *http.Request) {
+	if err := (*a.Controller.Datastore).DeleteToken(mux.Vars(r)["ID"]); err != nil {
+		writeError(w, err)
+		return
+	}
+}
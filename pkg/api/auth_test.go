@@ -0,0 +1,124 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAuthorization(t *testing.T) {
+
+	// Create a datastore with a token of each role, an admin token restricted to the namespace team-a,
+	// two submitter tokens named bob and a finished definition submitted with the first of them
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	secrets := make(map[tokens.Role]string)
+	for _, role := range []tokens.Role{tokens.Viewer, tokens.Submitter, tokens.Admin} {
+		token, secret := tokens.NewToken("alice", role)
+		datastore.AddToken(token)
+		secrets[role] = secret
+	}
+	restricted, restrictedSecret := tokens.NewToken("carol", tokens.Admin)
+	restricted.Namespaces = []string{"team-a"}
+	datastore.AddToken(restricted)
+	owner, ownerSecret := tokens.NewToken("bob", tokens.Submitter)
+	datastore.AddToken(owner)
+	namesake, namesakeSecret := tokens.NewToken("bob", tokens.Submitter)
+	datastore.AddToken(namesake)
+	datastore.AddDefinition(&definitions.Definition{Id: "Def-ID", Namespace: "default", SubmittedBy: "bob", SubmitterId: owner.Id})
+	datastore.AddResultDefinition(&results.ResultDefinition{Id: "Def-ID", Namespace: "default", SubmittedBy: "bob", ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: results.Done}}})
+
+	a, _ := NewApi(controllers.NewController(nil, nil, &datastore, validators.NewValidator()))
+
+	var tests = []struct {
+		method string
+		path   string
+		secret string
+		status int
+	}{
+		{http.MethodGet, "/openapi.json", "", http.StatusOK},
+		{http.MethodGet, "/namespaces/default/result/get/Def-ID", "", http.StatusUnauthorized},
+		{http.MethodGet, "/namespaces/default/result/get/Def-ID", "hector_unknown", http.StatusUnauthorized},
+		{http.MethodGet, "/namespaces/default/result/get/Def-ID", secrets[tokens.Viewer], http.StatusOK},
+		{http.MethodGet, "/namespaces/team-a/result/get/Def-ID", secrets[tokens.Viewer], http.StatusNotFound},
+		{http.MethodGet, "/namespaces/Team_A/result/get/Def-ID", secrets[tokens.Viewer], http.StatusBadRequest},
+		{http.MethodPost, "/namespaces/default/definition/cancel/Def-ID", secrets[tokens.Viewer], http.StatusForbidden},
+		{http.MethodPost, "/namespaces/default/definition/cancel/Def-ID", secrets[tokens.Submitter], http.StatusForbidden},
+		{http.MethodPost, "/namespaces/default/definition/cancel/Def-ID", secrets[tokens.Admin], http.StatusConflict},
+		{http.MethodPost, "/namespaces/default/definition/cancel/Def-ID", ownerSecret, http.StatusConflict},
+		{http.MethodPost, "/namespaces/default/definition/cancel/Def-ID", namesakeSecret, http.StatusForbidden},
+		{http.MethodGet, "/namespaces/default/result/get/Def-ID", restrictedSecret, http.StatusForbidden},
+		{http.MethodGet, "/namespaces/team-a/result/get/Def-ID", restrictedSecret, http.StatusNotFound},
+		{http.MethodGet, "/result/get/Def-ID", secrets[tokens.Viewer], http.StatusOK},
+		{http.MethodGet, "/result/get/Def-ID", restrictedSecret, http.StatusForbidden},
+		{http.MethodGet, "/token/list", secrets[tokens.Submitter], http.StatusForbidden},
+		{http.MethodGet, "/token/list", secrets[tokens.Admin], http.StatusOK},
+		{http.MethodGet, "/token/list", restrictedSecret, http.StatusForbidden},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.secret != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.secret)
+			}
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	admin, secret := tokens.NewToken("root", tokens.Admin)
+	datastore.AddToken(admin)
+	a, _ := NewApi(controllers.NewController(nil, nil, &datastore, validators.NewValidator()))
+
+	var tests = []struct {
+		body   string
+		status int
+	}{
+		{`{"name": "alice", "role": "submitter"}`, http.StatusCreated},
+		{`{"name": "alice", "role": "owner"}`, http.StatusUnprocessableEntity},
+		{`{"name": "alice", "role": "admin", "hash": "chosen"}`, http.StatusUnprocessableEntity},
+		{`{"name": "alice", "role": "viewer", "namespaces": ["team-a"]}`, http.StatusCreated},
+		{`{"name": "alice", "role": "viewer", "namespaces": ["Team_A"]}`, http.StatusUnprocessableEntity},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/token/create", strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Bearer "+secret)
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			} else if tt.status == http.StatusCreated && strings.Contains(recorder.Body.String(), `"hash"`) {
+				t.Error("The hash of the token must not be returned")
+			}
+		})
+	}
+
+	// Only the valid requests have stored a new token
+	page, _ := datastore.ListTokens(&datastores.ListOptions{})
+	if len(page.Items) != 3 {
+		t.Fatal("got ", len(page.Items), " tokens, want 3")
+	}
+}
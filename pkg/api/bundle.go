@@ -0,0 +1,114 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/bundles"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/tracing"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/rs/xid"
+)
+
+// tarContentType is the media type of the bundles sent as tar archives (the rest are read as json).
+const tarContentType = "application/x-tar"
+
+// bundleResponse contains the identifiers of the elements stored from a bundle.
+type bundleResponse struct {
+	Components    []string `json:"components"`
+	Specification string   `json:"specification,omitempty"`
+	Definition    string   `json:"definition,omitempty"`
+}
+
+// submitBundle function is responsible for extracting a bundle of components, specification and
+// definition from the request body (a json or YAML document, or a tar archive), validating all of them together
+// and storing them at once. The definition (if any) is queued for its execution. Finally, it records
+// the identifiers of the stored elements in the variable type ResponseWriter. It takes as input the
+// request and the variable type ResponseWriter.
+func (a *Api) submitBundle(w http.ResponseWriter, r *http.Request) {
+
+	// Read bundle from body and validate scheme
+	bundle, err := a.readBundle(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// All the elements belong to the namespace of the url
+	for i := range bundle.Components {
+		if err := setNamespace(r, &bundle.Components[i].Namespace); err != nil {
+			writeError(w, prefixField(err, fmt.Sprintf("components[%d].", i)))
+			return
+		}
+	}
+	if bundle.Specification != nil {
+		if err := setNamespace(r, &bundle.Specification.Namespace); err != nil {
+			writeError(w, prefixField(err, "specification."))
+			return
+		}
+	}
+	if bundle.Definition != nil {
+		if err := setNamespace(r, &bundle.Definition.Namespace); err != nil {
+			writeError(w, prefixField(err, "definition."))
+			return
+		}
+
+		// Generate random id and record the submitting identity
+		bundle.Definition.Id = xid.New().String()
+		bundle.Definition.SubmittedBy = requestToken(r).Name
+		bundle.Definition.SubmitterId = requestToken(r).Id
+		bundle.Definition.RequestId = logging.RequestId(r.Context())
+		bundle.Definition.Traceparent = tracing.Traceparent(r.Context())
+	}
+
+	// Validate the elements together and store them
+	if _, err := a.Controller.SubmitBundle(&bundle); err != nil {
+		writeError(w, fmt.Errorf("error during submission of the bundle %w", err))
+		return
+	}
+
+	// If everything has gone well, we return the identifiers of the stored elements
+	response := bundleResponse{Components: []string{}}
+	for _, component := range bundle.Components {
+		response.Components = append(response.Components, component.Id)
+	}
+	if bundle.Specification != nil {
+		response.Specification = bundle.Specification.Id
+	}
+	if bundle.Definition != nil {
+		response.Definition = bundle.Definition.Id
+	}
+	writeResponse(w, r, http.StatusCreated, response)
+}
+
+// readBundle function reads a bundle from the request body, either from a tar archive (if the
+// request is sent with the application/x-tar content type) or from a json or YAML document, and validates
+// the structure of its elements. It takes as input the request. Returns the bundle read and an
+// error variable to report any problems.
+func (a *Api) readBundle(r *http.Request) (bundles.Bundle, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != tarContentType {
+		return readAndValidateElement(a.Controller.Validator.ValidateBundleStruct, r)
+	}
+
+	bundle, err := bundles.FromTar(r.Body)
+	if err != nil {
+		return bundles.Bundle{}, &errors.InvalidRequestErr{Message: "invalid request: " + err.Error()}
+	}
+	if err := a.Controller.Validator.ValidateBundleStruct(bundle); err != nil {
+		return *bundle, fmt.Errorf("invalid scheme: %w", err)
+	}
+	return *bundle, nil
+}
+
+// prefixField function completes the field of an invalid request whose path is relative to an
+// element of the bundle. It takes as input the error and the path of the element. Returns the
+// completed error.
+func prefixField(err error, prefix string) error {
+	if invalidRequestErr, ok := err.(*errors.InvalidRequestErr); ok {
+		invalidRequestErr.Field = prefix + invalidRequestErr.Field
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/validators"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+)
+
+// errorResponse is the envelope returned to the client whenever a request fails.
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Line    int    `json:"line,omitempty"`
+
+	Problems []errorProblem `json:"problems,omitempty"`
+}
+
+// errorProblem is each one of the problems that make a document invalid.
+// Violations of the validator also carry the JSON pointer of the invalid value and a machine code.
+type errorProblem struct {
+	Field   string `json:"field"`
+	Pointer string `json:"pointer,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// errorStatus function selects the HTTP status and the envelope that correspond to an error. Typed
+// errors are searched through the whole chain of wrapped errors, while the details of the unexpected
+// ones are not disclosed. It takes as input the error and returns the status code and the envelope.
+func errorStatus(err error) (int, errorResponse) {
+	var notFoundErr *errors.ElementNotFoundErr
+	var unauthorizedErr *errors.UnauthorizedErr
+	var forbiddenErr *errors.ForbiddenErr
+	var duplicateIDErr *errors.DuplicateIDErr
+	var referencedElementErr *errors.ReferencedElementErr
+	var invalidStateErr *errors.InvalidStateErr
+	var invalidRequestErr *errors.InvalidRequestErr
+	var validationErr *errors.ValidationErr
+	var executorErr *errors.ExecutorErr
+	var conversionErr *errors.ConversionErr
+	var violations validators.Violations
+
+	switch {
+	case stderrors.As(err, &unauthorizedErr):
+		return http.StatusUnauthorized, errorResponse{Code: "unauthorized", Message: unauthorizedErr.Error()}
+	case stderrors.As(err, &forbiddenErr):
+		return http.StatusForbidden, errorResponse{Code: "forbidden", Message: forbiddenErr.Error()}
+	case stderrors.As(err, &notFoundErr):
+		return http.StatusNotFound, errorResponse{Code: "not_found", Message: notFoundErr.Error()}
+	case stderrors.As(err, &duplicateIDErr):
+		return http.StatusConflict, errorResponse{Code: "duplicate_id", Message: duplicateIDErr.Error()}
+	case stderrors.As(err, &referencedElementErr):
+		return http.StatusConflict, errorResponse{Code: "referenced_element", Message: referencedElementErr.Error()}
+	case stderrors.As(err, &invalidStateErr):
+		return http.StatusConflict, errorResponse{Code: "invalid_state", Message: invalidStateErr.Error()}
+	case stderrors.As(err, &invalidRequestErr):
+		return http.StatusBadRequest, errorResponse{Code: "invalid_request", Message: invalidRequestErr.Error(), Field: invalidRequestErr.Field, Line: invalidRequestErr.Line}
+	case stderrors.As(err, &violations) && len(violations) > 0:
+		response := errorResponse{Code: "validation_failed", Message: violations.Error(), Field: violations[0].Field(), Line: violations[0].Line}
+		for _, violation := range violations {
+			response.Problems = append(response.Problems, errorProblem{Field: violation.Field(), Pointer: violation.Pointer, Code: violation.Code, Line: violation.Line, Message: violation.Message})
+		}
+		return http.StatusUnprocessableEntity, response
+	case stderrors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity, errorResponse{Code: "validation_failed", Message: validationErr.Error(), Field: validationErr.Field, Line: validationErr.Line}
+	case stderrors.As(err, &conversionErr):
+		response := errorResponse{Code: "conversion_failed", Message: conversionErr.Error()}
+		for _, problem := range conversionErr.Problems {
+			response.Problems = append(response.Problems, errorProblem{Field: problem.Field, Line: problem.Line, Message: problem.Message})
+		}
+		return http.StatusUnprocessableEntity, response
+	case stderrors.As(err, &executorErr):
+		return http.StatusBadGateway, errorResponse{Code: "executor_error", Message: executorErr.Error()}
+	default:
+		return http.StatusInternalServerError, errorResponse{Code: "internal_error", Message: "an internal error has occurred"}
+	}
+}
+
+// writeError function records an error in the response following the JSON error envelope. Server
+// side errors are also kept to be logged with the request, whose identifier is added to the message of
+// the unexpected errors so that their details can be found in the logs. It takes as input the variable
+// type ResponseWriter and the error.
+func writeError(w http.ResponseWriter, err error) {
+	status, response := errorStatus(err)
+	if recorder, ok := w.(*responseRecorder); ok && status >= http.StatusInternalServerError {
+		recorder.err = err
+	}
+	if requestId := w.Header().Get(logging.RequestIdHeader); status == http.StatusInternalServerError && requestId != "" {
+		response.Message += " (request " + requestId + ")"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
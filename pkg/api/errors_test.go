@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/validators"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestErrorStatus(t *testing.T) {
+	var tests = []struct {
+		err      error
+		status   int
+		response errorResponse
+	}{
+		{
+			err:      &errors.ElementNotFoundErr{Type: "components.Component", Id: "comp-A"},
+			status:   http.StatusNotFound,
+			response: errorResponse{Code: "not_found", Message: "components.Component with id comp-A not found in database."},
+		},
+		{
+			err:      fmt.Errorf("error during insertion into the datastore %w", &errors.DuplicateIDErr{Type: "components.Component", Id: "comp-A"}),
+			status:   http.StatusConflict,
+			response: errorResponse{Code: "duplicate_id", Message: "A components.Component with id comp-A is already stored in the database."},
+		},
+		{
+			err:      fmt.Errorf("invalid scheme: %w", &errors.ValidationErr{Field: "inputs[1].type", Message: "invalid type"}),
+			status:   http.StatusUnprocessableEntity,
+			response: errorResponse{Code: "validation_failed", Message: "invalid type", Field: "inputs[1].type"},
+		},
+		{
+			err:    fmt.Errorf("invalid scheme: %w", validators.Violations{{Pointer: "/inputs/1/type", Code: "required", Message: "type is required", Line: 7}, {Pointer: "/containerImage", Code: "required", Message: "containerImage is required"}}),
+			status: http.StatusUnprocessableEntity,
+			response: errorResponse{Code: "validation_failed", Message: "type is required; containerImage is required", Field: "inputs[1].type", Line: 7, Problems: []errorProblem{
+				{Field: "inputs[1].type", Pointer: "/inputs/1/type", Code: "required", Line: 7, Message: "type is required"},
+				{Field: "containerImage", Pointer: "/containerImage", Code: "required", Message: "containerImage is required"},
+			}},
+		},
+		{
+			err:      &errors.InvalidRequestErr{Field: "limit", Message: "invalid limit a"},
+			status:   http.StatusBadRequest,
+			response: errorResponse{Code: "invalid_request", Message: "invalid limit a", Field: "limit"},
+		},
+		{
+			err:      &errors.InvalidStateErr{Type: "definition", Id: "def-A", Message: "has already finished"},
+			status:   http.StatusConflict,
+			response: errorResponse{Code: "invalid_state", Message: "The definition with id def-A has already finished."},
+		},
+		{
+			err:      &errors.UnauthorizedErr{Message: "missing bearer token"},
+			status:   http.StatusUnauthorized,
+			response: errorResponse{Code: "unauthorized", Message: "missing bearer token"},
+		},
+		{
+			err:      &errors.ForbiddenErr{Message: "the viewer role is not allowed"},
+			status:   http.StatusForbidden,
+			response: errorResponse{Code: "forbidden", Message: "the viewer role is not allowed"},
+		},
+		{
+			err:      &errors.ConversionErr{Source: "Argo workflow", Problems: []errors.ValidationErr{{Field: "kind", Line: 2, Message: "unsupported kind"}}},
+			status:   http.StatusUnprocessableEntity,
+			response: errorResponse{Code: "conversion_failed", Message: "The Argo workflow cannot be converted: line 2: kind: unsupported kind.", Problems: []errorProblem{{Field: "kind", Line: 2, Message: "unsupported kind"}}},
+		},
+		{
+			err:      fmt.Errorf("unexpected error"),
+			status:   http.StatusInternalServerError,
+			response: errorResponse{Code: "internal_error", Message: "an internal error has occurred"},
+		},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			status, response := errorStatus(tt.err)
+			if status != tt.status {
+				t.Error("got ", status, ", want ", tt.status)
+			}
+			if !reflect.DeepEqual(response, tt.response) {
+				t.Error("got ", response, ", want ", tt.response)
+			}
+		})
+	}
+}
+
+func TestWriteUnexpectedError(t *testing.T) {
+	var out bytes.Buffer
+	a := &Api{}
+	a.Logger, _ = logging.NewLogger("info", logging.JSONFormat, &out)
+	handler := a.logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, fmt.Errorf("database is locked: /var/lib/hector/hector.sqlite"))
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/components", nil)
+	request.Header.Set(logging.RequestIdHeader, "req-1234")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	// We check that the details of the error are not disclosed to the client
+	var response errorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err.Error())
+	}
+	if recorder.Code != http.StatusInternalServerError || strings.Contains(recorder.Body.String(), "hector.sqlite") {
+		t.Error("got the response ", recorder.Code, " ", recorder.Body.String())
+	}
+	if response.Code != "internal_error" || !strings.Contains(response.Message, "req-1234") {
+		t.Error("got the envelope ", response)
+	}
+
+	// We check that they are logged together with the request id
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatal(err.Error())
+	}
+	if line[logging.RequestIdField] != "req-1234" || !strings.Contains(line["error"].(string), "hector.sqlite") {
+		t.Error("got the line ", out.String())
+	}
+}
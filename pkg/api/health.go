@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// checkTimeout is the maximum time given to each dependency to answer a readiness check.
+const checkTimeout = 3 * time.Second
+
+// We declare the statuses of the health checks.
+const (
+	statusOk          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// pinger is implemented by the datastores and the executors that depend on an external service
+// (a database, a nomad agent or a docker daemon), so that the readiness of the api can be checked.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// checkResult contains the outcome of the check of a dependency.
+type checkResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthResponse is the body of the health and readiness routes.
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []checkResult `json:"checks,omitempty"`
+}
+
+// getHealth function is responsible for resolving the liveness requests, which only report that
+// the api is serving. It takes as input the request and the ResponseWriter variable.
+func (a *Api) getHealth(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, http.StatusOK, healthResponse{Status: statusOk})
+}
+
+// getReadiness function is responsible for resolving the readiness requests. It checks that the
+// datastore and the executor can be reached (those that do not depend on an external service are
+// not checked) and reports the status of each of them, answering with 503 if any is unavailable.
+// It takes as input the request and the ResponseWriter variable.
+func (a *Api) getReadiness(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the dependencies that can be checked
+	dependencies := map[string]any{}
+	if a.Controller != nil && a.Controller.Datastore != nil {
+		dependencies["datastore"] = *a.Controller.Datastore
+	}
+	if a.Controller != nil && a.Controller.Executor != nil {
+		dependencies["executor"] = *a.Controller.Executor
+	}
+
+	response := healthResponse{Status: statusOk}
+	for _, name := range []string{"datastore", "executor"} {
+		p, ok := dependencies[name].(pinger)
+		if !ok {
+			continue
+		}
+		result := check(r.Context(), name, p)
+		if result.Status != statusOk {
+			response.Status = statusUnavailable
+		}
+		response.Checks = append(response.Checks, result)
+	}
+
+	status := http.StatusOK
+	if response.Status != statusOk {
+		status = http.StatusServiceUnavailable
+	}
+	writeResponse(w, r, status, response)
+}
+
+// check function pings a dependency, giving up after checkTimeout. It takes as input the context
+// of the request, the name of the dependency and the dependency itself. Returns the result of the
+// check.
+func check(ctx context.Context, name string, p pinger) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	// The clients of some dependencies do not support contexts, so we stop waiting on the timeout
+	errCh := make(chan error, 1)
+	go func() { errCh <- p.Ping(ctx) }()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return checkResult{Name: name, Status: statusUnavailable, Error: err.Error()}
+	}
+	return checkResult{Name: name, Status: statusOk}
+}
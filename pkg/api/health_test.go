@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/executors"
+	"dag/hector/golang/module/pkg/executors/execmock"
+	"dag/hector/golang/module/pkg/validators"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pingedDatastore is a mocked datastore whose database answers the pings with the given error.
+type pingedDatastore struct {
+	*dbmock.DBMock
+	err error
+}
+
+func (pd *pingedDatastore) Ping(ctx context.Context) error {
+	return pd.err
+}
+
+// pingedExecutor is a mocked executor whose service answers the pings with the given error.
+type pingedExecutor struct {
+	*execmock.ExecMock
+	err error
+}
+
+func (pe *pingedExecutor) Ping(ctx context.Context) error {
+	return pe.err
+}
+
+func TestHealth(t *testing.T) {
+	var tests = []struct {
+		path      string
+		datastore datastores.Datastore
+		executor  executors.Executor
+		status    int
+		want      string
+	}{
+		{"/healthz", &pingedDatastore{dbmock.NewDBMock(), fmt.Errorf("database is locked")}, nil, http.StatusOK, `{"status":"ok"}`},
+		{"/readyz", dbmock.NewDBMock(), execmock.NewExecMock(), http.StatusOK, `{"status":"ok"}`},
+		{"/readyz", &pingedDatastore{dbmock.NewDBMock(), nil}, &pingedExecutor{execmock.NewExecMock(), nil}, http.StatusOK, `{"status":"ok","checks":[{"name":"datastore","status":"ok"},{"name":"executor","status":"ok"}]}`},
+		{"/readyz", &pingedDatastore{dbmock.NewDBMock(), nil}, &pingedExecutor{execmock.NewExecMock(), fmt.Errorf("connection refused")}, http.StatusServiceUnavailable, `{"status":"unavailable","checks":[{"name":"datastore","status":"ok"},{"name":"executor","status":"unavailable","error":"connection refused"}]}`},
+		{"/readyz", &pingedDatastore{dbmock.NewDBMock(), fmt.Errorf("database is locked")}, nil, http.StatusServiceUnavailable, `{"status":"unavailable","checks":[{"name":"datastore","status":"unavailable","error":"database is locked"}]}`},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			var executor *executors.Executor
+			if tt.executor != nil {
+				executor = &tt.executor
+			}
+			a, _ := NewApi(controllers.NewController(executor, nil, &tt.datastore, validators.NewValidator()))
+
+			// The health routes do not need a token
+			recorder := httptest.NewRecorder()
+			a.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			} else if body := strings.TrimSpace(recorder.Body.String()); body != tt.want {
+				t.Error("got ", body, ", want ", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/logging"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/sirupsen/logrus"
+)
+
+// validRequestId contains the identifiers of requests that are accepted from the clients.
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// responseRecorder keeps the status of a response and the server side error reported in it, so
+// that they can be logged once the request has been handled.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	err    error
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Flush function allows to stream the responses (e.g. Server-Sent Events) through the recorder.
+func (rr *responseRecorder) Flush() {
+	if flusher, ok := rr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// logRequests function is the middleware that identifies every request and logs it once it has
+// been handled. The identifier sent by the client in the X-Request-Id header is reused if it is
+// valid (otherwise a new one is generated), returned in the same header and carried by the context
+// of the request, so that the lines of the components are correlated with it.
+func (a *Api) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// We identify the request
+		requestId := r.Header.Get(logging.RequestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			requestId = xid.New().String()
+		}
+		w.Header().Set(logging.RequestIdHeader, requestId)
+		ctx := logging.WithFields(r.Context(), logrus.Fields{logging.RequestIdField: requestId})
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		// We log the request, together with the server side errors
+		logger := logging.FromContext(a.Logger, ctx).WithFields(logrus.Fields{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      recorder.status,
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		if recorder.err != nil {
+			logger.WithError(recorder.err).Error("request failed")
+		} else {
+			logger.Info("request handled")
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bytes"
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/validators"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestLogRequests(t *testing.T) {
+	var tests = []struct {
+		requestId string
+		reused    bool
+	}{
+		{"", false},
+		{"req-1234", true},
+		{"a request id with spaces", false},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			var datastore datastores.Datastore = dbmock.NewDBMock()
+			a, _ := NewApi(controllers.NewController(nil, nil, &datastore, validators.NewValidator()))
+			var out bytes.Buffer
+			a.Logger, _ = logging.NewLogger("info", logging.JSONFormat, &out)
+
+			request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			if tt.requestId != "" {
+				request.Header.Set(logging.RequestIdHeader, tt.requestId)
+			}
+			recorder := httptest.NewRecorder()
+			a.Router.ServeHTTP(recorder, request)
+
+			// We check the identifier returned to the client
+			requestId := recorder.Header().Get(logging.RequestIdHeader)
+			if !validRequestId.MatchString(requestId) {
+				t.Fatal("got an invalid request id ", requestId)
+			}
+			if (requestId == tt.requestId) != tt.reused {
+				t.Error("got the request id ", requestId, " for the sent ", tt.requestId)
+			}
+
+			// We check that the logged line is correlated with the request
+			var line map[string]any
+			if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+				t.Fatal(err.Error())
+			}
+			if line[logging.RequestIdField] != requestId || line["path"] != "/healthz" || line["status"] != float64(http.StatusOK) {
+				t.Error("got the line ", out.String())
+			}
+		})
+	}
+}
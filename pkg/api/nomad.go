@@ -0,0 +1,46 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/executors/nomad"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// exportNomadJob function is responsible for rendering a stored definition as a single nomad job
+// specification, in HCL (by default) or JSON depending on the format query parameter, which is
+// recorded in the variable type ResponseWriter. It takes as input the request and the variable type
+// ResponseWriter.
+func (a *Api) exportNomadJob(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the namespace and the ID of the url, and the format of the query
+	vars := mux.Vars(r)
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = nomad.HCL
+	}
+	if format != nomad.HCL && format != nomad.JSON {
+		writeError(w, &errors.InvalidRequestErr{Field: "format", Message: "invalid format " + format + " (the valid ones are " + nomad.HCL + " and " + nomad.JSON + ")"})
+		return
+	}
+
+	// We resolve the jobs of the definition and render them
+	definition, _, nestedJobs, err := a.Controller.PlanDefinition(vars["NS"], vars["ID"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	content, err := nomad.RenderJob(nomad.BuildDefinitionJob(definition, nestedJobs, &a.NomadExport), format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format == nomad.JSON {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Write(content)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/schedulers"
+	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExportNomadJob(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	// We store a definition through a bundle
+	definitionId := submitTestBundle(t, a, secret)
+
+	var tests = []struct {
+		id     string
+		query  string
+		status int
+		want   string
+	}{
+		{definitionId, "", http.StatusOK, "job \"hector-" + definitionId + "\" {"},
+		{definitionId, "?format=hcl", http.StatusOK, "  group \"task-b\" {\n    restart {\n      attempts = 0\n    }\n\n    task \"await-dependencies\" {"},
+		{definitionId, "?format=json", http.StatusOK, "\"ID\": \"hector-" + definitionId + "\""},
+		{definitionId, "?format=yaml", http.StatusBadRequest, `"field":"format"`},
+		{"Unknown-ID", "", http.StatusNotFound, `"code":"not_found"`},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/namespaces/team-a/nomad/export/"+tt.id+tt.query, nil)
+			request.Header.Set("Authorization", "Bearer "+secret)
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.want) {
+				t.Error("got ", recorder.Body.String(), ", want ", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/results"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type OpenAPIDocument struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       OpenAPIInfo                     `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components OpenAPIComponents               `json:"components"`
+
+	types map[string]reflect.Type // Type registered under each schema name
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type Operation struct {
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	OperationId string                `json:"operationId"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+}
+
+// pathParameterRegexp matches the variables of the route paths (e.g. {ID}).
+var pathParameterRegexp = regexp.MustCompile(`{([^}]+)}`)
+
+// newOpenAPIDocument function generates the OpenAPI 3 document that describes the given routes.
+// The schemas of the bodies are obtained by reflection from the exchanged types, including the
+// restrictions established in their validate tags. It takes as input the table of routes and
+// returns the pointer to the constructed document.
+func newOpenAPIDocument(routes []route) *OpenAPIDocument {
+	doc := OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: "Hector", Version: "v1"},
+		Paths:   map[string]map[string]Operation{},
+		Components: OpenAPIComponents{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{"bearer": {Type: "http", Scheme: "bearer"}},
+		},
+		types: map[string]reflect.Type{},
+	}
+
+	// The error envelope is shared by all the operations
+	errorSchema := doc.schemaOf(reflect.TypeOf(errorResponse{}))
+
+	for _, rt := range routes {
+		operation := Operation{
+			Summary:     rt.Summary,
+			OperationId: operationId(rt),
+			Responses:   map[string]Response{},
+			Deprecated:  rt.Deprecated,
+		}
+
+		// Required role
+		if rt.Role != "" {
+			operation.Description = "Requires a token with the " + string(rt.Role) + " role."
+			operation.Security = []map[string][]string{{"bearer": {}}}
+		}
+
+		// Path and query parameters
+		for _, match := range pathParameterRegexp.FindAllStringSubmatch(rt.Path, -1) {
+			operation.Parameters = append(operation.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, name := range rt.Query {
+			operation.Parameters = append(operation.Parameters, Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+		}
+
+		// Request body, which may be sent as json or YAML
+		if rt.Request != nil {
+			schema := doc.schemaOf(reflect.TypeOf(rt.Request))
+			operation.RequestBody = &RequestBody{
+				Required: !rt.RequestOptional,
+				Content:  map[string]MediaType{string(formats.JSON): {Schema: schema}, string(formats.YAML): {Schema: schema}},
+			}
+		}
+
+		// Responses
+		response := Response{Description: http.StatusText(rt.Status)}
+		if rt.Response != nil {
+			schema := doc.schemaOf(reflect.TypeOf(rt.Response))
+			if rt.Stream {
+				response.Content = map[string]MediaType{"text/event-stream": {Schema: schema}}
+			} else {
+				response.Content = map[string]MediaType{string(formats.JSON): {Schema: schema}, string(formats.YAML): {Schema: schema}}
+			}
+		}
+		operation.Responses[strconv.Itoa(rt.Status)] = response
+		operation.Responses["default"] = Response{Description: "Error", Content: map[string]MediaType{"application/json": {Schema: errorSchema}}}
+
+		if doc.Paths[rt.Path] == nil {
+			doc.Paths[rt.Path] = map[string]Operation{}
+		}
+		doc.Paths[rt.Path][strings.ToLower(rt.Method)] = operation
+	}
+
+	return &doc
+}
+
+// operationId function builds a unique identifier for the operation of a route from its path.
+func operationId(rt route) string {
+	var words []string
+	for _, segment := range strings.Split(pathParameterRegexp.ReplaceAllString(rt.Path, ""), "/") {
+		segment = strings.TrimSuffix(segment, ".json")
+		if segment != "" {
+			words = append(words, strings.ToUpper(segment[:1])+segment[1:])
+		}
+	}
+	return strings.ToLower(rt.Method) + strings.Join(words, "")
+}
+
+// schemaOf function returns the schema of a given type. Named structs are registered in the
+// components section of the document and referenced from the returned schema. It takes as input
+// the type and returns the pointer to the schema.
+func (doc *OpenAPIDocument) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Enumerated status of the jobs
+	if t == reflect.TypeOf(results.Status(0)) {
+		schema := &Schema{Type: "integer"}
+		var names []string
+		for s := results.Status(0); !strings.HasPrefix(s.String(), "Status("); s++ {
+			schema.Enum = append(schema.Enum, int64(s))
+			names = append(names, strconv.Itoa(int(s))+": "+s.String())
+		}
+		schema.Description = strings.Join(names, ", ")
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: doc.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: doc.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			doc.fillStructSchema(schema, t)
+			return schema
+		}
+		name := schemaName(t)
+		if registered, exists := doc.types[name]; exists && registered != t {
+			// Types of different packages with the same name are distinguished by their package
+			pkgName := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkgName[:1]) + pkgName[1:] + name
+		}
+		if _, exists := doc.types[name]; !exists {
+			// The schema is registered before visiting the fields to support recursive types
+			schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			doc.types[name] = t
+			doc.Components.Schemas[name] = schema
+			doc.fillStructSchema(schema, t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// Interfaces accept any value
+		return &Schema{}
+	}
+}
+
+// fillStructSchema function completes the schema of a struct with its fields. The name of each
+// property is taken from its json tag and the restrictions from its validate tag. It takes as
+// input the pointer to the schema and the type of the struct.
+func (doc *OpenAPIDocument) fillStructSchema(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Property name
+		jsonTag := strings.Split(field.Tag.Get("json"), ",")
+		name := jsonTag[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		// Property schema (a copy is required to add restrictions to referenced schemas)
+		property := doc.schemaOf(field.Type)
+		if property.Ref != "" {
+			property = &Schema{Ref: property.Ref}
+		}
+
+		// Restrictions of the validate tag (only those that apply to the field itself, not after dive)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			tag, param, _ := strings.Cut(rule, "=")
+			if tag == "dive" {
+				break
+			}
+			switch tag {
+			case "required":
+				schema.Required = append(schema.Required, name)
+			case "isdefault":
+				property.ReadOnly = true
+			case "min", "max":
+				value, err := strconv.Atoi(param)
+				if err != nil {
+					continue
+				}
+				setLimit(property, field.Type.Kind(), tag, value)
+			case "url":
+				property.Format = "uri"
+			case "representsType":
+				property.Enum = []any{"string", "int", "float", "bool"}
+			case "validDependencies":
+				property.Description = "The dependencies of each task must be tasks of the same specification."
+			}
+		}
+
+		schema.Properties[name] = property
+	}
+}
+
+// setLimit function applies a min or max restriction to a schema according to the kind of the field.
+func setLimit(schema *Schema, kind reflect.Kind, tag string, value int) {
+	switch kind {
+	case reflect.Slice, reflect.Array:
+		if tag == "min" {
+			schema.MinItems = &value
+		} else {
+			schema.MaxItems = &value
+		}
+	case reflect.String:
+		if tag == "min" {
+			schema.MinLength = &value
+		} else {
+			schema.MaxLength = &value
+		}
+	}
+}
+
+// schemaName function returns the name under which a struct is registered in the document. The
+// instances of generic types (e.g. Page[...components.Component]) are named after their type
+// argument (e.g. ComponentPage).
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i != -1 {
+		argument := name[i+1 : len(name)-1]
+		argument = argument[strings.LastIndex(argument, ".")+1:]
+		name = argument + name[:i]
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// getOpenAPI function is responsible for resolving requests for the OpenAPI document of the api.
+// It takes as input the request and the ResponseWriter variable.
+func (a *Api) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.OpenAPI)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOpenAPIDocumentMatchesRouter(t *testing.T) {
+
+	// Create an api with a token of each role
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	secrets := make(map[tokens.Role]string)
+	for _, role := range []tokens.Role{tokens.Viewer, tokens.Submitter, tokens.Admin} {
+		token, secret := tokens.NewToken("alice", role)
+		datastore.AddToken(token)
+		secrets[role] = secret
+	}
+	a, _ := NewApi(controllers.NewController(nil, nil, &datastore, validators.NewValidator()))
+	router := a.Router.(*mux.Router)
+	serve := func(method string, target string, secret string) int {
+		request := httptest.NewRequest(method, target, nil)
+		if secret != "" {
+			request.Header.Set("Authorization", "Bearer "+secret)
+		}
+		recorder := httptest.NewRecorder()
+		a.Router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// We read the document served to the clients
+	recorder := httptest.NewRecorder()
+	a.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatal("The document was not served: ", recorder.Code)
+	}
+	var doc OpenAPIDocument
+	if err := json.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every documented operation must be routed at its path and protected by the documented role
+	documented := 0
+	roleRegexp := regexp.MustCompile(`the (\w+) role`)
+	lowerRoles := map[tokens.Role]tokens.Role{tokens.Submitter: tokens.Viewer, tokens.Admin: tokens.Submitter}
+	for path, operations := range doc.Paths {
+		target := pathParameterRegexp.ReplaceAllString(path, "unknown")
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+			operation, exists := operations[strings.ToLower(method)]
+			var match mux.RouteMatch
+			routed := router.Match(httptest.NewRequest(method, target, nil), &match)
+			if !exists {
+				if routed {
+					t.Error("The route " + method + " " + path + " is not documented")
+				}
+				continue
+			}
+			documented++
+			if !routed {
+				t.Error("The operation " + method + " " + path + " is not routed")
+				continue
+			}
+			if template, _ := match.Route.GetPathTemplate(); template != path {
+				t.Error("The operation " + method + " " + path + " is routed to " + template)
+			}
+
+			// The public operations are served without a token
+			if len(operation.Security) == 0 {
+				if status := serve(method, target, ""); status == http.StatusUnauthorized || status == http.StatusForbidden {
+					t.Error("The public operation "+method+" "+path+" answered ", status)
+				}
+				continue
+			}
+
+			// The rest of them require a token with the documented role
+			submatch := roleRegexp.FindStringSubmatch(operation.Description)
+			if submatch == nil {
+				t.Error("The operation " + method + " " + path + " does not document its role")
+				continue
+			}
+			role := tokens.Role(submatch[1])
+			if status := serve(method, target, ""); status != http.StatusUnauthorized {
+				t.Error("The operation "+method+" "+path+" answered ", status, " without a token")
+			}
+			if lower, exists := lowerRoles[role]; exists {
+				if status := serve(method, target, secrets[lower]); status != http.StatusForbidden {
+					t.Error("The operation "+method+" "+path+" answered ", status, " to the "+string(lower)+" role")
+				}
+			}
+			if status := serve(method, target, secrets[role]); status == http.StatusUnauthorized || status == http.StatusForbidden {
+				t.Error("The operation "+method+" "+path+" answered ", status, " to the "+string(role)+" role")
+			}
+		}
+	}
+
+	// And the router must not serve any other operation
+	registered := 0
+	router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, _ := route.GetMethods()
+		registered += len(methods)
+		return nil
+	})
+	if documented != registered {
+		t.Error("The document contains ", documented, " operations but the router has ", registered)
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	a, _ := NewApi(nil)
+	schemas := a.OpenAPI.Components.Schemas
+
+	var tests = []struct {
+		schema   string
+		required []string
+	}{
+		{"Component", []string{"id", "name", "apiVersion", "containerDockerfile", "containerImage"}},
+		{"Put", []string{"name", "type"}},
+		{"Specification", []string{"id", "name", "apiVersion", "spec"}},
+		{"Dag", []string{"tasks"}},
+		{"Definition", []string{"name", "specificationId", "apiVersion"}},
+		{"ResultDefinition", nil},
+		{"ComponentPage", nil},
+		{"ErrorResponse", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			schema, exists := schemas[tt.schema]
+			if !exists {
+				t.Fatal("The schema " + tt.schema + " is missing")
+			}
+			if strings.Join(schema.Required, ",") != strings.Join(tt.required, ",") {
+				t.Error("got ", schema.Required, ", want ", tt.required)
+			}
+		})
+	}
+
+	if tasks := schemas["Dag"].Properties["tasks"]; tasks.MinItems == nil || *tasks.MinItems != 1 {
+		t.Error("The min restriction of the tasks has not been documented")
+	}
+	if id := schemas["Definition"].Properties["id"]; !id.ReadOnly {
+		t.Error("The id of the definitions must be read only")
+	}
+	if operation := a.OpenAPI.Paths["/namespaces/{NS}/definition/execute"]["post"]; operation.Responses["202"].Description == "" {
+		t.Error("The accepted response of the execution has not been documented")
+	}
+}
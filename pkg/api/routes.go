@@ -0,0 +1,101 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/argo"
+	"dag/hector/golang/module/pkg/bundles"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/metrics"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"net/http"
+	"strings"
+)
+
+// route describes an endpoint of the api. Besides registering the handler in the router, it is
+// used to generate the OpenAPI document, so the request and response fields hold a value of the
+// type sent in each body (nil if there is no body), which is required unless stated otherwise. Streamed responses are sent as Server-Sent
+// Events, each of them carrying a value of the response type. The role is the minimum one that
+// the token of the request must have (routes without role are public). Deprecated routes are the
+// aliases of the routes of the elements without namespace (see legacyRoutes).
+type route struct {
+	Path            string
+	Method          string
+	Handler         http.HandlerFunc
+	Summary         string
+	Query           []string
+	Request         any
+	RequestOptional bool
+	Status          int
+	Response        any
+	Stream          bool
+	Role            tokens.Role
+	Deprecated      bool
+}
+
+// namespacePath is the prefix of the routes of the elements, which belong to a namespace.
+const namespacePath = "/namespaces/{NS}"
+
+// listQuery contains the query parameters accepted by the list endpoints.
+var listQuery = []string{"limit", "cursor", "sort", "order", "name", "specificationId", "status"}
+
+// routes function returns the table of endpoints exposed by the api, followed by the deprecated
+// aliases of the routes of the elements.
+func (a *Api) routes() []route {
+	routes := []route{
+		{Path: namespacePath + "/component/submit", Method: http.MethodPost, Handler: a.submitComponent, Summary: "Submit a component", Request: components.Component{}, Status: http.StatusOK, Role: tokens.Submitter},
+		{Path: namespacePath + "/component/get/{ID}", Method: http.MethodGet, Handler: a.getComponent, Summary: "Get a component", Status: http.StatusOK, Response: components.Component{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/component/list", Method: http.MethodGet, Handler: a.listComponents, Summary: "List components", Query: listQuery, Status: http.StatusOK, Response: datastores.Page[components.Component]{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/component/update/{ID}", Method: http.MethodPut, Handler: a.updateComponent, Summary: "Update a component", Request: components.Component{}, Status: http.StatusOK, Role: tokens.Admin},
+		{Path: namespacePath + "/component/delete/{ID}", Method: http.MethodDelete, Handler: a.deleteComponent, Summary: "Delete a component that is not referenced by any specification", Status: http.StatusOK, Role: tokens.Admin},
+		{Path: namespacePath + "/specification/submit", Method: http.MethodPost, Handler: a.submitSpecification, Summary: "Submit a specification", Request: specifications.Specification{}, Status: http.StatusOK, Role: tokens.Submitter},
+		{Path: namespacePath + "/specification/get/{ID}", Method: http.MethodGet, Handler: a.getSpecification, Summary: "Get a specification", Status: http.StatusOK, Response: specifications.Specification{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/specification/list", Method: http.MethodGet, Handler: a.listSpecifications, Summary: "List specifications", Query: listQuery, Status: http.StatusOK, Response: datastores.Page[specifications.Specification]{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/specification/update/{ID}", Method: http.MethodPut, Handler: a.updateSpecification, Summary: "Update a specification and recalculate its planning", Request: specifications.Specification{}, Status: http.StatusOK, Role: tokens.Admin},
+		{Path: namespacePath + "/specification/delete/{ID}", Method: http.MethodDelete, Handler: a.deleteSpecification, Summary: "Delete a specification and its planning", Status: http.StatusOK, Role: tokens.Admin},
+		{Path: namespacePath + "/bundle/submit", Method: http.MethodPost, Handler: a.submitBundle, Summary: "Submit components, a specification and a definition at once (as json or as a tar archive)", Request: bundles.Bundle{}, Status: http.StatusCreated, Response: bundleResponse{}, Role: tokens.Submitter},
+		{Path: namespacePath + "/argo/import", Method: http.MethodPost, Handler: a.importArgoWorkflow, Summary: "Convert an Argo workflow into components, a specification and a definition that can be submitted as a bundle (nothing is stored)", Request: argo.Workflow{}, Status: http.StatusOK, Response: importResponse{}, Role: tokens.Submitter},
+		{Path: namespacePath + "/argo/export/{ID}", Method: http.MethodGet, Handler: a.exportArgoWorkflow, Summary: "Render a stored definition, its specification and components as an Argo workflow", Status: http.StatusOK, Response: argo.Workflow{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/nomad/export/{ID}", Method: http.MethodGet, Handler: a.exportNomadJob, Summary: "Render a stored definition as a single nomad job specification (format hcl or json)", Query: []string{"format"}, Status: http.StatusOK, Role: tokens.Viewer},
+		{Path: namespacePath + "/topologicalSort/get/{ID}", Method: http.MethodGet, Handler: a.getTopologicalSort, Summary: "Get the planning of a specification", Status: http.StatusOK, Response: [][]string{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/definition/execute", Method: http.MethodPost, Handler: a.executeDefinition, Summary: "Queue a definition for its execution", Request: definitions.Definition{}, Status: http.StatusAccepted, Response: executionResponse{}, Role: tokens.Submitter},
+		{Path: namespacePath + "/definition/validate", Method: http.MethodPost, Handler: a.validateDefinition, Summary: "Validate a definition and plan the jobs that its execution would launch (nothing is stored nor executed)", Request: definitions.Definition{}, Status: http.StatusOK, Response: validationResponse{}, Role: tokens.Submitter},
+		{Path: namespacePath + "/definition/cancel/{ID}", Method: http.MethodPost, Handler: a.cancelDefinition, Summary: "Cancel the execution of a definition", Request: cancelRequest{}, RequestOptional: true, Status: http.StatusOK, Response: results.ResultDefinition{}, Role: tokens.Submitter},
+		{Path: namespacePath + "/definition/retry/{ID}", Method: http.MethodPost, Handler: a.retryDefinition, Summary: "Execute again the failed and cancelled jobs of a definition", Request: retryRequest{}, RequestOptional: true, Status: http.StatusAccepted, Response: results.ResultDefinition{}, Role: tokens.Submitter},
+		{Path: namespacePath + "/definition/get/{ID}", Method: http.MethodGet, Handler: a.getDefinition, Summary: "Get a definition", Status: http.StatusOK, Response: definitions.Definition{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/definition/list", Method: http.MethodGet, Handler: a.listDefinitions, Summary: "List definitions", Query: listQuery, Status: http.StatusOK, Response: datastores.Page[definitions.Definition]{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/result/get/{ID}", Method: http.MethodGet, Handler: a.getResultDefinition, Summary: "Get the result of a definition", Status: http.StatusOK, Response: results.ResultDefinition{}, Role: tokens.Viewer},
+		{Path: namespacePath + "/result/watch/{ID}", Method: http.MethodGet, Handler: a.watchResultDefinition, Summary: "Follow the job transitions of a definition as Server-Sent Events", Status: http.StatusOK, Response: events.Event{}, Stream: true, Role: tokens.Viewer},
+		{Path: namespacePath + "/result/list", Method: http.MethodGet, Handler: a.listResultDefinitions, Summary: "List results", Query: listQuery, Status: http.StatusOK, Response: datastores.Page[results.ResultDefinition]{}, Role: tokens.Viewer},
+		{Path: "/token/create", Method: http.MethodPost, Handler: a.createToken, Summary: "Create a token and reveal its secret", Request: tokens.Token{}, Status: http.StatusCreated, Response: tokenResponse{}, Role: tokens.Admin},
+		{Path: "/token/list", Method: http.MethodGet, Handler: a.listTokens, Summary: "List tokens", Query: listQuery, Status: http.StatusOK, Response: datastores.Page[tokens.Token]{}, Role: tokens.Admin},
+		{Path: "/token/delete/{ID}", Method: http.MethodDelete, Handler: a.deleteToken, Summary: "Revoke a token", Status: http.StatusOK, Role: tokens.Admin},
+		{Path: "/openapi.json", Method: http.MethodGet, Handler: a.getOpenAPI, Summary: "Get the OpenAPI document of the api", Status: http.StatusOK},
+		{Path: "/healthz", Method: http.MethodGet, Handler: a.getHealth, Summary: "Check that the api is serving", Status: http.StatusOK, Response: healthResponse{}},
+		{Path: "/readyz", Method: http.MethodGet, Handler: a.getReadiness, Summary: "Check that the datastore and the executor can be reached (503 if any of them is unavailable)", Status: http.StatusOK, Response: healthResponse{}},
+		{Path: "/metrics", Method: http.MethodGet, Handler: metrics.Handler().ServeHTTP, Summary: "Get the metrics of the server in the Prometheus text format", Status: http.StatusOK},
+	}
+	return append(routes, legacyRoutes(routes)...)
+}
+
+// legacyRoutes function returns the deprecated aliases of the routes of the elements, which keep the
+// paths without namespace (e.g. /definition/execute) that were served before the namespaces existed.
+// They act on the default namespace and will be removed in a future version. It takes as input the
+// routes of the api. Returns the aliases.
+func legacyRoutes(routes []route) []route {
+	var aliases []route
+	for _, rt := range routes {
+		if !strings.HasPrefix(rt.Path, namespacePath+"/") {
+			continue
+		}
+		alias := rt
+		alias.Path = strings.TrimPrefix(rt.Path, namespacePath)
+		alias.Summary = rt.Summary + " in the default namespace (deprecated alias of " + rt.Path + ")"
+		alias.Deprecated = true
+		aliases = append(aliases, alias)
+	}
+	return aliases
+}
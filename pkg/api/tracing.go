@@ -0,0 +1,43 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/tracing"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// traceRequests function is the middleware that records a span for every request. The span is the
+// child of the one sent by the client in the traceparent header (if any) and is carried by the
+// context of the request, so that the definitions submitted by it are traced within the same trace.
+func (a *Api) traceRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.Tracer == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// We name the span after the route, so that the spans of the same operation can be grouped
+		name := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				name = template
+			}
+		}
+		ctx := tracing.WithRemoteParent(r.Context(), r.Header.Get(tracing.TraceparentHeader))
+		ctx, span := a.Tracer.Start(ctx, r.Method+" "+name)
+		span.SetAttribute("method", r.Method)
+		span.SetAttribute("path", r.URL.Path)
+
+		// The status is recorded by the recorder of the logging middleware when it wraps the writer
+		recorder, ok := w.(*responseRecorder)
+		if !ok {
+			recorder = &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttribute("status", strconv.Itoa(recorder.status))
+		span.End(recorder.err)
+	})
+}
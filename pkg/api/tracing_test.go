@@ -0,0 +1,60 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/tracing"
+	"dag/hector/golang/module/pkg/validators"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// spansExporter keeps the exported spans in memory.
+type spansExporter []*tracing.Span
+
+func (se *spansExporter) Export(span *tracing.Span) error {
+	*se = append(*se, span)
+	return nil
+}
+
+func TestTraceRequests(t *testing.T) {
+	var tests = []struct {
+		traceparent string
+		traceId     string
+		parentId    string
+	}{
+		{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331"},
+		{"not-a-traceparent", "", ""},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			var datastore datastores.Datastore = dbmock.NewDBMock()
+			controller := controllers.NewController(nil, nil, &datastore, validators.NewValidator())
+			exporter := &spansExporter{}
+			controller.Tracer = tracing.NewTracer(exporter)
+			a, _ := NewApi(controller)
+
+			request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			request.Header.Set(tracing.TraceparentHeader, tt.traceparent)
+			a.Router.ServeHTTP(httptest.NewRecorder(), request)
+
+			// We check that the span of the request continues the trace of the client (if valid)
+			if len(*exporter) != 1 {
+				t.Fatal("got ", len(*exporter), " spans, want 1")
+			}
+			span := (*exporter)[0]
+			if span.Name != "GET /readyz" || span.Attributes["status"] != "200" {
+				t.Error("got the span ", span.Name, span.Attributes)
+			}
+			if tt.traceId != "" && span.TraceId != tt.traceId || span.ParentId != tt.parentId {
+				t.Error("got the span ", span.TraceId, span.ParentId)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/executors"
+	"dag/hector/golang/module/pkg/formats"
+	"io/ioutil"
+	"net/http"
+)
+
+// plannedJob is each one of the jobs that the execution of a definition would launch, with the
+// image of its component and the arguments passed to its container.
+type plannedJob struct {
+	Name         string   `json:"name"`
+	Component    string   `json:"component"`
+	Image        string   `json:"image"`
+	Arguments    []string `json:"arguments"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// validationResponse is the body returned to the client when a definition is valid. The jobs are
+// grouped in the order in which they would be executed (the jobs of a group run simultaneously).
+type validationResponse struct {
+	Jobs [][]plannedJob `json:"jobs"`
+}
+
+// validateDefinition function extracts the Definition element from the request body, validates it
+// against its specification and components and records the jobs that its execution would launch in
+// the variable type ResponseWriter. Nothing is stored nor executed. If the definition is not valid,
+// all its problems are reported (with their line in YAML documents). It takes as input the request
+// and the variable type ResponseWriter.
+func (a *Api) validateDefinition(w http.ResponseWriter, r *http.Request) {
+
+	// Read definition from body (its scheme is validated by the controller together with the rest)
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &errors.InvalidRequestErr{Message: "invalid request: " + err.Error()})
+		return
+	}
+	format := formats.FromMediaType(r.Header.Get("Content-Type"))
+	definition := definitions.Definition{}
+	if err := decodeBody(content, format, &definition); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := setNamespace(r, &definition.Namespace); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Validate the definition and plan its jobs, locating the problems in YAML documents
+	nestedJobs, err := a.Controller.DryRun(&definition)
+	if err != nil {
+		locateViolations(err, content, format)
+		writeError(w, err)
+		return
+	}
+
+	// We render the jobs as the executors would launch them
+	response := validationResponse{Jobs: [][]plannedJob{}}
+	for _, jobGroup := range *nestedJobs {
+		group := []plannedJob{}
+		for _, job := range jobGroup {
+			planned := plannedJob{Name: job.Name, Component: job.Component, Image: job.Image, Arguments: executors.ArgumentsToSlice(&job.Arguments), Dependencies: job.Dependencies}
+			if planned.Arguments == nil {
+				planned.Arguments = []string{}
+			}
+			if planned.Dependencies == nil {
+				planned.Dependencies = []string{}
+			}
+			group = append(group, planned)
+		}
+		response.Jobs = append(response.Jobs, group)
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/schedulers"
+	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidateDefinition(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	a, _ := NewApi(controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator()))
+
+	// We store the components and the specification through a bundle (its definition is queued)
+	submitTestBundle(t, a, secret)
+
+	var tests = []struct {
+		contentType string
+		body        string
+		status      int
+		want        string
+	}{
+		{
+			"application/json",
+			`{"name": "Def", "specificationId": "Spec-ID", "apiVersion": "hector/v1", "data": {"tasks": [{"name": "Task A", "inputs": [{"name": "input_1", "value": "a"}]}, {"name": "Task B", "inputs": [{"name": "input_1", "value": "b"}]}]}}`,
+			http.StatusOK,
+			`{"jobs":[[{"name":"Task A","component":"Comp-ID","image":"image/name","arguments":["--input_1","a"],"dependencies":[]}],[{"name":"Task B","component":"Comp-ID","image":"image/name","arguments":["--input_1","b"],"dependencies":["Task A"]}]]}`,
+		},
+		{
+			"application/json",
+			`{"name": "Def", "specificationId": "Spec-ID", "apiVersion": "hector/v1", "data": {"tasks": [{"name": "Task A", "inputs": [{"name": "input_1", "value": 1}]}, {"name": "Task B"}]}}`,
+			http.StatusUnprocessableEntity,
+			`"problems":[{"field":"data.tasks[0].inputs[0].value","pointer":"/data/tasks/0/inputs/0/value","code":"invalid_type","message":"parameter input_1 has an invalid value in the definition file"},{"field":"data.tasks[1].inputs","pointer":"/data/tasks/1/inputs","code":"missing_parameter","message":"parameter input_1 is required but is not present in the definition file"}]`,
+		},
+		{
+			"application/yaml",
+			"name: Def\napiVersion: hector/v1\nspecificationId: Missing-ID\ndata:\n  tasks: []\n",
+			http.StatusUnprocessableEntity,
+			`"problems":[{"field":"specificationId","pointer":"/specificationId","code":"not_found","line":3,`,
+		},
+		{"application/json", `{"name": `, http.StatusBadRequest, `"code":"invalid_request"`},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/namespaces/team-a/definition/validate", strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Bearer "+secret)
+			request.Header.Set("Content-Type", tt.contentType)
+			a.Router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Error("got ", recorder.Code, ", want ", tt.status, ": ", recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.want) {
+				t.Error("got ", recorder.Body.String(), ", want ", tt.want)
+			}
+		})
+	}
+
+	// Only the definition of the bundle has been queued
+	if queued, _ := datastore.CountQueuedDefinitions(); queued != 1 {
+		t.Error("The validated definitions must not be queued but there are " + strconv.Itoa(queued))
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/events"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// keepAliveInterval is the time after which an idle stream receives a comment, so that proxies
+// do not close the connection while the jobs are running.
+const keepAliveInterval = 15 * time.Second
+
+// watchResultDefinition function is responsible for resolving requests that follow the execution
+// of a definition in real time through Server-Sent Events. First, it sends a "job" event with the
+// current state of each job and then one for every transition published by the controller. When
+// the execution ends, it sends an "end" event with the final ResultDefinition and closes the stream.
+// If the stream stops receiving the transitions before (the client fell behind or the invocation was
+// interrupted), the current state of the jobs is sent again and the stream goes on following them.
+// It takes as input the request and the ResponseWriter variable.
+func (a *Api) watchResultDefinition(w http.ResponseWriter, r *http.Request) {
+
+	// We collect the namespace and the ID of the url
+	vars := mux.Vars(r)
+	namespace, id := vars["NS"], vars["ID"]
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("the response writer does not support streaming"))
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for started := false; ; started = true {
+
+		// We subscribe before reading the current state so that no transition is lost
+		subscription, unsubscribe := a.Controller.Broker.Subscribe(id)
+		resultDefinition, err := (*a.Controller.Datastore).GetResultDefinition(namespace, id)
+		if err != nil {
+			unsubscribe()
+			if !started {
+				writeError(w, err)
+			}
+			return
+		}
+
+		// We send the current state of the jobs
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+		}
+		for _, resultJob := range resultDefinition.ResultJobs {
+			writeEvent(w, "job", events.Event{Namespace: namespace, DefinitionId: id, ResultJob: resultJob})
+		}
+		flusher.Flush()
+
+		// If the execution has already ended, there is nothing to follow
+		if !resultDefinition.Status().Pending() {
+			unsubscribe()
+			writeEvent(w, "end", resultDefinition)
+			flusher.Flush()
+			return
+		}
+
+		// We forward the transitions until the subscription is closed or the client leaves
+		if !followEvents(w, r, flusher, subscription, keepAlive, a.closing) {
+			unsubscribe()
+			return
+		}
+		unsubscribe()
+	}
+}
+
+// followEvents function forwards the events of a subscription to the stream, keeping it alive while
+// the jobs are running. It takes as input the ResponseWriter variable, the request, the flusher of the
+// stream, the subscription, the keep-alive ticker and the channel closed when the server shuts down.
+// Returns false if the client has left or the server is shutting down, or true when the subscription is
+// closed (which does not mean that the execution has ended, since the broker also disconnects the
+// subscribers that fall behind).
+func followEvents(w http.ResponseWriter, r *http.Request, flusher http.Flusher, subscription <-chan events.Event, keepAlive *time.Ticker, closing <-chan struct{}) bool {
+	for {
+		select {
+		case <-r.Context().Done():
+			return false
+		case <-closing:
+			return false
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event, open := <-subscription:
+			if !open {
+				return true
+			}
+			writeEvent(w, "job", event)
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent function writes a Server-Sent Event whose data is the JSON encoding of a value. It
+// takes as input the ResponseWriter variable, the name of the event and the value.
+func writeEvent(w http.ResponseWriter, name string, value any) {
+	data, _ := json.Marshal(value)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/tokens"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchResultDefinition(t *testing.T) {
+
+	// Create a controller whose datastore contains a result definition in progress
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddResultDefinition(&results.ResultDefinition{
+		Id:         "RD-ID",
+		Namespace:  "default",
+		ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: results.Waiting}},
+	})
+	token, secret := tokens.NewToken("alice", tokens.Viewer)
+	datastore.AddToken(token)
+	controller := controllers.NewController(nil, nil, &datastore, nil)
+	a, _ := NewApi(controller)
+
+	server := httptest.NewServer(a.Router)
+	defer server.Close()
+
+	// The controller publishes the transitions once the client is subscribed
+	go func() {
+		for controller.Broker.Subscribers("RD-ID") == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		jobRes := results.ResultJob{Id: "J1", Name: "A", Status: results.Done}
+		datastore.UpdateResultJob(&jobRes, "default", "RD-ID")
+		controller.Broker.Publish(events.Event{Namespace: "default", DefinitionId: "RD-ID", ResultJob: results.ResultJob{Id: "J1", Name: "A", Status: results.Running}})
+		controller.Broker.Publish(events.Event{Namespace: "default", DefinitionId: "RD-ID", ResultJob: jobRes})
+		controller.Broker.Close("RD-ID")
+	}()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/namespaces/default/result/watch/RD-ID", nil)
+	request.Header.Set("Authorization", "Bearer "+secret)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Error("Unexpected content type " + contentType)
+	}
+
+	// The stream contains the initial state, both transitions and the final result (depending on
+	// when the initial state is read, it may already contain the transitions)
+	var tests = []string{
+		`event: job` + "\n" + `data: {"namespace":"default","definitionId":"RD-ID","resultJob":{"Id":"J1","Name":"A","Logs":"",`,
+		`event: job` + "\n" + `data: {"namespace":"default","definitionId":"RD-ID","resultJob":{"Id":"J1","Name":"A","Logs":"","Status":4}}`,
+		`event: job` + "\n" + `data: {"namespace":"default","definitionId":"RD-ID","resultJob":{"Id":"J1","Name":"A","Logs":"","Status":1}}`,
+		`event: end`,
+	}
+	stream := string(body)
+	for _, tt := range tests {
+		idx := strings.Index(stream, tt)
+		if idx == -1 {
+			t.Fatal("The stream does not contain the event " + tt + " in the expected position:\n" + string(body))
+		}
+		stream = stream[idx+len(tt):]
+	}
+}
+
+func TestWatchResultDefinitionResync(t *testing.T) {
+
+	// Create a controller whose datastore contains a result definition in progress
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddResultDefinition(&results.ResultDefinition{
+		Id:         "RD-ID",
+		Namespace:  "default",
+		ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: results.Waiting}},
+	})
+	token, secret := tokens.NewToken("alice", tokens.Viewer)
+	datastore.AddToken(token)
+	controller := controllers.NewController(nil, nil, &datastore, nil)
+	a, _ := NewApi(controller)
+
+	server := httptest.NewServer(a.Router)
+	defer server.Close()
+
+	// The subscription is closed while the job is still pending (as the broker does with the subscribers
+	// that fall behind), and only the second closure comes after the end of the execution
+	go func() {
+		for controller.Broker.Subscribers("RD-ID") == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		controller.Broker.Close("RD-ID")
+		for controller.Broker.Subscribers("RD-ID") == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		jobRes := results.ResultJob{Id: "J1", Name: "A", Status: results.Done}
+		datastore.UpdateResultJob(&jobRes, "default", "RD-ID")
+		controller.Broker.Publish(events.Event{Namespace: "default", DefinitionId: "RD-ID", ResultJob: jobRes})
+		controller.Broker.Close("RD-ID")
+	}()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/namespaces/default/result/watch/RD-ID", nil)
+	request.Header.Set("Authorization", "Bearer "+secret)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	// The stream sends the state again after the first closure and ends only once
+	waiting := `event: job` + "\n" + `data: {"namespace":"default","definitionId":"RD-ID","resultJob":{"Id":"J1","Name":"A","Logs":"","Status":0}}`
+	if count := strings.Count(string(body), waiting); count != 2 {
+		t.Error("The waiting state should be sent twice, but it was sent ", count, " times:\n"+string(body))
+	}
+	if count := strings.Count(string(body), "event: end"); count != 1 || !strings.Contains(string(body)[strings.Index(string(body), "event: end"):], `"Status":1`) {
+		t.Error("The stream should end once with the finished definition:\n" + string(body))
+	}
+}
+
+func TestWatchResultDefinitionCloseStreams(t *testing.T) {
+
+	// Create a controller whose datastore contains a result definition in progress
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddResultDefinition(&results.ResultDefinition{
+		Id:         "RD-ID",
+		Namespace:  "default",
+		ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: results.Running}},
+	})
+	token, secret := tokens.NewToken("alice", tokens.Viewer)
+	datastore.AddToken(token)
+	controller := controllers.NewController(nil, nil, &datastore, nil)
+	a, _ := NewApi(controller)
+
+	server := httptest.NewServer(a.Router)
+	defer server.Close()
+
+	// The server shuts down while the job is still running
+	go func() {
+		for controller.Broker.Subscribers("RD-ID") == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		a.CloseStreams()
+	}()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/namespaces/default/result/watch/RD-ID", nil)
+	request.Header.Set("Authorization", "Bearer "+secret)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	// The stream is closed without reporting the end of the execution
+	if strings.Contains(string(body), "event: end") {
+		t.Error("The stream must not report the end of a running execution:\n" + string(body))
+	}
+}
+
+func TestWatchUnknownResultDefinition(t *testing.T) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Viewer)
+	datastore.AddToken(token)
+	a, _ := NewApi(controllers.NewController(nil, nil, &datastore, nil))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/namespaces/default/result/watch/unknown", nil)
+	request.Header.Set("Authorization", "Bearer "+secret)
+	a.Router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotFound {
+		t.Error("got ", recorder.Code, ", want ", http.StatusNotFound)
+	}
+}
@@ -0,0 +1,121 @@
+package argo
+
+import (
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/executors"
+	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/specifications"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// EntrypointTemplate is the name of the DAG template of the exported workflows.
+const EntrypointTemplate = "main"
+
+// invalidNameRegexp matches the characters that Argo does not accept in the names of the templates
+// and tasks.
+var invalidNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// namer assigns unique Argo names to the Hector elements.
+type namer map[string]bool
+
+// name function converts a Hector name into an Argo one (lowercase alphanumeric characters and
+// dashes), adding a numeric suffix if it is already taken. It takes as input the Hector name and
+// returns the Argo name.
+func (n namer) name(hectorName string) string {
+	base := strings.Trim(invalidNameRegexp.ReplaceAllString(strings.ToLower(hectorName), "-"), "-")
+	if base == "" {
+		base = "task"
+	}
+	name := base
+	for i := 2; n[name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	n[name] = true
+	return name
+}
+
+// Export function renders a definition as an Argo Workflow that can be run outside of Hector. Each
+// component becomes a container template whose arguments are rendered as the executors do
+// (--name value), taking the values from the input parameters of the template, and each task
+// becomes a task of the DAG of the entrypoint with the same dependencies. The components used with
+// different parameters get a template for each set of parameters. It takes as input the pointer to
+// the Definition, the pointer to its Specification and the pointer to the jobs of the definition in
+// topological order. Returns the pointer to the Workflow.
+func Export(definition *definitions.Definition, specification *specifications.Specification, nestedJobs *[][]jobs.Job) *Workflow {
+	names := namer{EntrypointTemplate: true}
+	workflow := Workflow{
+		ApiVersion: WorkflowApiVersion,
+		Kind:       WorkflowKind,
+		Metadata:   Metadata{GenerateName: namer{}.name(definition.Name) + "-"},
+		Spec:       WorkflowSpec{Entrypoint: EntrypointTemplate, Templates: []Template{}},
+	}
+
+	// We name the tasks in advance, since the dependencies refer to them
+	taskNames := map[string]string{}
+	for _, jobsGroup := range *nestedJobs {
+		for _, job := range jobsGroup {
+			taskNames[job.Name] = names.name(job.Name)
+		}
+	}
+
+	dag := DAGTemplate{Tasks: []DAGTask{}}
+	templates := map[string]string{}
+	for _, jobsGroup := range *nestedJobs {
+		for _, job := range jobsGroup {
+
+			// We reuse the template of the component if it has already been used with the same parameters
+			idx := slices.IndexFunc(specification.Spec.Dag.Tasks, func(t specifications.SpecificationTask) bool { return t.Name == job.Name })
+			component := specification.Spec.Dag.Tasks[idx].Component
+			var parameterNames []string
+			for _, argument := range job.Arguments {
+				parameterNames = append(parameterNames, argument.Name)
+			}
+			key := component + "\n" + job.Image + "\n" + strings.Join(parameterNames, "\n")
+			templateName, found := templates[key]
+			if !found {
+				templateName = names.name(component)
+				templates[key] = templateName
+				workflow.Spec.Templates = append(workflow.Spec.Templates, containerTemplate(templateName, job.Image, parameterNames))
+			}
+
+			// We add the task with the values of its parameters
+			task := DAGTask{Name: taskNames[job.Name], Template: templateName}
+			for _, dependency := range job.Dependencies {
+				task.Dependencies = append(task.Dependencies, taskNames[dependency])
+			}
+			for _, argument := range job.Arguments {
+				value := AnyString(fmt.Sprintf("%v", argument.Value))
+				if task.Arguments == nil {
+					task.Arguments = &Arguments{}
+				}
+				task.Arguments.Parameters = append(task.Arguments.Parameters, Parameter{Name: argument.Name, Value: &value})
+			}
+			dag.Tasks = append(dag.Tasks, task)
+		}
+	}
+
+	workflow.Spec.Templates = append(workflow.Spec.Templates, Template{Name: EntrypointTemplate, DAG: &dag})
+	return &workflow
+}
+
+// containerTemplate function builds the template of a component, which runs its image with the
+// arguments rendered by the executors. It takes as input the name of the template, the image and
+// the names of the parameters. Returns the template.
+func containerTemplate(name string, image string, parameterNames []string) Template {
+	template := Template{Name: name, Container: &Container{Image: image}}
+	var arguments []definitions.Parameter
+	for _, parameterName := range parameterNames {
+		if template.Inputs == nil {
+			template.Inputs = &Inputs{}
+		}
+		template.Inputs.Parameters = append(template.Inputs.Parameters, Parameter{Name: parameterName})
+		arguments = append(arguments, definitions.Parameter{Name: parameterName, Value: "{{inputs.parameters." + parameterName + "}}"})
+	}
+	template.Container.Args = executors.ArgumentsToSlice(&arguments)
+	return template
+}
@@ -0,0 +1,61 @@
+package argo
+
+import (
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/specifications"
+	"fmt"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	definition := definitions.Definition{Name: "Toy Definition 1", SpecificationId: "Spec-ID"}
+	specification := specifications.Specification{Id: "Spec-ID", Spec: specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{
+		{Name: "Concat Messages 1", Component: "ConcatMessages"},
+		{Name: "Concat Messages 2", Component: "ConcatMessages"},
+		{Name: "Count Letters", Component: "shared/CountLetters", Dependencies: []string{"Concat Messages 1", "Concat Messages 2"}},
+	}}}}
+	nestedJobs := [][]jobs.Job{
+		{
+			{Name: "Concat Messages 1", Image: "concat", Arguments: []definitions.Parameter{{Name: "message-1", Value: "Hello"}, {Name: "output-file", Value: "out_1.txt"}}},
+			{Name: "Concat Messages 2", Image: "concat", Arguments: []definitions.Parameter{{Name: "message-1", Value: "Bye"}, {Name: "output-file", Value: "out_2.txt"}}},
+		},
+		{
+			{Name: "Count Letters", Image: "count", Arguments: []definitions.Parameter{{Name: "times", Value: 2.0}}, Dependencies: []string{"Concat Messages 1", "Concat Messages 2"}},
+		},
+	}
+
+	workflow := Export(&definition, &specification, &nestedJobs)
+
+	// The components are templates that render their arguments as the executors do
+	if workflow.Metadata.GenerateName != "toy-definition-1-" || workflow.Spec.Entrypoint != EntrypointTemplate {
+		t.Error("got metadata ", workflow.Metadata, " and entrypoint ", workflow.Spec.Entrypoint)
+	}
+	if len(workflow.Spec.Templates) != 3 {
+		t.Fatal("got ", len(workflow.Spec.Templates), " templates, want 3")
+	}
+	if template := workflow.Spec.Templates[0]; template.Name != "concatmessages" || fmt.Sprint(template.Container.Args) != "[--message-1 {{inputs.parameters.message-1}} --output-file {{inputs.parameters.output-file}}]" {
+		t.Error("got template ", template.Name, " with arguments ", template.Container.Args)
+	}
+	if template := workflow.Spec.Templates[1]; template.Name != "shared-countletters" || template.Container.Image != "count" {
+		t.Error("got template ", template.Name, " with image ", template.Container.Image)
+	}
+
+	// The tasks keep their dependencies and the values of their parameters
+	tasks := workflow.Spec.Templates[2].DAG.Tasks
+	if tasks[2].Name != "count-letters" || fmt.Sprint(tasks[2].Dependencies) != "[concat-messages-1 concat-messages-2]" {
+		t.Error("got task ", tasks[2])
+	}
+	if tasks[1].Template != "concatmessages" || string(*tasks[1].Arguments.Parameters[0].Value) != "Bye" || string(*tasks[2].Arguments.Parameters[0].Value) != "2" {
+		t.Error("got tasks ", tasks)
+	}
+
+	// The exported workflow can be imported again
+	bundle, _, err := Import(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Components) != 2 || len(bundle.Definition.Data.Tasks) != 3 || bundle.Definition.Data.Tasks[2].Inputs[0].Value != "2" {
+		t.Error("got bundle ", bundle)
+	}
+}
@@ -0,0 +1,292 @@
+package argo
+
+import (
+	"dag/hector/golang/module/pkg/bundles"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/specifications"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ApiVersion is the version of the Hector elements produced by the conversions.
+const ApiVersion = "hector/v1"
+
+// expressionRegexp finds the Argo expressions ({{...}}) inside the values of a manifest.
+var expressionRegexp = regexp.MustCompile(`{{\s*([^{}]*?)\s*}}`)
+
+// importer gathers the problems and warnings found while converting a workflow.
+type importer struct {
+	workflow   *Workflow
+	name       string
+	parameters map[string]*AnyString
+	templates  map[string]int
+	used       map[string]bool
+	problems   []errors.ValidationErr
+	warnings   []string
+}
+
+// Import function converts an Argo Workflow into a bundle of Hector elements: the container
+// templates used by the entrypoint DAG become components, the DAG tasks become a specification and
+// the arguments of the tasks (resolved with the parameters of the workflow) become a definition. The
+// identifiers of the components and the specification are prefixed by the name of the workflow. It
+// takes as input the pointer to the Workflow. Returns the pointer to the Bundle, the warnings about
+// the parts of the workflow that have been ignored and a ConversionErr listing every part of the
+// workflow that cannot be converted.
+func Import(workflow *Workflow) (*bundles.Bundle, []string, error) {
+	imp := importer{workflow: workflow, parameters: map[string]*AnyString{}, templates: map[string]int{}, used: map[string]bool{}}
+
+	// We check the kind of the manifest and obtain the name of the workflow
+	if workflow.Kind != WorkflowKind {
+		imp.problem("kind", "the kind %q is not supported, only %s manifests can be imported", workflow.Kind, WorkflowKind)
+	}
+	if !strings.HasPrefix(workflow.ApiVersion, "argoproj.io/") {
+		imp.problem("apiVersion", "the api version %q is not an Argo one", workflow.ApiVersion)
+	}
+	imp.name = workflow.Metadata.Name
+	if imp.name == "" {
+		imp.name = strings.TrimRight(workflow.Metadata.GenerateName, "-")
+	}
+	if imp.name == "" {
+		imp.problem("metadata.name", "the workflow has neither a name nor a generateName")
+	}
+
+	// We index the parameters of the workflow and its templates
+	for i, parameter := range workflow.Spec.arguments().Parameters {
+		imp.parameters[parameter.Name] = parameter.Value
+		if parameter.Value == nil {
+			imp.parameters[parameter.Name] = parameter.Default
+		}
+		if parameter.ValueFrom != nil {
+			imp.problem(fmt.Sprintf("spec.arguments.parameters[%d].valueFrom", i), "the parameters of the workflow must be given by value")
+		}
+	}
+	if len(workflow.Spec.arguments().Artifacts) > 0 {
+		imp.problem("spec.arguments.artifacts", "artifacts are not supported")
+	}
+	for i, template := range workflow.Spec.Templates {
+		imp.templates[template.Name] = i
+	}
+
+	// The entrypoint must be a DAG, whose tasks are converted
+	entrypoint, found := imp.templates[workflow.Spec.Entrypoint]
+	if !found {
+		imp.problem("spec.entrypoint", "the entrypoint %q is not a template of the workflow", workflow.Spec.Entrypoint)
+		return nil, imp.warnings, imp.err()
+	}
+	if workflow.Spec.Templates[entrypoint].DAG == nil {
+		imp.problem(fmt.Sprintf("spec.templates[%d]", entrypoint), "the entrypoint must be a dag template (steps and single templates are not supported)")
+		return nil, imp.warnings, imp.err()
+	}
+	bundle := imp.convertDAG(entrypoint)
+
+	// The templates that are not used by the DAG are not imported
+	for _, template := range workflow.Spec.Templates {
+		if template.Name != workflow.Spec.Entrypoint && !imp.used[template.Name] {
+			imp.warnings = append(imp.warnings, fmt.Sprintf("the template %s is not used by the entrypoint and has not been imported", template.Name))
+		}
+	}
+
+	if err := imp.err(); err != nil {
+		return nil, imp.warnings, err
+	}
+	return bundle, imp.warnings, nil
+}
+
+// convertDAG function converts the tasks of a DAG template into the specification and the
+// definition of the bundle, adding the components of the templates they use. It takes as input the
+// index of the DAG template. Returns the bundle built.
+func (imp *importer) convertDAG(index int) *bundles.Bundle {
+	dagField := fmt.Sprintf("spec.templates[%d].dag", index)
+	bundle := &bundles.Bundle{Components: []components.Component{}}
+	specification := specifications.Specification{Id: imp.name, Name: imp.name, ApiVersion: ApiVersion}
+	definition := definitions.Definition{Name: imp.name, SpecificationId: imp.name, ApiVersion: ApiVersion}
+
+	for i, task := range imp.workflow.Spec.Templates[index].DAG.Tasks {
+		field := fmt.Sprintf("%s.tasks[%d]", dagField, i)
+
+		// We reject the features of the tasks that Hector cannot reproduce
+		switch {
+		case task.TemplateRef != nil:
+			imp.problem(field+".templateRef", "templates of other workflow templates are not supported")
+			continue
+		case task.When != "":
+			imp.problem(field+".when", "conditional tasks are not supported")
+		case task.Depends != "":
+			imp.problem(field+".depends", "depends expressions are not supported, use dependencies instead")
+		case task.WithItems != nil || task.WithParam != "" || task.WithSequence != nil:
+			imp.problem(field, "loops (withItems, withParam and withSequence) are not supported")
+		case len(task.arguments().Artifacts) > 0:
+			imp.problem(field+".arguments.artifacts", "artifacts are not supported")
+		}
+
+		// We convert the template into a component the first time it is used
+		templateIndex, found := imp.templates[task.Template]
+		if !found {
+			imp.problem(field+".template", "the template %q is not a template of the workflow", task.Template)
+			continue
+		}
+		template := imp.workflow.Spec.Templates[templateIndex]
+		if !imp.used[template.Name] {
+			imp.used[template.Name] = true
+			if component, ok := imp.convertTemplate(templateIndex); ok {
+				bundle.Components = append(bundle.Components, component)
+			}
+		}
+
+		// We add the task to the specification and its arguments to the definition
+		specification.Spec.Dag.Tasks = append(specification.Spec.Dag.Tasks, specifications.SpecificationTask{
+			Name:         task.Name,
+			Dependencies: task.Dependencies,
+			Component:    imp.componentId(template.Name),
+		})
+		definition.Data.Tasks = append(definition.Data.Tasks, imp.convertArguments(field, task, template))
+	}
+
+	bundle.Specification = &specification
+	bundle.Definition = &definition
+	return bundle
+}
+
+// convertTemplate function converts a container template into a component. The parameters of the
+// template become the inputs and outputs of the component, all of them strings. It takes as input
+// the index of the template. Returns the component and whether the template could be converted.
+func (imp *importer) convertTemplate(index int) (components.Component, bool) {
+	template := imp.workflow.Spec.Templates[index]
+	field := fmt.Sprintf("spec.templates[%d]", index)
+
+	switch {
+	case template.DAG != nil:
+		imp.problem(field+".dag", "nested dag templates are not supported")
+		return components.Component{}, false
+	case template.Steps != nil:
+		imp.problem(field+".steps", "steps templates are not supported")
+		return components.Component{}, false
+	case template.Script != nil, template.Resource != nil, template.Suspend != nil, template.Container == nil:
+		imp.problem(field, "only container templates can be converted into components")
+		return components.Component{}, false
+	}
+	if len(template.inputs().Artifacts) > 0 {
+		imp.problem(field+".inputs.artifacts", "artifacts are not supported")
+	}
+	if len(template.outputs().Artifacts) > 0 {
+		imp.problem(field+".outputs.artifacts", "artifacts are not supported")
+	}
+
+	// Argo images are not built from a Dockerfile, so the image is recorded instead
+	component := components.Component{
+		Id:                  imp.componentId(template.Name),
+		Name:                template.Name,
+		ApiVersion:          ApiVersion,
+		Inputs:              []components.Put{},
+		Outputs:             []components.Put{},
+		ContainerDockerfile: template.Container.Image,
+		ContainerImage:      template.Container.Image,
+		ContainerCommand:    append(append([]string{}, template.Container.Command...), template.Container.Args...),
+	}
+	for _, parameter := range template.inputs().Parameters {
+		component.Inputs = append(component.Inputs, components.Put{Name: parameter.Name, Type: "string"})
+	}
+	for i, parameter := range template.outputs().Parameters {
+		if parameter.ValueFrom == nil || parameter.ValueFrom.Path == "" {
+			imp.problem(fmt.Sprintf("%s.outputs.parameters[%d]", field, i), "only the output parameters read from a path are supported")
+		}
+		component.Outputs = append(component.Outputs, components.Put{Name: parameter.Name, Type: "string"})
+	}
+
+	// Hector passes the inputs as arguments (--name value), so the expressions are not rendered
+	for _, value := range component.ContainerCommand {
+		if expressionRegexp.MatchString(value) {
+			imp.warnings = append(imp.warnings, fmt.Sprintf("the command of the template %s uses Argo expressions, but Hector passes the inputs as --name value arguments to the image", template.Name))
+			break
+		}
+	}
+	return component, true
+}
+
+// convertArguments function converts the arguments of a task into the inputs and outputs of a
+// definition task. Each input of the template takes the value of the argument of the task, its own
+// value or its default value, in that order, and the outputs take the path they are read from. It
+// takes as input the field of the task, the task and its template. Returns the definition task.
+func (imp *importer) convertArguments(field string, task DAGTask, template Template) definitions.DefinitionTask {
+	definitionTask := definitions.DefinitionTask{Name: task.Name, Inputs: []definitions.Parameter{}, Outputs: []definitions.Parameter{}}
+
+	arguments := map[string]int{}
+	for i, argument := range task.arguments().Parameters {
+		arguments[argument.Name] = i
+	}
+	for _, parameter := range template.inputs().Parameters {
+		value, valueField := parameter.Value, fmt.Sprintf("spec.templates[%d].inputs", imp.templates[template.Name])
+		if i, found := arguments[parameter.Name]; found {
+			value, valueField = task.arguments().Parameters[i].Value, fmt.Sprintf("%s.arguments.parameters[%d]", field, i)
+			delete(arguments, parameter.Name)
+		} else if value == nil {
+			value = parameter.Default
+		}
+		if value == nil {
+			imp.problem(field+".arguments", "the input %s of the template %s has no value", parameter.Name, template.Name)
+			continue
+		}
+		definitionTask.Inputs = append(definitionTask.Inputs, definitions.Parameter{Name: parameter.Name, Value: imp.resolve(valueField, string(*value))})
+	}
+	for i, argument := range task.arguments().Parameters {
+		if _, unknown := arguments[argument.Name]; unknown {
+			imp.problem(fmt.Sprintf("%s.arguments.parameters[%d]", field, i), "the template %s has no input %s", template.Name, argument.Name)
+		}
+	}
+
+	for _, parameter := range template.outputs().Parameters {
+		if parameter.ValueFrom != nil && parameter.ValueFrom.Path != "" {
+			definitionTask.Outputs = append(definitionTask.Outputs, definitions.Parameter{Name: parameter.Name, Value: parameter.ValueFrom.Path})
+		}
+	}
+	return definitionTask
+}
+
+// resolve function replaces the references to the parameters of the workflow
+// ({{workflow.parameters.name}}) in a value. The rest of the expressions depend on the execution
+// and are reported as problems. It takes as input the field of the value and the value. Returns
+// the resolved value.
+func (imp *importer) resolve(field string, value string) string {
+	return expressionRegexp.ReplaceAllStringFunc(value, func(expression string) string {
+		reference := expressionRegexp.FindStringSubmatch(expression)[1]
+		if name, found := cutPrefix(reference, "workflow.parameters."); found {
+			if parameter := imp.parameters[name]; parameter != nil {
+				return string(*parameter)
+			}
+			imp.problem(field, "the parameter %s of the workflow has no value", name)
+			return expression
+		}
+		imp.problem(field, "the expression %s cannot be resolved before the execution", expression)
+		return expression
+	})
+}
+
+// componentId function returns the identifier of the component converted from a template.
+func (imp *importer) componentId(template string) string {
+	return imp.name + "-" + template
+}
+
+// problem function records a part of the workflow that cannot be converted. It takes as input the
+// field of the workflow and the message, which may be formatted with the rest of arguments.
+func (imp *importer) problem(field string, format string, args ...any) {
+	imp.problems = append(imp.problems, errors.ValidationErr{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err function returns the ConversionErr with the recorded problems, or nil if there are none.
+func (imp *importer) err() error {
+	if len(imp.problems) == 0 {
+		return nil
+	}
+	return &errors.ConversionErr{Source: "Argo workflow", Problems: imp.problems}
+}
+
+// cutPrefix function removes a prefix from a string, reporting whether it was found.
+func cutPrefix(s string, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
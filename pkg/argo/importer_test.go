@@ -0,0 +1,119 @@
+package argo
+
+import (
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/validators"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestImportExample(t *testing.T) {
+	for i, file := range []string{"../../data/argo/example.yaml", "../../data/argo/example.json"} {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			workflow := Workflow{}
+			if err := workflow.FromFile(file); err != nil {
+				t.Fatal(err)
+			}
+			bundle, warnings, err := Import(&workflow)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The bundle must be accepted by Hector
+			if err := validators.NewValidator().ValidateBundleStruct(bundle); err != nil {
+				t.Error(err)
+			}
+			if len(bundle.Components) != 1 || bundle.Components[0].Id != "dag-diamond-echo" || bundle.Components[0].ContainerImage != "alpine:3.7" {
+				t.Error("got components ", bundle.Components)
+			}
+			if tasks := bundle.Specification.Spec.Dag.Tasks; len(tasks) != 4 || fmt.Sprint(tasks[3].Dependencies) != "[B C]" {
+				t.Error("got tasks ", tasks)
+			}
+			if inputs := bundle.Definition.Data.Tasks[1].Inputs; len(inputs) != 1 || inputs[0].Value != "B" {
+				t.Error("got inputs ", inputs)
+			}
+			if len(warnings) != 1 {
+				t.Error("got warnings ", warnings)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+
+	// The entrypoint of each workflow is completed with its tasks
+	body := `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: wf
+spec:
+  entrypoint: main
+  arguments:
+    parameters:
+    - name: greeting
+      value: hello
+  templates:
+  - name: echo
+    inputs:
+      parameters:
+      - name: message
+      - name: times
+        default: 1
+    outputs:
+      parameters:
+      - name: result
+        valueFrom:
+          path: /tmp/result.txt
+    container:
+      image: alpine:3.7
+  - name: script
+    script:
+      image: python
+      source: print(1)
+  - name: main
+    dag:
+      tasks:
+%TASKS`
+
+	var tests = []struct {
+		tasks string
+		want  string
+	}{
+		{"      - {name: A, template: echo, arguments: {parameters: [{name: message, value: '{{workflow.parameters.greeting}}'}]}}\n", ""},
+		{"      - {name: A, template: echo}\n", "The Argo workflow cannot be converted: spec.templates[2].dag.tasks[0].arguments: the input message of the template echo has no value."},
+		{"      - {name: A, template: script}\n", "The Argo workflow cannot be converted: spec.templates[1]: only container templates can be converted into components."},
+		{"      - {name: A, template: echo, when: '1 == 1', arguments: {parameters: [{name: message, value: '{{tasks.B.outputs.result}}'}]}}\n", "The Argo workflow cannot be converted: spec.templates[2].dag.tasks[0].when: conditional tasks are not supported; spec.templates[2].dag.tasks[0].arguments.parameters[0]: the expression {{tasks.B.outputs.result}} cannot be resolved before the execution."},
+		{"      - {name: A, template: other}\n", "The Argo workflow cannot be converted: spec.templates[2].dag.tasks[0].template: the template \"other\" is not a template of the workflow."},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			workflow := Workflow{}
+			if err := formats.Unmarshal([]byte(strings.Replace(body, "%TASKS", tt.tasks, 1)), formats.YAML, &workflow); err != nil {
+				t.Fatal(err)
+			}
+			bundle, _, err := Import(&workflow)
+
+			if err == nil {
+				inputs := bundle.Definition.Data.Tasks[0].Inputs
+				if fmt.Sprint(inputs) != "[{message hello} {times 1}]" {
+					t.Error("got inputs ", inputs)
+				}
+				if outputs := bundle.Definition.Data.Tasks[0].Outputs; fmt.Sprint(outputs) != "[{result /tmp/result.txt}]" {
+					t.Error("got outputs ", outputs)
+				}
+				err = fmt.Errorf("")
+			}
+			if err.Error() != tt.want {
+				t.Error("got ", err, ", want ", tt.want)
+			}
+		})
+	}
+}
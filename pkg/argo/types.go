@@ -0,0 +1,187 @@
+package argo
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/formats"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// We declare the kind and the api version of the Argo workflows.
+const (
+	WorkflowKind       = "Workflow"
+	WorkflowApiVersion = "argoproj.io/v1alpha1"
+)
+
+// AnyString is a string that Argo also accepts as a number or a boolean in the manifests
+// (e.g. value: 5).
+type AnyString string
+
+// UnmarshalJSON function is applied to AnyString variables and decodes them from a json
+// string, number or boolean.
+func (s *AnyString) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case string:
+		*s = AnyString(v)
+	case float64:
+		*s = AnyString(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		*s = AnyString(strconv.FormatBool(v))
+	default:
+		return fmt.Errorf("cannot unmarshal %s into a string", data)
+	}
+	return nil
+}
+
+type ValueFrom struct {
+	Path string `json:"path,omitempty"`
+}
+
+type Parameter struct {
+	Name      string     `json:"name"`
+	Value     *AnyString `json:"value,omitempty"`
+	Default   *AnyString `json:"default,omitempty"`
+	ValueFrom *ValueFrom `json:"valueFrom,omitempty"`
+}
+
+type Artifact struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	From string `json:"from,omitempty"`
+}
+
+type Arguments struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Artifacts  []Artifact  `json:"artifacts,omitempty"`
+}
+
+type Inputs struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Artifacts  []Artifact  `json:"artifacts,omitempty"`
+}
+
+type Outputs struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Artifacts  []Artifact  `json:"artifacts,omitempty"`
+}
+
+type Container struct {
+	Image   string   `json:"image"`
+	Command []string `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+type TemplateRef struct {
+	Name     string `json:"name"`
+	Template string `json:"template"`
+}
+
+type DAGTask struct {
+	Name         string       `json:"name"`
+	Template     string       `json:"template,omitempty"`
+	TemplateRef  *TemplateRef `json:"templateRef,omitempty"`
+	Dependencies []string     `json:"dependencies,omitempty"`
+	Depends      string       `json:"depends,omitempty"`
+	Arguments    *Arguments   `json:"arguments,omitempty"`
+	When         string       `json:"when,omitempty"`
+	WithItems    []any        `json:"withItems,omitempty"`
+	WithParam    string       `json:"withParam,omitempty"`
+	WithSequence any          `json:"withSequence,omitempty"`
+}
+
+// arguments function is applied to DAGTask variables and returns their arguments (empty if they
+// are not given).
+func (task *DAGTask) arguments() Arguments {
+	if task.Arguments == nil {
+		return Arguments{}
+	}
+	return *task.Arguments
+}
+
+type DAGTemplate struct {
+	Tasks []DAGTask `json:"tasks"`
+}
+
+type Template struct {
+	Name      string       `json:"name"`
+	Inputs    *Inputs      `json:"inputs,omitempty"`
+	Outputs   *Outputs     `json:"outputs,omitempty"`
+	Container *Container   `json:"container,omitempty"`
+	DAG       *DAGTemplate `json:"dag,omitempty"`
+	Steps     any          `json:"steps,omitempty"`
+	Script    any          `json:"script,omitempty"`
+	Resource  any          `json:"resource,omitempty"`
+	Suspend   any          `json:"suspend,omitempty"`
+}
+
+// inputs function is applied to Template variables and returns their inputs (empty if they are
+// not given).
+func (template *Template) inputs() Inputs {
+	if template.Inputs == nil {
+		return Inputs{}
+	}
+	return *template.Inputs
+}
+
+// outputs function is applied to Template variables and returns their outputs (empty if they are
+// not given).
+func (template *Template) outputs() Outputs {
+	if template.Outputs == nil {
+		return Outputs{}
+	}
+	return *template.Outputs
+}
+
+type WorkflowSpec struct {
+	Entrypoint string     `json:"entrypoint"`
+	Arguments  *Arguments `json:"arguments,omitempty"`
+	Templates  []Template `json:"templates"`
+}
+
+// arguments function is applied to WorkflowSpec variables and returns their arguments (empty if
+// they are not given).
+func (spec *WorkflowSpec) arguments() Arguments {
+	if spec.Arguments == nil {
+		return Arguments{}
+	}
+	return *spec.Arguments
+}
+
+type Metadata struct {
+	Name         string `json:"name,omitempty"`
+	GenerateName string `json:"generateName,omitempty"`
+	Namespace    string `json:"namespace,omitempty"`
+}
+
+type Workflow struct {
+	ApiVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Metadata   Metadata     `json:"metadata"`
+	Spec       WorkflowSpec `json:"spec"`
+}
+
+// String function is applied to Workflow variables and returns their content as a string.
+func (wf *Workflow) String() string {
+	s, _ := json.MarshalIndent(wf, "", "  ")
+	return string(s)
+}
+
+// FromFile function is applied on variables of type Workflow and it is in charge of dumping
+// the content of a file (JSON or, if its extension is .yaml or .yml, YAML) in this variable. It
+// takes as input the path of the file and returns an error type variable in charge of notifying
+// any problem.
+func (wf *Workflow) FromFile(file string) error {
+	content, err := pkg.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := formats.Unmarshal(content, formats.FromFileName(file), wf); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
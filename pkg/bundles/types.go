@@ -0,0 +1,107 @@
+package bundles
+
+import (
+	"archive/tar"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/specifications"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// We declare the layout of the tar archives: the components are files inside a components
+// directory, while the specification and the definition are files with a fixed name. The files
+// are JSON (.json) or YAML (.yaml or .yml) documents and may be nested in a root directory
+// (e.g. my-workflow/specification.yaml).
+const (
+	ComponentsDir     = "components"
+	SpecificationFile = "specification"
+	DefinitionFile    = "definition"
+)
+
+// documentExtensions contains the extensions of the files that are decoded from the archives.
+var documentExtensions = []string{".json", ".yaml", ".yml"}
+
+// Bundle groups the elements needed to onboard a workflow, so that they are validated together and
+// stored at once: either all of them are accepted or none is. The specification and the definition
+// are optional (the definition may execute a specification that is already stored).
+type Bundle struct {
+	Components    []components.Component        `json:"components"`
+	Specification *specifications.Specification `json:"specification,omitempty"`
+	Definition    *definitions.Definition       `json:"definition,omitempty"`
+}
+
+// String function is applied to Bundle variables and returns their content as a string.
+func (b *Bundle) String() string {
+	s, _ := json.MarshalIndent(b, "", "  ")
+	return string(s)
+}
+
+// Empty function is applied to Bundle variables and reports whether the bundle contains no element.
+func (b *Bundle) Empty() bool {
+	return len(b.Components) == 0 && b.Specification == nil && b.Definition == nil
+}
+
+// FromTar function reads a bundle from a tar archive. It takes as input the reader of the archive.
+// Returns the pointer to the Bundle and an error variable to report any problems, such as files
+// that do not follow the layout of the bundles.
+func FromTar(reader io.Reader) (*Bundle, error) {
+	bundle := Bundle{}
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		// We only consider the regular files
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		// We decode each file depending on its location
+		name := path.Clean(header.Name)
+		ext := path.Ext(name)
+		if !slices.Contains(documentExtensions, ext) {
+			return nil, fmt.Errorf("unexpected file %s in the archive", header.Name)
+		}
+		format := formats.FromFileName(name)
+		switch base := strings.TrimSuffix(path.Base(name), ext); {
+		case base == SpecificationFile:
+			if bundle.Specification != nil {
+				return nil, fmt.Errorf("the archive contains more than one %s file", SpecificationFile)
+			}
+			bundle.Specification = &specifications.Specification{}
+			err = formats.Unmarshal(content, format, bundle.Specification)
+		case base == DefinitionFile:
+			if bundle.Definition != nil {
+				return nil, fmt.Errorf("the archive contains more than one %s file", DefinitionFile)
+			}
+			bundle.Definition = &definitions.Definition{}
+			err = formats.Unmarshal(content, format, bundle.Definition)
+		case path.Base(path.Dir(name)) == ComponentsDir:
+			component := components.Component{}
+			err = formats.Unmarshal(content, format, &component)
+			bundle.Components = append(bundle.Components, component)
+		default:
+			return nil, fmt.Errorf("unexpected file %s in the archive", header.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid file %s: %w", header.Name, err)
+		}
+	}
+	return &bundle, nil
+}
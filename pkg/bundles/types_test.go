@@ -0,0 +1,60 @@
+package bundles
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// newTar function builds a tar archive in memory with the given files (name and content).
+func newTar(files [][2]string) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	archive := tar.NewWriter(buffer)
+	for _, file := range files {
+		archive.WriteHeader(&tar.Header{Name: file[0], Mode: 0600, Size: int64(len(file[1])), Typeflag: tar.TypeReg})
+		archive.Write([]byte(file[1]))
+	}
+	archive.Close()
+	return buffer
+}
+
+func TestFromTar(t *testing.T) {
+	component := `{"id": "Comp-ID", "name": "Component"}`
+	specification := `{"id": "Spec-ID", "name": "Specification"}`
+	definition := `{"name": "Definition", "specificationId": "Spec-ID"}`
+
+	var tests = []struct {
+		files         [][2]string
+		numComponents int
+		want          string
+	}{
+		{[][2]string{{"components/a.json", component}, {"components/b.json", component}, {"specification.json", specification}, {"definition.json", definition}}, 2, ""},
+		{[][2]string{{"workflow/components/a.json", component}, {"workflow/specification.json", specification}}, 1, ""},
+		{[][2]string{{"definition.json", definition}}, 0, ""},
+		{[][2]string{{"components/a.yaml", "id: Comp-ID\nname: Component\n"}, {"specification.yml", "id: Spec-ID\nname: Specification\n"}}, 1, ""},
+		{[][2]string{{"README.md", "# Workflow"}}, 0, "unexpected file README.md in the archive"},
+		{[][2]string{{"specification.json", specification}, {"other/specification.json", specification}}, 0, "the archive contains more than one specification file"},
+		{[][2]string{{"specification.json", specification}, {"specification.yaml", specification}}, 0, "the archive contains more than one specification file"},
+		{[][2]string{{"components/a.json", `{"id": 1}`}}, 0, "invalid file components/a.json: json: cannot unmarshal number into Go struct field Component.id of type string"},
+		{[][2]string{{"components/a.yaml", "id: Comp-ID\n  name: Component\n"}}, 0, "invalid file components/a.yaml: line 2: yaml: mapping values are not allowed in this context"},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			bundle, err := FromTar(newTar(tt.files))
+
+			if err == nil {
+				if len(bundle.Components) != tt.numComponents {
+					t.Error("got ", len(bundle.Components), " components, want ", tt.numComponents)
+				}
+				err = fmt.Errorf("")
+			}
+			if err.Error() != tt.want {
+				t.Error("got ", err, ", want ", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/specifications"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestTimeout is the maximum duration of the requests, except for the streams.
+const requestTimeout = 30 * time.Second
+
+// Client is a structured type that sends requests to the api of a Hector server on behalf of the
+// owner of a token. All the elements are read from and written to the namespace of the client.
+type Client struct {
+	Url        string
+	Token      string
+	Namespace  string
+	HttpClient *http.Client
+}
+
+// ApiErr reports a request rejected by the server, following the error envelope of the api.
+type ApiErr struct {
+	Status   int       `json:"-"`
+	Code     string    `json:"code"`
+	Message  string    `json:"message"`
+	Field    string    `json:"field,omitempty"`
+	Line     int       `json:"line,omitempty"`
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+// Problem is each one of the problems that make a document invalid.
+type Problem struct {
+	Field   string `json:"field"`
+	Pointer string `json:"pointer,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error function applied on a variable of type ApiErr
+// returns the corresponding error message in the form of string,
+// followed by the problems of the document (if any).
+func (e *ApiErr) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.Status)
+	}
+	for _, problem := range e.Problems {
+		message += "\n  - "
+		if problem.Line > 0 {
+			message += "line " + strconv.Itoa(problem.Line) + ": "
+		}
+		message += problem.Field + ": " + problem.Message
+	}
+	return message
+}
+
+// ListOptions contains the query parameters accepted by the list endpoints. The empty fields are
+// not sent.
+type ListOptions struct {
+	Limit           int
+	Cursor          string
+	Sort            string
+	Descending      bool
+	Name            string
+	SpecificationId string
+	Status          string
+}
+
+// values function converts the listing options into the query of the request.
+func (options *ListOptions) values() url.Values {
+	query := url.Values{}
+	if options == nil {
+		return query
+	}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.Descending {
+		query.Set("order", "desc")
+	}
+	for name, value := range map[string]string{"cursor": options.Cursor, "sort": options.Sort, "name": options.Name, "specificationId": options.SpecificationId, "status": options.Status} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
+
+// NewClient function creates a new instance of the Client type. It takes as input the url of the
+// server, the secret of the token and the namespace. Returns a pointer to the constructed variable.
+func NewClient(serverUrl string, token string, namespace string) *Client {
+	return &Client{
+		Url:        strings.TrimRight(serverUrl, "/"),
+		Token:      token,
+		Namespace:  namespace,
+		HttpClient: &http.Client{},
+	}
+}
+
+// SubmitComponent function sends a component to the server. It takes as input the document of the
+// component and its format. Returns an error variable to report any problems.
+func (c *Client) SubmitComponent(content []byte, format formats.Format) error {
+	return c.do(http.MethodPost, c.namespacePath("/component/submit"), content, format, nil)
+}
+
+// SubmitSpecification function sends a specification to the server, which calculates its planning.
+// It takes as input the document of the specification and its format. Returns an error variable to
+// report any problems.
+func (c *Client) SubmitSpecification(content []byte, format formats.Format) error {
+	return c.do(http.MethodPost, c.namespacePath("/specification/submit"), content, format, nil)
+}
+
+// ExecuteDefinition function queues a definition for its execution. It takes as input the document
+// of the definition and its format. Returns the identifier of the definition and an error variable
+// to report any problems.
+func (c *Client) ExecuteDefinition(content []byte, format formats.Format) (string, error) {
+	var response struct {
+		Id string `json:"id"`
+	}
+	if err := c.do(http.MethodPost, c.namespacePath("/definition/execute"), content, format, &response); err != nil {
+		return "", err
+	}
+	return response.Id, nil
+}
+
+// GetComponent function returns the component with the given identifier.
+func (c *Client) GetComponent(id string) (*components.Component, error) {
+	return get[components.Component](c, c.namespacePath("/component/get/"+url.PathEscape(id)))
+}
+
+// GetSpecification function returns the specification with the given identifier.
+func (c *Client) GetSpecification(id string) (*specifications.Specification, error) {
+	return get[specifications.Specification](c, c.namespacePath("/specification/get/"+url.PathEscape(id)))
+}
+
+// GetDefinition function returns the definition with the given identifier.
+func (c *Client) GetDefinition(id string) (*definitions.Definition, error) {
+	return get[definitions.Definition](c, c.namespacePath("/definition/get/"+url.PathEscape(id)))
+}
+
+// GetResultDefinition function returns the result of the definition with the given identifier.
+func (c *Client) GetResultDefinition(id string) (*results.ResultDefinition, error) {
+	return get[results.ResultDefinition](c, c.namespacePath("/result/get/"+url.PathEscape(id)))
+}
+
+// ListComponents function returns a page of the components of the namespace.
+func (c *Client) ListComponents(options *ListOptions) (*datastores.Page[components.Component], error) {
+	return get[datastores.Page[components.Component]](c, c.namespacePath("/component/list")+query(options))
+}
+
+// ListSpecifications function returns a page of the specifications of the namespace.
+func (c *Client) ListSpecifications(options *ListOptions) (*datastores.Page[specifications.Specification], error) {
+	return get[datastores.Page[specifications.Specification]](c, c.namespacePath("/specification/list")+query(options))
+}
+
+// ListDefinitions function returns a page of the definitions of the namespace.
+func (c *Client) ListDefinitions(options *ListOptions) (*datastores.Page[definitions.Definition], error) {
+	return get[datastores.Page[definitions.Definition]](c, c.namespacePath("/definition/list")+query(options))
+}
+
+// ListResultDefinitions function returns a page of the results of the namespace.
+func (c *Client) ListResultDefinitions(options *ListOptions) (*datastores.Page[results.ResultDefinition], error) {
+	return get[datastores.Page[results.ResultDefinition]](c, c.namespacePath("/result/list")+query(options))
+}
+
+// WatchResultDefinition function follows the execution of a definition through the Server-Sent
+// Events of the api. The handler receives the current state of each job and then every transition,
+// until the execution ends. It takes as input the identifier of the definition and the handler of
+// the events. Returns the final result of the definition and an error variable to report any
+// problems.
+func (c *Client) WatchResultDefinition(id string, handler func(events.Event)) (*results.ResultDefinition, error) {
+	request, err := c.newRequest(http.MethodGet, c.namespacePath("/result/watch/"+url.PathEscape(id)), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "text/event-stream")
+
+	// The stream lasts as long as the execution, so the timeout of the client does not apply
+	httpClient := *c.HttpClient
+	httpClient.Timeout = 0
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, readError(response)
+	}
+
+	// We read the events, which are separated by blank lines, ignoring the comments
+	name, data := "", ""
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(line[len("event:"):])
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(line[len("data:"):])
+		case line == "" && data != "":
+			switch name {
+			case "job":
+				event := events.Event{}
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					return nil, err
+				}
+				handler(event)
+			case "end":
+				resultDefinition := results.ResultDefinition{}
+				if err := json.Unmarshal([]byte(data), &resultDefinition); err != nil {
+					return nil, err
+				}
+				return &resultDefinition, nil
+			}
+			name, data = "", ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("the stream of the definition %s ended before its execution", id)
+}
+
+// get function requests an element from the server and decodes it. It takes as input the client
+// and the path of the element. Returns the pointer to the element and an error variable to report
+// any problems.
+func get[V any](c *Client, path string) (*V, error) {
+	var element V
+	if err := c.do(http.MethodGet, path, nil, "", &element); err != nil {
+		return nil, err
+	}
+	return &element, nil
+}
+
+// query function renders the listing options as the query of a url (empty if there is none).
+func query(options *ListOptions) string {
+	if values := options.values(); len(values) > 0 {
+		return "?" + values.Encode()
+	}
+	return ""
+}
+
+// namespacePath function prefixes a path with the namespace of the client.
+func (c *Client) namespacePath(path string) string {
+	return "/namespaces/" + url.PathEscape(c.Namespace) + path
+}
+
+// newRequest function builds an authenticated request to the server. It takes as input the method,
+// the path, the body (if any) and its format. Returns the request and an error variable to report
+// any problems.
+func (c *Client) newRequest(method string, path string, body []byte, format formats.Format) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequest(method, c.Url+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", string(format))
+	}
+	return request, nil
+}
+
+// do function sends a request to the server and decodes the json response. It takes as input the
+// method, the path, the body (if any), its format and the variable where the response is decoded
+// (nil if it is discarded). Returns an error variable to report any problems.
+func (c *Client) do(method string, path string, body []byte, format formats.Format, v any) error {
+	request, err := c.newRequest(method, path, body, format)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", string(formats.JSON))
+
+	httpClient := *c.HttpClient
+	if httpClient.Timeout == 0 {
+		httpClient.Timeout = requestTimeout
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return readError(response)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// readError function decodes the error envelope of a failed response. It takes as input the
+// response and returns the corresponding ApiErr.
+func readError(response *http.Response) error {
+	apiErr := ApiErr{}
+	content, _ := io.ReadAll(response.Body)
+	if err := json.Unmarshal(content, &apiErr); err != nil {
+		apiErr.Message = strings.TrimSpace(string(content))
+	}
+	apiErr.Status = response.StatusCode
+	return &apiErr
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/api"
+	"dag/hector/golang/module/pkg/controllers"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/formats"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/schedulers"
+	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/validators"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestServer function raises an api over a mocked datastore. It returns the controller, the
+// server and the secret of a submitter token.
+func newTestServer(t *testing.T) (*controllers.Controller, *httptest.Server, string) {
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	token, secret := tokens.NewToken("alice", tokens.Submitter)
+	datastore.AddToken(token)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	controller := controllers.NewController(nil, &scheduler, &datastore, validators.NewValidator())
+	a, err := api.NewApi(controller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(a.Router)
+	t.Cleanup(server.Close)
+	return controller, server, secret
+}
+
+func TestClient(t *testing.T) {
+	_, server, secret := newTestServer(t)
+	c := NewClient(server.URL+"/", secret, "team-a")
+
+	// We submit the toy workflow
+	for _, file := range []string{
+		"../../data/hector/toy_components/concat_files/concat-files-component.json",
+		"../../data/hector/toy_components/concat_messages/concat-messages-component.json",
+		"../../data/hector/toy_components/count_letters/count-letters-component.json",
+	} {
+		content, _ := pkg.ReadFile(file)
+		if err := c.SubmitComponent(content, formats.JSON); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content, _ := pkg.ReadFile("../../data/hector/toy_specifications/toy_specification_1.json")
+	if err := c.SubmitSpecification(content, formats.JSON); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = pkg.ReadFile("../../data/hector/toy_definitions/toy_definition_1.json")
+	id, err := c.ExecuteDefinition(content, formats.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The submitted elements can be read
+	page, err := c.ListComponents(&ListOptions{Limit: 2, Sort: "name"})
+	if err != nil || len(page.Items) != 2 || page.NextCursor == "" {
+		t.Error("got page ", page, " and error ", err)
+	}
+	resultDefinition, err := c.GetResultDefinition(id)
+	if err != nil || resultDefinition.Id != id || resultDefinition.Status() != results.Waiting {
+		t.Error("got result ", resultDefinition, " and error ", err)
+	}
+	resultsPage, err := c.ListResultDefinitions(&ListOptions{Status: "Waiting"})
+	if err != nil || len(resultsPage.Items) != 1 {
+		t.Error("got page ", resultsPage, " and error ", err)
+	}
+
+	// The rejected requests are reported with the error envelope of the api
+	var tests = []struct {
+		request func() error
+		status  int
+		want    string
+	}{
+		{func() error { _, err := c.GetComponent("Unknown-ID"); return err }, http.StatusNotFound, "not_found"},
+		{func() error { return c.SubmitComponent([]byte("id: [\n"), formats.YAML) }, http.StatusBadRequest, "invalid_request"},
+		{func() error { _, err := c.ListDefinitions(&ListOptions{Status: "Unknown"}); return err }, http.StatusBadRequest, "invalid_request"},
+		{func() error {
+			_, err := NewClient(server.URL, "wrong", "team-a").GetComponent("Unknown-ID")
+			return err
+		}, http.StatusUnauthorized, "unauthorized"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			var apiErr *ApiErr
+			if err := tt.request(); !stderrors.As(err, &apiErr) {
+				t.Fatal("got ", err, ", want an ApiErr")
+			}
+			if apiErr.Status != tt.status || apiErr.Code != tt.want {
+				t.Error("got ", apiErr.Status, " ", apiErr.Code, ", want ", tt.status, " ", tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchResultDefinition(t *testing.T) {
+	controller, server, secret := newTestServer(t)
+	(*controller.Datastore).AddResultDefinition(&results.ResultDefinition{
+		Id:         "RD-ID",
+		Namespace:  "default",
+		ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: results.Waiting}},
+	})
+
+	// The controller publishes the transitions once the client is subscribed
+	go func() {
+		for controller.Broker.Subscribers("RD-ID") == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		jobRes := results.ResultJob{Id: "J1", Name: "A", Status: results.Error, Logs: "failed"}
+		(*controller.Datastore).UpdateResultJob(&jobRes, "default", "RD-ID")
+		controller.Broker.Publish(events.Event{Namespace: "default", DefinitionId: "RD-ID", ResultJob: jobRes})
+		controller.Broker.Close("RD-ID")
+	}()
+
+	var statuses []results.Status
+	resultDefinition, err := NewClient(server.URL, secret, "default").WatchResultDefinition("RD-ID", func(event events.Event) {
+		statuses = append(statuses, event.ResultJob.Status)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The initial state may already contain the transition
+	if len(statuses) == 0 || statuses[len(statuses)-1] != results.Error {
+		t.Error("got statuses ", statuses)
+	}
+	if resultDefinition.Status() != results.Error || resultDefinition.ResultJobs[0].Logs != "failed" {
+		t.Error("got result ", resultDefinition)
+	}
+}
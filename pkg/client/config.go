@@ -0,0 +1,90 @@
+package client
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/formats"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// We declare the environment variables that override the fields of the configuration file.
+const (
+	ConfigEnv    = "HECTORCTL_CONFIG"
+	UrlEnv       = "HECTOR_URL"
+	TokenEnv     = "HECTOR_TOKEN"
+	NamespaceEnv = "HECTOR_NAMESPACE"
+)
+
+// We declare the default values of the configuration.
+const (
+	DefaultUrl       = "http://localhost:8080"
+	DefaultNamespace = "default"
+)
+
+// Config contains the connection settings of the command-line client.
+type Config struct {
+	Url       string `json:"url"`
+	Token     string `json:"token"`
+	Namespace string `json:"namespace"`
+}
+
+// DefaultConfigPath function returns the path of the configuration file used when none is given,
+// which is $HECTORCTL_CONFIG or, if it is not set, .hector/config.yaml in the home directory.
+func DefaultConfigPath() string {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".hector", "config.yaml")
+}
+
+// LoadConfig function reads the configuration of the client from a file (JSON or, if its extension
+// is .yaml or .yml, YAML). A missing file is only an error when the path has been given explicitly.
+// The HECTOR_URL, HECTOR_TOKEN and HECTOR_NAMESPACE environment variables override the values of the
+// file, and the unset fields take the default values. It takes as input the path of the file and a
+// boolean that indicates whether it has been given explicitly. Returns the pointer to the
+// configuration and an error variable to report any problems.
+func LoadConfig(path string, explicit bool) (*Config, error) {
+	config := Config{}
+
+	if path != "" {
+		content, err := pkg.ReadFile(path)
+		if err != nil && (explicit || !os.IsNotExist(err)) {
+			return nil, err
+		}
+		if err == nil {
+			if err := formats.Unmarshal(content, formats.FromFileName(path), &config); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+
+	// We apply the environment variables and the defaults
+	for _, field := range []struct {
+		value      *string
+		env        string
+		defaultVal string
+	}{
+		{&config.Url, UrlEnv, DefaultUrl},
+		{&config.Token, TokenEnv, ""},
+		{&config.Namespace, NamespaceEnv, DefaultNamespace},
+	} {
+		if value := os.Getenv(field.env); value != "" {
+			*field.value = value
+		}
+		if *field.value == "" {
+			*field.value = field.defaultVal
+		}
+	}
+
+	return &config, nil
+}
+
+// NewClient function creates a client that connects to the server of the configuration.
+func (config *Config) NewClient() *Client {
+	return NewClient(config.Url, config.Token, config.Namespace)
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config.yaml")
+	os.WriteFile(yamlFile, []byte("url: https://hector.example.com\ntoken: secret\n"), 0600)
+	jsonFile := filepath.Join(dir, "config.json")
+	os.WriteFile(jsonFile, []byte(`{"url": "https://hector.example.com", "namespace": "team-a"}`), 0600)
+	missingFile := filepath.Join(dir, "missing.yaml")
+
+	var tests = []struct {
+		path     string
+		explicit bool
+		env      map[string]string
+		want     Config
+		err      bool
+	}{
+		{yamlFile, true, nil, Config{Url: "https://hector.example.com", Token: "secret", Namespace: DefaultNamespace}, false},
+		{jsonFile, true, map[string]string{TokenEnv: "env-secret", NamespaceEnv: "team-b"}, Config{Url: "https://hector.example.com", Token: "env-secret", Namespace: "team-b"}, false},
+		{missingFile, false, map[string]string{UrlEnv: "http://localhost:9090"}, Config{Url: "http://localhost:9090", Namespace: DefaultNamespace}, false},
+		{missingFile, true, nil, Config{}, true},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			for _, env := range []string{UrlEnv, TokenEnv, NamespaceEnv} {
+				t.Setenv(env, tt.env[env])
+			}
+			config, err := LoadConfig(tt.path, tt.explicit)
+			if (err != nil) != tt.err {
+				t.Fatal("got error ", err)
+			}
+			if err == nil && *config != tt.want {
+				t.Error("got ", *config, ", want ", tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package components
 
 import (
 	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/formats"
 	"encoding/json"
+	"fmt"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -23,6 +25,7 @@ type Put struct {
 
 type Component struct {
 	Id                  string   `json:"id" validate:"required"`
+	Namespace           string   `json:"namespace" validate:"omitempty,namespace"`
 	Name                string   `json:"name" validate:"required"`
 	ApiVersion          string   `json:"apiVersion" validate:"required"`
 	Inputs              []Put    `json:"inputs" validate:"dive"`
@@ -39,15 +42,16 @@ func (comp *Component) String() string {
 }
 
 // FromFile function is applied on variables of type Component and it is in charge of dumping
-// the content of a file in this variable. It takes as input the path of the file and returns
-// an error type variable in charge of notifying any problem.
+// the content of a file (JSON or, if its extension is .yaml or .yml, YAML) in this variable. It
+// takes as input the path of the file and returns an error type variable in charge of notifying
+// any problem.
 func (comp *Component) FromFile(file string) error {
 	content, err := pkg.ReadFile(file)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(content, comp); err != nil {
-		return err
+	if err := formats.Unmarshal(content, formats.FromFileName(file), comp); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
 	}
 	return nil
 }
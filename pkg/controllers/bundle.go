@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"dag/hector/golang/module/pkg/bundles"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/namespaces"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/validators"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+)
+
+// bundleDatastore presents the elements of a bundle as if they were already stored, so that they can
+// be validated together with the stored ones before any of them is inserted. The rest of the methods
+// are those of the underlying datastore.
+type bundleDatastore struct {
+	datastores.Datastore
+	bundle   *bundles.Bundle
+	planning *[][]string
+}
+
+// GetComponent function extracts a component from the bundle or, if it is not there, from the
+// underlying datastore.
+func (d *bundleDatastore) GetComponent(namespace string, id string) (*components.Component, error) {
+	idx := slices.IndexFunc(d.bundle.Components, func(c components.Component) bool { return c.Namespace == namespace && c.Id == id })
+	if idx != -1 {
+		return &d.bundle.Components[idx], nil
+	}
+	return d.Datastore.GetComponent(namespace, id)
+}
+
+// GetSpecification function extracts the specification of the bundle or, if it is not the requested
+// one, a specification from the underlying datastore.
+func (d *bundleDatastore) GetSpecification(namespace string, id string) (*specifications.Specification, error) {
+	if specification := d.bundle.Specification; specification != nil && specification.Namespace == namespace && specification.Id == id {
+		return specification, nil
+	}
+	return d.Datastore.GetSpecification(namespace, id)
+}
+
+// GetPlanning function extracts the planning of the specification of the bundle or, if it is not the
+// requested one, a planning from the underlying datastore.
+func (d *bundleDatastore) GetPlanning(namespace string, id string) (*[][]string, error) {
+	if specification := d.bundle.Specification; specification != nil && specification.Namespace == namespace && specification.Id == id {
+		return d.planning, nil
+	}
+	return d.Datastore.GetPlanning(namespace, id)
+}
+
+// SubmitBundle function validates all the elements of a bundle together and stores them in a single
+// operation of the datastore, so that either all of them are accepted or none is. The specification
+// can only use components that are in the bundle or already stored, and the definition is checked
+// against the specification and components of the bundle or the stored ones. The definition (if any)
+// is added to the execution queue. Takes as input the pointer to a Bundle whose structure has already
+// been validated. Returns the pointer to the initial ResultDefinition of the definition (nil if the
+// bundle has no definition) and an error variable to report any problems.
+func (c *Controller) SubmitBundle(bundle *bundles.Bundle) (*results.ResultDefinition, error) {
+	overlay := &bundleDatastore{Datastore: *c.Datastore, bundle: bundle}
+	var datastore datastores.Datastore = overlay
+
+	// Validate the components used by the specification and calculate its topological sort
+	if specification := bundle.Specification; specification != nil {
+		if err := checkComponentsExist(specification, datastore, c.Validator.ValidateComponentReferences); err != nil {
+			return nil, fmt.Errorf("invalid scheme: %w", validators.Prefix(err, "/specification"))
+		}
+		planning, err := (*c.Scheduler).Plan(specification)
+		if err != nil {
+			return nil, fmt.Errorf("error during planning calculation %w", err)
+		}
+		overlay.planning = &planning
+	}
+
+	// Validate the definition against the specification and components
+	var resultDefinition *results.ResultDefinition
+	if definition := bundle.Definition; definition != nil {
+		nestedJobs, err := getJobs(definition, &datastore, c.Validator)
+		if err != nil {
+			return nil, fmt.Errorf("error while trying to get jobs %w", validators.Prefix(err, "/definition"))
+		}
+		resultDefinition = newResultDefinition(definition, nestedJobs)
+	}
+
+	// Store all the elements at once
+	if err := (*c.Datastore).AddBundle(bundle, overlay.planning, resultDefinition); err != nil {
+		return nil, fmt.Errorf("error during insertion into the datastore %w", err)
+	}
+	if resultDefinition != nil {
+		c.signalQueue()
+		c.updateQueueDepth()
+	}
+
+	return resultDefinition, nil
+}
+
+// checkComponentsExist function ensures that all the components referenced by the tasks of a
+// specification can be used by it and exist in the datastore. It takes as input the pointer to the
+// Specification, the datastore and the function that validates the references. Returns an error
+// variable in charge of notifying all the missing components, whose pointers are relative to the
+// specification.
+func checkComponentsExist(specification *specifications.Specification, datastore datastores.Datastore, validateReferences func(*specifications.Specification) error) error {
+	if err := validateReferences(specification); err != nil {
+		return err
+	}
+	var violations validators.Violations
+	for i, task := range specification.Spec.Dag.Tasks {
+		namespace, id, _ := namespaces.ResolveComponent(specification.Namespace, task.Component)
+		if _, err := datastore.GetComponent(namespace, id); err != nil {
+			if _, notFound := err.(*errors.ElementNotFoundErr); !notFound {
+				return err
+			}
+			violations = append(violations, validators.Violation{Pointer: fmt.Sprintf("/spec/dag/tasks/%d/component", i), Code: validators.NotFoundCode, Message: fmt.Sprintf("component %s is neither in the bundle nor in the datastore", task.Component)})
+		}
+	}
+	return violations.Err()
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"dag/hector/golang/module/pkg/bundles"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/schedulers"
+	"dag/hector/golang/module/pkg/schedulers/topologicalgrouped"
+	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/validators"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestSubmitBundle(t *testing.T) {
+
+	// Declare test components (one of them is already stored in the shared namespace)
+	newComponent := func(id string, namespace string) components.Component {
+		return components.Component{Id: id, Namespace: namespace, Inputs: []components.Put{{Name: "input_1", Type: "string"}}, ContainerImage: "image/name"}
+	}
+	storedComponent := newComponent("Stored-Comp-ID", "shared")
+
+	// Declare test specifications and definitions
+	newSpecification := func(id string, components ...string) *specifications.Specification {
+		specification := specifications.Specification{Id: id, Namespace: "team-a"}
+		for i, component := range components {
+			specification.Spec.Dag.Tasks = append(specification.Spec.Dag.Tasks, specifications.SpecificationTask{Name: "T" + strconv.Itoa(i), Component: component})
+		}
+		return &specification
+	}
+	newDefinition := func(id string, specificationId string, value interface{}, tasks int) *definitions.Definition {
+		definition := definitions.Definition{Id: id, Namespace: "team-a", SpecificationId: specificationId}
+		for i := 0; i < tasks; i++ {
+			definition.Data.Tasks = append(definition.Data.Tasks, definitions.DefinitionTask{Name: "T" + strconv.Itoa(i), Inputs: []definitions.Parameter{{Name: "input_1", Value: value}}})
+		}
+		return &definition
+	}
+
+	// Create Datastore
+	dbm := dbmock.NewDBMock()
+	var datastore datastores.Datastore = dbm
+	datastore.AddComponent(&storedComponent)
+
+	// Create Controller (workers are not started, so the queue is not drained)
+	var scheduler schedulers.Scheduler = topologicalgrouped.NewTopologicalGrouped()
+	controller := NewController(nil, &scheduler, &datastore, validators.NewValidator())
+
+	// Classic tests variable
+	var tests = []struct {
+		bundle  bundles.Bundle
+		pointer string
+		err     string
+	}{
+		{
+			bundle: bundles.Bundle{
+				Components:    []components.Component{newComponent("Comp-ID", "team-a")},
+				Specification: newSpecification("Spec-1", "Comp-ID", "shared/Stored-Comp-ID"),
+				Definition:    newDefinition("Def-1", "Spec-1", "value", 2),
+			},
+			err: "",
+		},
+		{
+			bundle: bundles.Bundle{
+				Components:    []components.Component{newComponent("Other-Comp-ID", "team-a")},
+				Specification: newSpecification("Spec-2", "Other-Comp-ID", "Missing-Comp-ID"),
+			},
+			pointer: "/specification/spec/dag/tasks/1/component",
+			err:     "invalid scheme: component Missing-Comp-ID is neither in the bundle nor in the datastore",
+		},
+		{
+			bundle: bundles.Bundle{
+				Specification: newSpecification("Spec-3", "Comp-ID"),
+				Definition:    newDefinition("Def-3", "Spec-3", 3, 1),
+			},
+			pointer: "/definition/data/tasks/0/inputs/0/value",
+			err:     "error while trying to get jobs parameter input_1 has an invalid value in the definition file",
+		},
+		{
+			bundle: bundles.Bundle{
+				Components: []components.Component{newComponent("New-Comp-ID", "team-a"), newComponent("Comp-ID", "team-a")},
+			},
+			err: "error during insertion into the datastore A components.Component with id team-a/Comp-ID is already stored in the database.",
+		},
+		{
+			bundle: bundles.Bundle{
+				Definition: newDefinition("Def-5", "Spec-1", "value", 2),
+			},
+			err: "",
+		},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			components, queued := len(dbm.ComponentStructs), len(dbm.QueuedDefinitionIds)
+			_, err := controller.SubmitBundle(&tt.bundle)
+
+			var pointer string
+			var violations validators.Violations
+			if stderrors.As(err, &violations) {
+				pointer = violations[0].Pointer
+			}
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+
+			if tt.err != err.Error() || tt.pointer != pointer {
+				t.Error("The error obtained was not as expected. Got " + pointer + ": " + err.Error() + " but want " + tt.pointer + ": " + tt.err)
+			} else if tt.err == "" {
+				if tt.bundle.Definition != nil && len(dbm.QueuedDefinitionIds) != queued+1 {
+					t.Error("The definition of the bundle must be queued")
+				}
+			} else if len(dbm.ComponentStructs) != components || len(dbm.QueuedDefinitionIds) != queued {
+				t.Error("No element of an invalid bundle must be stored in the datastore")
+			}
+		})
+	}
+}
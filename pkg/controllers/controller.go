@@ -1,30 +1,449 @@
 package controllers
 
 import (
+	"context"
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/datastores"
 	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/dispatchers"
 	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/events"
 	"dag/hector/golang/module/pkg/executors"
 	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/metrics"
+	"dag/hector/golang/module/pkg/namespaces"
 	"dag/hector/golang/module/pkg/results"
 	"dag/hector/golang/module/pkg/schedulers"
 	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tracing"
 	"dag/hector/golang/module/pkg/validators"
+	stderrors "errors"
 	"fmt"
-	"log"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/rs/xid"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 )
 
+// queuePollInterval is the maximum time an idle worker waits before checking the queue again.
+const queuePollInterval = 5 * time.Second
+
 type Controller struct {
 	Executor  *executors.Executor
 	Scheduler *schedulers.Scheduler
 	Datastore *datastores.Datastore
 	Validator *validators.Validator
+	Broker    *events.Broker
+
+	// Dispatcher notifies the webhooks of the finished jobs and definitions (optional).
+	Dispatcher *dispatchers.Dispatcher
+
+	// Logger writes the lines of the invocations, correlated by their request and definition.
+	Logger logrus.FieldLogger
+
+	// Tracer records the spans of the invocations, which belong to the trace of the request that
+	// submitted them (optional).
+	Tracer *tracing.Tracer
+
+	// queueSignal wakes up an idle worker when a new definition is queued.
+	queueSignal chan struct{}
+
+	// stop is closed to make the workers exit once their current invocation has ended, which the
+	// workers group allows to wait for.
+	stop     chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+
+	// executions contains the definitions that are being invoked, so that they can be cancelled. The
+	// mutex also makes the start of an invocation and the recording of a cancellation exclusive.
+	mutex      sync.Mutex
+	executions map[string]*execution
+}
+
+// execution allows to stop an ongoing invocation and to wait until it has ended.
+type execution struct {
+	namespace string
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	// reason is the reason for the cancellation, which is set before cancel is called (so it can
+	// be read once the context is done).
+	reason string
+}
+
+// executionKey is the key of the context values that contain the ongoing execution.
+type executionKey struct{}
+
+// NewController function creates a new instance of the Controller type. It takes as input the
+// pointers of the executor, the scheduler, the datastore and the validator. It returns the
+// pointer to the constructed variable.
+func NewController(executor *executors.Executor, scheduler *schedulers.Scheduler, datastore *datastores.Datastore, validator *validators.Validator) *Controller {
+	return &Controller{
+		Executor:    executor,
+		Scheduler:   scheduler,
+		Datastore:   datastore,
+		Validator:   validator,
+		Broker:      events.NewBroker(),
+		Logger:      logging.Default(),
+		queueSignal: make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		executions:  make(map[string]*execution),
+	}
+}
+
+// UseDispatcher function enables the webhooks of the definitions. The dispatcher is notified of
+// every job that reaches a final status through the broker, and of every definition that ends.
+// It takes as input the pointer of the Dispatcher.
+func (c *Controller) UseDispatcher(dispatcher *dispatchers.Dispatcher) {
+	c.Dispatcher = dispatcher
+	c.Broker.Handle(dispatcher.HandleEvent)
+}
+
+// Submit function validates a given definition, registers it in the datastore together with an
+// initial result in which all its jobs are waiting, and adds it to the execution queue, all at
+// once (either everything is stored or nothing is). The execution itself is carried out later by
+// the workers. Takes as input the pointer to a Definition variable. Returns the pointer to the
+// initial ResultDefinition and an error variable to report any problems.
+func (c *Controller) Submit(definition *definitions.Definition) (*results.ResultDefinition, error) {
+
+	// Validate the definition against its specification and components, and its webhooks, before accepting it
+	nestedJobs, err := getJobs(definition, c.Datastore, c.Validator)
+	violations := c.checkWebhooks(definition)
+	if err := violations.Merge(err, ""); err != nil {
+		return nil, fmt.Errorf("error while trying to get jobs %w", err)
+	}
+	if err := violations.Err(); err != nil {
+		return nil, fmt.Errorf("error while trying to get jobs %w", err)
+	}
+
+	// Add the definition to the datastore, with the result that allows to consult its status while it
+	// is queued, and to the queue at once (as a bundle that only contains the definition)
+	resultDefinition := newResultDefinition(definition, nestedJobs)
+	if err := (*c.Datastore).AddBundle(&bundles.Bundle{Definition: definition}, nil, resultDefinition); err != nil {
+		return nil, fmt.Errorf("error while trying to insert the definition in the datastore %w", err)
+	}
+	c.signalQueue()
+	c.updateQueueDepth()
+
+	return resultDefinition, nil
+}
+
+// DryRun function validates a definition as Submit does and plans the jobs that its execution
+// would launch, without storing anything nor calling the executor. The violations of the structure
+// and of all the tasks of the definition are reported together. Takes as input the pointer to a
+// Definition variable. Returns the pointer to the two-dimensional array of Jobs in the order
+// established by the scheduler and an error variable to report any problems, which lists all the
+// violations of the definition if it is not valid.
+func (c *Controller) DryRun(definition *definitions.Definition) (*[][]jobs.Job, error) {
+	var violations validators.Violations
+
+	// We validate the structure of the definition
+	if err := c.Validator.ValidateDefinitionStruct(definition); err != nil && !addViolation(&violations, err, "") {
+		return nil, err
+	}
+
+	// We plan the jobs, collecting the violations of all the tasks and the webhooks
+	nestedJobs, err := getJobs(definition, c.Datastore, c.Validator)
+	if err := violations.Merge(err, ""); err != nil {
+		return nil, err
+	}
+	violations = append(violations, c.checkWebhooks(definition)...)
+
+	if err := violations.Err(); err != nil {
+		return nil, err
+	}
+	return nestedJobs, nil
+}
+
+// checkWebhooks function ensures that the webhooks of a definition can be notified, which requires a
+// dispatcher whose policy accepts their urls. It takes as input the pointer to the Definition. Returns the
+// violations of the webhooks.
+func (c *Controller) checkWebhooks(definition *definitions.Definition) validators.Violations {
+	var violations validators.Violations
+	for i, webhookUrl := range definition.Webhooks {
+		pointer := fmt.Sprintf("/webhooks/%d", i)
+		if c.Dispatcher == nil {
+			violations = append(violations, validators.Violation{Pointer: pointer, Code: validators.InvalidValueCode, Message: "webhooks are not enabled in this server"})
+		} else if err := c.Dispatcher.CheckUrl(webhookUrl); err != nil {
+			violations = append(violations, validators.Violation{Pointer: pointer, Code: validators.InvalidValueCode, Message: err.Error()})
+		}
+	}
+	return violations
+}
+
+// Retry function prepares a finished definition to be executed again from the point of failure.
+// The jobs that ended with an error or were cancelled are reset to waiting, while the completed
+// ones are kept, and the definition is added to the execution queue. Optionally, the parameters
+// of the failed tasks can be overridden; overrides are matched by task and parameter name and
+// the resulting definition is validated and stored before queuing it. The identifier and the trace
+// context of the request carried by the context are recorded in the definition, so that the logs and
+// the spans of the new run are correlated with the retry. Takes as input the context of the request, the namespace and the
+// identifier of the definition and the array of tasks whose parameters are overridden. Returns the
+// pointer to the ResultDefinition with the reset jobs and an error variable to report any problems.
+func (c *Controller) Retry(ctx context.Context, namespace string, definitionId string, overrides []definitions.DefinitionTask) (*results.ResultDefinition, error) {
+
+	// Only finished definitions can be retried
+	resultDefinition, err := (*c.Datastore).GetResultDefinition(namespace, definitionId)
+	if err != nil {
+		return nil, err
+	}
+	if resultDefinition.Status().Pending() {
+		return nil, &errors.InvalidStateErr{Type: "definition", Id: definitionId, Message: "is still pending execution"}
+	}
+	definition, err := (*c.Datastore).GetDefinition(namespace, definitionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// We collect the jobs that have to be executed again
+	failed := make(map[string]bool)
+	for _, resultJob := range resultDefinition.ResultJobs {
+		if resultJob.Status == results.Error || resultJob.Status == results.Cancelled {
+			failed[resultJob.Name] = true
+		}
+	}
+	if len(failed) == 0 {
+		return nil, &errors.InvalidStateErr{Type: "definition", Id: definitionId, Message: "has no failed jobs to retry"}
+	}
+
+	// We apply the overrides of the parameters, which are only allowed for the failed tasks (on a copy
+	// of the tasks, so that the stored definition is only modified if the result is valid)
+	definition.Data.Tasks = slices.Clone(definition.Data.Tasks)
+	for i, override := range overrides {
+		if !failed[override.Name] {
+			return nil, &errors.ValidationErr{Field: fmt.Sprintf("tasks[%d].name", i), Message: fmt.Sprintf("task %s has not failed, so its parameters cannot be overridden", override.Name)}
+		}
+		idx := slices.IndexFunc(definition.Data.Tasks, func(t definitions.DefinitionTask) bool { return t.Name == override.Name })
+		task := &definition.Data.Tasks[idx]
+		if task.Inputs, err = overrideParameters(task.Inputs, override.Inputs); err != nil {
+			return nil, prefixValidationField(err, fmt.Sprintf("tasks[%d].inputs", i))
+		}
+		if task.Outputs, err = overrideParameters(task.Outputs, override.Outputs); err != nil {
+			return nil, prefixValidationField(err, fmt.Sprintf("tasks[%d].outputs", i))
+		}
+	}
+	if len(overrides) > 0 {
+		if _, err := getJobs(definition, c.Datastore, c.Validator); err != nil {
+			return nil, fmt.Errorf("error while trying to get jobs %w", err)
+		}
+	}
+	requestId, traceparent := logging.RequestId(ctx), tracing.Traceparent(ctx)
+	if len(overrides) > 0 || requestId != definition.RequestId || traceparent != definition.Traceparent {
+		definition.RequestId = requestId
+		definition.Traceparent = traceparent
+		if err := (*c.Datastore).UpdateDefinition(definition); err != nil {
+			return nil, fmt.Errorf("error while trying to update the definition in the datastore %w", err)
+		}
+	}
+
+	// We reset the failed jobs
+	for i, resultJob := range resultDefinition.ResultJobs {
+		if !failed[resultJob.Name] {
+			continue
+		}
+		jobRes := results.ResultJob{Id: resultJob.Id, Name: resultJob.Name, Status: results.Waiting}
+		if err := (*c.Datastore).UpdateResultJob(&jobRes, namespace, definitionId); err != nil {
+			return nil, err
+		}
+		resultDefinition.ResultJobs[i] = jobRes
+	}
+
+	// Add definition to the queue (it can be invoked again even if it was cancelled, as its jobs are waiting again)
+	err = c.enqueue(namespace, definitionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultDefinition, nil
+}
+
+// overrideParameters function replaces the value of some parameters by the one set in the
+// overrides. It takes as input the array of parameters and the array of overrides. Returns the
+// resulting array of parameters and an error variable to report any problems, whose field is
+// relative to the array of overrides.
+func overrideParameters(parameters []definitions.Parameter, overrides []definitions.Parameter) ([]definitions.Parameter, error) {
+	res := slices.Clone(parameters)
+	for i, override := range overrides {
+		idx := slices.IndexFunc(res, func(p definitions.Parameter) bool { return p.Name == override.Name })
+		if idx == -1 {
+			return nil, &errors.ValidationErr{Field: fmt.Sprintf("[%d].name", i), Message: fmt.Sprintf("parameter %s is not present in the definition file", override.Name)}
+		}
+		res[idx].Value = override.Value
+	}
+	return res, nil
+}
+
+// enqueue function adds a definition to the execution queue and wakes up an idle worker (if all
+// of them are busy, the definition will wait in the queue). It takes as input the namespace and
+// the identifier of the definition and returns an error variable to report any problems.
+func (c *Controller) enqueue(namespace string, definitionId string) error {
+	err := (*c.Datastore).AddQueuedDefinition(namespace, definitionId)
+	if err != nil {
+		return fmt.Errorf("error while trying to queue the definition %w", err)
+	}
+
+	c.signalQueue()
+	c.updateQueueDepth()
+	return nil
+}
+
+// signalQueue function wakes up an idle worker after a definition has been queued (if all of them
+// are busy, the definition will wait in the queue).
+func (c *Controller) signalQueue() {
+	select {
+	case c.queueSignal <- struct{}{}:
+	default:
+	}
+}
+
+// updateQueueDepth function refreshes the metric of the number of queued definitions.
+func (c *Controller) updateQueueDepth() {
+	if depth, err := (*c.Datastore).CountQueuedDefinitions(); err == nil {
+		metrics.QueueDepth.Set(float64(depth))
+	}
+}
+
+// StartWorkers function launches the given number of workers in charge of draining the
+// execution queue. Each worker invokes the queued definitions one by one.
+func (c *Controller) StartWorkers(workers int) {
+	c.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go c.worker()
+	}
+}
+
+// worker function repeatedly extracts the oldest definition from the queue and invokes it. When
+// the queue is empty, it waits until a new definition is submitted or the poll interval elapses.
+// The worker exits when the controller is shut down.
+func (c *Controller) worker() {
+	defer c.workers.Done()
+	for {
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+
+		definition, err := (*c.Datastore).PopQueuedDefinition()
+		switch err.(type) {
+		case nil:
+			c.updateQueueDepth()
+			if _, err := c.Invoke(definition); err != nil {
+				logger := logging.FromContext(c.Logger, definitionContext(context.Background(), definition)).WithError(err)
+				var stateErr *errors.InvalidStateErr
+				if stderrors.As(err, &stateErr) {
+					logger.Info("the definition has been skipped")
+				} else {
+					logger.Error("the invocation of the definition has failed")
+				}
+			}
+		case *errors.EmptyQueueErr:
+			select {
+			case <-c.queueSignal:
+			case <-time.After(queuePollInterval):
+			case <-c.stop:
+				return
+			}
+		default:
+			c.Logger.WithError(err).Error("cannot extract a definition from the queue")
+			select {
+			case <-time.After(queuePollInterval):
+			case <-c.stop:
+				return
+			}
+		}
+	}
+}
+
+// Recover function resumes the definitions interrupted by a previous stop of the server. Every
+// definition with pending jobs that is neither queued nor being invoked is added to the execution
+// queue again; its jobs that were left running are reconciled with the executor when it is invoked.
+// Returns the number of queued definitions and an error variable to report any problems.
+func (c *Controller) Recover() (int, error) {
+	pendingDefinitions, err := (*c.Datastore).GetDefinitionsWithWaitings()
+	if err != nil {
+		return 0, fmt.Errorf("error while trying to get the pending definitions %w", err)
+	}
+
+	recovered := 0
+	for _, definition := range *pendingDefinitions {
+		c.mutex.Lock()
+		_, invoking := c.executions[definition.Id]
+		c.mutex.Unlock()
+		if invoking {
+			continue
+		}
+		queued, err := c.requeue(definition.Namespace, definition.Id)
+		if err != nil {
+			return recovered, err
+		}
+		if queued {
+			logging.FromContext(c.Logger, definitionContext(context.Background(), &definition)).Info("interrupted definition queued again")
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// Shutdown function stops the controller gracefully. The workers stop extracting definitions from
+// the queue and the function waits for the ongoing invocations until the context is done. The
+// invocations that have not ended by then are not cancelled, so that their jobs keep running in the
+// executor, but their definitions are queued again to be resumed by Recover on the next start. It
+// takes as input the context that limits the wait. Returns an error variable to report any problems
+// (the error of the context if some invocations had to be interrupted).
+func (c *Controller) Shutdown(ctx context.Context) error {
+
+	// We stop the workers
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	// We wait for the ongoing invocations
+	finished := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// We checkpoint the invocations that are still running
+	c.mutex.Lock()
+	running := make(map[string]string, len(c.executions))
+	for definitionId, exec := range c.executions {
+		running[definitionId] = exec.namespace
+	}
+	c.mutex.Unlock()
+	for definitionId, namespace := range running {
+		if _, err := c.requeue(namespace, definitionId); err != nil {
+			return err
+		}
+		c.Logger.WithFields(logrus.Fields{logging.NamespaceField: namespace, logging.DefinitionIdField: definitionId}).Warn("running definition queued again to be resumed on the next start")
+	}
+	return ctx.Err()
+}
+
+// requeue function adds a definition to the execution queue unless it is already queued. It takes as input the namespace and the identifier of the definition. Returns whether
+// the definition has been queued and an error variable to report any problems.
+func (c *Controller) requeue(namespace string, definitionId string) (bool, error) {
+	queued, err := (*c.Datastore).IsQueuedDefinition(namespace, definitionId)
+	if err != nil {
+		return false, fmt.Errorf("error while trying to check the queue %w", err)
+	}
+	if queued {
+		return false, nil
+	}
+	if err := c.enqueue(namespace, definitionId); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Invoke function is responsible for the complete execution of a given definition. Takes as input
@@ -32,41 +451,200 @@ type Controller struct {
 // error variable to report any problems.
 func (c *Controller) Invoke(definition *definitions.Definition) (*results.ResultDefinition, error) {
 
+	// Register the invocation so that it can be cancelled
+	ctx, err := c.startExecution(definition.Namespace, definition.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer c.endExecution(definition.Id)
+	metrics.DefinitionsStarted.Inc()
+
+	// The lines of the invocation and its jobs are correlated with the request and the definition
+	ctx = definitionContext(ctx, definition)
+	logger := logging.FromContext(c.Logger, ctx)
+	logger.Info("definition started")
+
+	// The spans of the invocation and its jobs belong to the trace of the request that submitted it
+	ctx, span := c.Tracer.Start(tracing.WithRemoteParent(ctx, definition.Traceparent), "Invoke")
+	span.SetAttribute(logging.NamespaceField, definition.Namespace)
+	span.SetAttribute(logging.DefinitionIdField, definition.Id)
+
+	// Whatever the outcome, the subscribers are notified when the invocation ends
+	defer c.Broker.Close(definition.Id)
+
 	// Get jobs in topological order thanks to the scheduler while simultaneously validating the tasks
 	// and parameters exposed in the definition (must be compatible with the corresponding specification).
+	_, jobsSpan := tracing.Start(ctx, "getJobs")
 	nestedJobs, err := getJobs(definition, c.Datastore, c.Validator)
+	jobsSpan.End(err)
 	if err != nil {
-		return nil, fmt.Errorf("error while trying to get jobs %s", err.Error())
+		metrics.DefinitionsFailed.Inc()
+		err = fmt.Errorf("error while trying to get jobs %w", err)
+		span.End(err)
+		return nil, err
 	}
 
 	// Get result definition or create a default one if it doesn't exist
-	resultDefinition, err := getOrDefaultResultDefinition(definition, c.Datastore, nestedJobs)
+	resultDefinition, err := getOrDefaultResultDefinition(definition, c.Datastore, nestedJobs, logger)
 	if err != nil {
-		return nil, fmt.Errorf("error getting result definition %s", err.Error())
+		metrics.DefinitionsFailed.Inc()
+		err = fmt.Errorf("error getting result definition %w", err)
+		span.End(err)
+		return nil, err
 	}
 
 	// Execute jobs
-	resultJobs, err := executeJobs(nestedJobs, c.Executor, resultDefinition, c.Datastore)
+	resultJobs, err := executeJobs(ctx, nestedJobs, c.Executor, resultDefinition, c.Datastore, c.Broker)
 	if err != nil {
-		return nil, fmt.Errorf("error during execution %s", err.Error())
+		metrics.DefinitionsFailed.Inc()
+		err = fmt.Errorf("error during execution %w", err)
+		span.End(err)
+		return nil, err
 	}
 	resultDefinition.ResultJobs = *resultJobs
+	metrics.DefinitionFinished(resultDefinition.Status())
+	logger.WithField("status", resultDefinition.Status().String()).Info("definition finished")
+	span.SetAttribute("status", resultDefinition.Status().String())
+	span.End(nil)
+
+	// Notify the webhooks if the definition has ended (a cancelled one is notified by Cancel)
+	c.Dispatcher.DefinitionFinished(resultDefinition)
 
 	// We return the pointer to the constructed result definition
 	return resultDefinition, nil
 }
 
+// Cancel function stops the execution of a definition. If the definition is being invoked, no
+// new group of jobs is started, the executor is asked to stop the running jobs and the function
+// waits until the invocation ends. Then, the definition is removed from the queue and all the jobs
+// that are still pending are marked as Cancelled with the given reason. The definition is not
+// invoked anymore, even if a worker had already extracted it from the queue. It takes as input the
+// namespace and the identifier of the definition and the reason for the cancellation. Returns the
+// pointer to the resulting ResultDefinition and an error variable to report any problems.
+func (c *Controller) Cancel(namespace string, definitionId string, reason string) (*results.ResultDefinition, error) {
+
+	// Only definitions with pending jobs can be cancelled
+	resultDefinition, err := (*c.Datastore).GetResultDefinition(namespace, definitionId)
+	if err != nil {
+		return nil, err
+	}
+	if !resultDefinition.Status().Pending() {
+		return nil, &errors.InvalidStateErr{Type: "definition", Id: definitionId, Message: "has already finished"}
+	}
+
+	// Stop the ongoing invocation (if any), passing it the reason, and wait for it. Then, the remaining
+	// jobs are marked as cancelled and the definition removed from the queue at once, while no other
+	// invocation can start, so that the workers see the stored cancellation and skip the definition.
+	c.mutex.Lock()
+	for {
+		exec, running := c.executions[definitionId]
+		if !running {
+			break
+		}
+		exec.reason = reason
+		c.mutex.Unlock()
+		exec.cancel()
+		<-exec.done
+		c.mutex.Lock()
+	}
+	resultDefinition, cancelled, err := (*c.Datastore).CancelResultDefinition(namespace, definitionId, "Cancelled: "+reason)
+	c.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	c.updateQueueDepth()
+	for _, jobRes := range cancelled {
+		c.Broker.Publish(events.Event{Namespace: namespace, DefinitionId: definitionId, ResultJob: jobRes})
+	}
+	c.Broker.Close(definitionId)
+
+	// Notify the webhooks, unless the invocation ended on its own and has already done so
+	if len(cancelled) > 0 {
+		c.Dispatcher.DefinitionFinished(resultDefinition)
+	}
+
+	return resultDefinition, nil
+}
+
+// startExecution function registers the invocation of a definition, unless its stored result shows
+// that it has been cancelled or has already finished. It takes as input the namespace and the identifier of the definition.
+// Returns the context that is cancelled when the execution must stop and an error variable to report
+// any problems.
+func (c *Controller) startExecution(namespace string, definitionId string) (context.Context, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// A definition without result has not been invoked yet, so it cannot have been cancelled
+	resultDefinition, err := (*c.Datastore).GetResultDefinition(namespace, definitionId)
+	if _, notFound := err.(*errors.ElementNotFoundErr); err != nil && !notFound {
+		return nil, err
+	}
+	if err == nil && len(resultDefinition.ResultJobs) > 0 {
+		switch status := resultDefinition.Status(); {
+		case status == results.Cancelled:
+			return nil, &errors.InvalidStateErr{Type: "definition", Id: definitionId, Message: "has been cancelled"}
+		case !status.Pending():
+			return nil, &errors.InvalidStateErr{Type: "definition", Id: definitionId, Message: "has already finished"}
+		}
+	}
+	if _, running := c.executions[definitionId]; running {
+		return nil, &errors.InvalidStateErr{Type: "definition", Id: definitionId, Message: "is already being executed"}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := &execution{namespace: namespace, cancel: cancel, done: make(chan struct{})}
+	c.executions[definitionId] = exec
+	return context.WithValue(ctx, executionKey{}, exec), nil
+}
+
+// cancelReason function returns the reason why the execution of a context has been cancelled, or
+// an empty string if it has not been cancelled by Cancel. It takes as input the context of the execution.
+func cancelReason(ctx context.Context) string {
+	exec, ok := ctx.Value(executionKey{}).(*execution)
+	if !ok || ctx.Err() == nil {
+		return ""
+	}
+	return exec.reason
+}
+
+// endExecution function forgets the invocation of a definition and notifies its end to those
+// waiting for it. It takes as input the identifier of the definition.
+func (c *Controller) endExecution(definitionId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	exec := c.executions[definitionId]
+	exec.cancel()
+	close(exec.done)
+	delete(c.executions, definitionId)
+}
+
+// definitionContext function returns a copy of the context that carries the correlation fields of a
+// definition: the request that submitted it, its namespace and its identifier. It takes as input the
+// context and the pointer of the Definition.
+func definitionContext(ctx context.Context, definition *definitions.Definition) context.Context {
+	return logging.WithFields(ctx, logrus.Fields{
+		logging.RequestIdField:    definition.RequestId,
+		logging.NamespaceField:    definition.Namespace,
+		logging.DefinitionIdField: definition.Id,
+	})
+}
+
 // getJobs function is responsible for extracting the jobs (minimum units of information for an execution)
 // in the order established by the scheduler. In addition, during the process it is in charge of validating
-// the consistency between the definition and the specification and components. It takes as input the pointer
-// of a Definition variable, the pointer of a Datastore variable and the pointer of a Validator variable. Finally,
-// it returns the pointer to a two-dimensional array of Jobs and an error variable to notify of any problem.
+// the consistency between the definition and the specification and components, collecting the violations of
+// all the tasks. It takes as input the pointer of a Definition variable, the pointer of a Datastore variable and
+// the pointer of a Validator variable. Finally, it returns the pointer to a two-dimensional array of Jobs and an
+// error variable to notify of any problem, which lists all the violations of the definition if it is not valid.
 func getJobs(definition *definitions.Definition, datastore *datastores.Datastore, validator *validators.Validator) (*[][]jobs.Job, error) {
+	var violations validators.Violations
 
 	// Obtain specification and planning, and validate the concordance between their tasks with respect to those recorded in the definition.
 	specification, planning, err := getAndCheckSpecPlanning(definition, datastore, validator)
 	if err != nil {
-		return nil, err
+		if !addViolation(&violations, err, "/specificationId") {
+			return nil, err
+		}
+		return nil, violations
 	}
 
 	// We build a two-dimensional vector to store the topologically ordered tasks with the necessary content for their definition.
@@ -84,7 +662,11 @@ func getJobs(definition *definitions.Definition, datastore *datastores.Datastore
 			// Obtain the work associated with the specified task and validate its parameters with respect to the established in the specification and components.
 			job, err := getAndCheckJob(definition, taskName, specification, datastore, validator)
 			if err != nil {
-				return nil, err
+				idxDefinitionTask := slices.IndexFunc(definition.Data.Tasks, func(t definitions.DefinitionTask) bool { return t.Name == taskName })
+				if !addViolation(&violations, err, fmt.Sprintf("/data/tasks/%d", idxDefinitionTask)) {
+					return nil, err
+				}
+				continue
 			}
 
 			// F. We add it to the group's task list
@@ -94,6 +676,9 @@ func getJobs(definition *definitions.Definition, datastore *datastores.Datastore
 		nestedJobs = append(nestedJobs, jobsGroup)
 	}
 
+	if err := violations.Err(); err != nil {
+		return nil, err
+	}
 	return &nestedJobs, nil
 }
 
@@ -104,12 +689,12 @@ func getJobs(definition *definitions.Definition, datastore *datastores.Datastore
 // the planning and an error variable to report any problems.
 func getAndCheckSpecPlanning(definition *definitions.Definition, datastore *datastores.Datastore, validator *validators.Validator) (*specifications.Specification, *[][]string, error) {
 
-	// We extract the associated specification and its topological order
-	specification, err := (*datastore).GetSpecification(definition.SpecificationId)
+	// We extract the associated specification and its topological order (from the namespace of the definition)
+	specification, err := (*datastore).GetSpecification(definition.Namespace, definition.SpecificationId)
 	if err != nil {
 		return nil, nil, err
 	}
-	planning, err := (*datastore).GetPlanning(definition.SpecificationId)
+	planning, err := (*datastore).GetPlanning(definition.Namespace, definition.SpecificationId)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -137,22 +722,29 @@ func getAndCheckJob(definition *definitions.Definition, taskName string, specifi
 	// B. We extract the task information from the specification struct (mainly to know the identifier of its component)
 	idxSpecificationTask := slices.IndexFunc(specification.Spec.Dag.Tasks, func(t specifications.SpecificationTask) bool { return t.Name == taskName })
 	specificationTask := specification.Spec.Dag.Tasks[idxSpecificationTask]
-	componentId := specificationTask.Component
 
-	// C. We extract the information about the task component
-	execComponent, err := (*datastore).GetComponent(componentId)
+	// C. We extract the information about the task component (from the namespace of the specification or the shared one)
+	componentNamespace, componentId, err := namespaces.ResolveComponent(specification.Namespace, specificationTask.Component)
+	if err != nil {
+		return nil, err
+	}
+	execComponent, err := (*datastore).GetComponent(componentNamespace, componentId)
 	if err != nil {
 		return nil, err
 	}
 
-	// D. We check that the parameters entered (inputs/outputs) in the definition file are correct
+	// D. We check that the parameters entered (inputs/outputs) in the definition file are correct, collecting the violations of both
+	var violations validators.Violations
 	inputValidatorErr := validator.ValidateDefinitionParameters(&definitionTask.Inputs, &execComponent.Inputs)
-	if inputValidatorErr != nil {
-		return nil, inputValidatorErr
+	if err := violations.Merge(inputValidatorErr, fmt.Sprintf("/data/tasks/%d/inputs", idxDefinitionTask)); err != nil {
+		return nil, err
 	}
 	outputValidatorErr := validator.ValidateDefinitionParameters(&definitionTask.Outputs, &execComponent.Outputs)
-	if outputValidatorErr != nil {
-		return nil, outputValidatorErr
+	if err := violations.Merge(outputValidatorErr, fmt.Sprintf("/data/tasks/%d/outputs", idxDefinitionTask)); err != nil {
+		return nil, err
+	}
+	if err := violations.Err(); err != nil {
+		return nil, err
 	}
 
 	// E. We create the definition task (job)
@@ -160,6 +752,7 @@ func getAndCheckJob(definition *definitions.Definition, taskName string, specifi
 		Id:           xid.New().String(),
 		Name:         taskName,
 		Image:        execComponent.ContainerImage,
+		Component:    execComponent.Id,
 		Arguments:    append(definitionTask.Inputs, definitionTask.Outputs...),
 		Dependencies: specificationTask.Dependencies,
 	}
@@ -167,43 +760,66 @@ func getAndCheckJob(definition *definitions.Definition, taskName string, specifi
 	return job, nil
 }
 
+// prefixValidationField function completes the field of a validation error whose path is relative
+// to a part of the overrides of a retry. It takes as input the error and the path of that part. Returns the
+// completed error.
+func prefixValidationField(err error, prefix string) error {
+	if validationErr, ok := err.(*errors.ValidationErr); ok {
+		validationErr.Field = prefix + validationErr.Field
+	}
+	return err
+}
+
+// addViolation function records an error among the violations of a definition if it is caused by the
+// definition itself: invalid values or a reference to an element that does not exist. It takes as input
+// the pointer to the array of violations, the error and the JSON pointer of the part of the definition
+// that causes it when the error does not report one. Returns whether the error has been recorded.
+func addViolation(violations *validators.Violations, err error, pointer string) bool {
+	var errViolations validators.Violations
+	var validationErr *errors.ValidationErr
+	var notFoundErr *errors.ElementNotFoundErr
+	switch {
+	case stderrors.As(err, &errViolations):
+		*violations = append(*violations, errViolations...)
+	case stderrors.As(err, &validationErr):
+		code := validationErr.Code
+		if code == "" {
+			code = validators.InvalidValueCode
+		}
+		*violations = append(*violations, validators.Violation{Pointer: pointer, Code: code, Message: validationErr.Message, Line: validationErr.Line})
+	case stderrors.As(err, &notFoundErr):
+		*violations = append(*violations, validators.Violation{Pointer: pointer, Code: validators.NotFoundCode, Message: notFoundErr.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
 // getOrDefaultResultDefinition function is responsible for downloading the execution result
 // recorded in the datastore for the specified definition. In case it has not been executed
 // before, it will not find any result in the datastore and will create a new one with the
 // default values. It takes as input the pointer of a Definition variable, the pointer of a
-// Datastore variable and the pointer of set of jobs in topological order. Returns the pointer
-// to the RestultDefinition variable and an error variable to report any problems.
-func getOrDefaultResultDefinition(definition *definitions.Definition, datastore *datastores.Datastore, nestedJobs *[][]jobs.Job) (*results.ResultDefinition, error) {
+// Datastore variable, the pointer of set of jobs in topological order and the logger. Returns the
+// pointer to the RestultDefinition variable and an error variable to report any problems.
+func getOrDefaultResultDefinition(definition *definitions.Definition, datastore *datastores.Datastore, nestedJobs *[][]jobs.Job, logger logrus.FieldLogger) (*results.ResultDefinition, error) {
 
 	// If the definition already has a result in the datastore we download it.
-	resultDefinition, err := (*datastore).GetResultDefinition(definition.Id)
+	resultDefinition, err := (*datastore).GetResultDefinition(definition.Namespace, definition.Id)
 
 	// Otherwise we create an empty one, set all its jobs to waiting and upload it to the datastore before starting the execution.
 	switch err.(type) {
 	case *errors.ElementNotFoundErr:
 		{
 			// We inform the user that a new result has been created in the datastore.
-			log.Printf(err.Error() + " A new document is created.")
+			logger.WithFields(logrus.Fields{logging.NamespaceField: definition.Namespace, logging.DefinitionIdField: definition.Id}).Debug("the definition has no result yet, a new one is created")
 
 			// Create empty result definition
-			resultDefinition = &results.ResultDefinition{
-				Id:              definition.Id,
-				Name:            definition.Name,
-				SpecificationId: definition.SpecificationId,
-				ResultJobs:      []results.ResultJob{},
-			}
-
-			// We instantiate all its works in a waiting state
-			for _, jobGroup := range *nestedJobs {
-				for _, job := range jobGroup {
-					resultDefinition.ResultJobs = append(resultDefinition.ResultJobs, results.ResultJob{Id: job.Id, Name: job.Name, Status: results.Waiting})
-				}
-			}
+			resultDefinition = newResultDefinition(definition, nestedJobs)
 
 			// We add the result definition to the datastore
 			err := (*datastore).AddResultDefinition(resultDefinition)
 			if err != nil {
-				return nil, fmt.Errorf("error during insertion into the datastore %s", err.Error())
+				return nil, fmt.Errorf("error during insertion into the datastore %w", err)
 			}
 		}
 	default:
@@ -216,13 +832,37 @@ func getOrDefaultResultDefinition(definition *definitions.Definition, datastore
 	return resultDefinition, nil
 }
 
+// newResultDefinition function creates the initial result of a definition, in which all its jobs
+// are waiting. It takes as input the pointer of a Definition variable and the pointer of set of jobs
+// in topological order. Returns the pointer to the ResultDefinition variable.
+func newResultDefinition(definition *definitions.Definition, nestedJobs *[][]jobs.Job) *results.ResultDefinition {
+	resultDefinition := &results.ResultDefinition{
+		Id:              definition.Id,
+		Namespace:       definition.Namespace,
+		Name:            definition.Name,
+		SpecificationId: definition.SpecificationId,
+		SubmittedBy:     definition.SubmittedBy,
+		ResultJobs:      []results.ResultJob{},
+	}
+
+	// We instantiate all its works in a waiting state
+	for _, jobGroup := range *nestedJobs {
+		for _, job := range jobGroup {
+			resultDefinition.ResultJobs = append(resultDefinition.ResultJobs, results.ResultJob{Id: job.Id, Name: job.Name, Status: results.Waiting})
+		}
+	}
+	return resultDefinition
+}
+
 // executeJobs function is responsible for executing the jobs in the order established in the
 // two-dimensional list. In addition, it stores real-time information in the datastore in order
-// to facilitate the resolution of cuts during execution. It takes as input the pointer of the
-// Jobs set, the pointer of an Executor variable, the pointer of a ResultDefinition variable and
-// the pointer of a Datastore variable. It returns the pointer to an array of ResultJob and an
-// error variable to report any problems.
-func executeJobs(nestedJobs *[][]jobs.Job, executor *executors.Executor, resultDefinition *results.ResultDefinition, datastore *datastores.Datastore) (*[]results.ResultJob, error) {
+// to facilitate the resolution of cuts during execution, and publishes each job transition in the
+// broker. When the context is cancelled, no new group is started and the running jobs are stopped
+// by the executor. It takes as input the context of the execution, the pointer of the Jobs set, the
+// pointer of an Executor variable, the pointer of a ResultDefinition variable, the pointer of a
+// Datastore variable and the pointer of a Broker variable. It returns the pointer to an array of
+// ResultJob and an error variable to report any problems.
+func executeJobs(ctx context.Context, nestedJobs *[][]jobs.Job, executor *executors.Executor, resultDefinition *results.ResultDefinition, datastore *datastores.Datastore, broker *events.Broker) (*[]results.ResultJob, error) {
 
 	// We create a map for storing the results of each job (local storage)
 	jobResults := make(map[string]results.ResultJob)
@@ -238,14 +878,24 @@ func executeJobs(nestedJobs *[][]jobs.Job, executor *executors.Executor, resultD
 	// For each group of tasks ...
 	for _, jobGroup := range *nestedJobs {
 
+		// If the execution has been cancelled, the remaining groups are not started
+		if ctx.Err() != nil {
+			break
+		}
+
 		// We create an error group to allow waiting for all tasks belonging to the group and collect any error
 		var errg errgroup.Group
 
 		// For each job in the group ...
 		for _, job := range jobGroup {
 
+			// Jobs that already have a result keep its identifier, so that the result is updated instead of duplicated.
+			if jobRes, exists := jobResults[job.Name]; exists && jobRes.Id != "" {
+				job.Id = jobRes.Id
+			}
+
 			// Verify that the job is pending execution and that none of its dependencies have been cancelled.
-			validForExecution, err := checkJobExecutionRequirements(&job, &jobResults, datastore, resultDefinition.Id)
+			validForExecution, err := checkJobExecutionRequirements(ctx, &job, &jobResults, datastore, broker, resultDefinition.Namespace, resultDefinition.Id)
 			if err != nil {
 				return nil, err
 			}
@@ -259,7 +909,7 @@ func executeJobs(nestedJobs *[][]jobs.Job, executor *executors.Executor, resultD
 			if validForExecution {
 				j := job
 				errg.Go(func() error {
-					return runAndUpdateStatus(executor, &j, mutex, &jobResults, datastore, resultDefinition.Id)
+					return runAndUpdateStatus(ctx, executor, &j, mutex, &jobResults, datastore, broker, resultDefinition.Namespace, resultDefinition.Id)
 				})
 			}
 		}
@@ -277,14 +927,14 @@ func executeJobs(nestedJobs *[][]jobs.Job, executor *executors.Executor, resultD
 
 // checkJobExecutionRequirements function checks that the job is pending execution and
 // that none of its dependencies have been cancelled. To do so, it takes as input the
-// pointer to a Job variable, the pointer to a ResultJob map, a pointer to a Datastore
-// variable and the id of the ResultDefinition. In the output it provides a boolean value
-// and an error variable to report any problems.
+// context of the execution, the pointer to a Job variable, the pointer to a ResultJob map, a pointer to a Datastore
+// variable, a pointer to a Broker variable and the namespace and id of the ResultDefinition.
+// In the output it provides a boolean value and an error variable to report any problems.
 
-func checkJobExecutionRequirements(job *jobs.Job, jobResults *map[string]results.ResultJob, datastore *datastores.Datastore, resultDefinitionId string) (bool, error) {
+func checkJobExecutionRequirements(ctx context.Context, job *jobs.Job, jobResults *map[string]results.ResultJob, datastore *datastores.Datastore, broker *events.Broker, namespace string, resultDefinitionId string) (bool, error) {
 
-	// If the job is not pending execution, it is ignored.
-	pending := (*jobResults)[job.Name].Status == results.Waiting
+	// If the job is not pending execution, it is ignored. Jobs that were left running by a cut are reconciled or executed again.
+	pending := (*jobResults)[job.Name].Status.Pending()
 
 	// If the job must be cancelled, it is ignored.
 	cancelled := false
@@ -305,11 +955,14 @@ func checkJobExecutionRequirements(job *jobs.Job, jobResults *map[string]results
 			(*jobResults)[job.Name] = jobRes
 
 			// Save result job in remote storage
-			err := (*datastore).UpdateResultJob(&jobRes, resultDefinitionId)
+			err := updateResultJob(ctx, &jobRes, datastore, namespace, resultDefinitionId)
 			if err != nil {
 				return false, err
 			}
 
+			// Notify the subscribers
+			broker.Publish(events.Event{Namespace: namespace, DefinitionId: resultDefinitionId, ResultJob: jobRes})
+
 			// If one of the dependencies has already failed, the search is stopped.
 			break
 		}
@@ -319,28 +972,120 @@ func checkJobExecutionRequirements(job *jobs.Job, jobResults *map[string]results
 }
 
 // runAndUpdateStatus function is responsible for calling the executor to run the job and then
-// update its status in the local variable and in the remote datastore. It takes as input the pointer
-// of an Executor variable, the pointer to a Job variable, the pointer to a sync.RWMutex variable, the
-// pointer to a ResultJob map, a pointer to a Datastore variable and the id of the ResultDefinition.
-// In the output it provides an error variable to report any problems.
-func runAndUpdateStatus(executor *executors.Executor, job *jobs.Job, mutex *sync.RWMutex, jobResults *map[string]results.ResultJob, datastore *datastores.Datastore, resultDefinitionId string) error {
-
-	// Execute job
-	jobRes, err := (*executor).ExecuteJob(job)
-	if err != nil {
+// update its status in the local variable and in the remote datastore. The job is marked as running
+// before calling the executor, and both transitions are published in the broker. A job that was left
+// running by a stop of the server is followed again if the executor implements executors.Reconciler,
+// and executed again otherwise (or if the executor no longer knows it). If the executor fails, the job
+// is recorded with the Error status and the error in its logs. It takes as input the
+// pointer of an Executor variable, the pointer to a Job variable, the pointer to a sync.RWMutex variable,
+// the pointer to a ResultJob map, a pointer to a Datastore variable, a pointer to a Broker variable and
+// the namespace and id of the ResultDefinition. The context allows the executor to stop the job. In the
+// output it provides an error variable to report any problems.
+func runAndUpdateStatus(ctx context.Context, executor *executors.Executor, job *jobs.Job, mutex *sync.RWMutex, jobResults *map[string]results.ResultJob, datastore *datastores.Datastore, broker *events.Broker, namespace string, resultDefinitionId string) error {
+
+	// Check whether the job was left running by a stop of the server
+	mutex.RLock()
+	resume := (*jobResults)[job.Name].Status == results.Running
+	mutex.RUnlock()
+
+	// Mark the job as running
+	if err := updateStatus(ctx, &results.ResultJob{Id: job.Id, Name: job.Name, Status: results.Running}, mutex, jobResults, datastore, broker, namespace, resultDefinitionId); err != nil {
 		return err
 	}
 
+	// Follow the job again if it was left running, otherwise execute it
+	start := time.Now()
+	var jobRes *results.ResultJob
+	var err error
+	if reconciler, ok := (*executor).(executors.Reconciler); ok && resume {
+		spanCtx, span := startJobSpan(ctx, "ReconcileJob", job)
+		jobRes, err = reconciler.ReconcileJob(spanCtx, job)
+		endJobSpan(span, jobRes, err)
+	}
+	if err == nil && jobRes == nil {
+		spanCtx, span := startJobSpan(ctx, "ExecuteJob", job)
+		jobRes, err = (*executor).ExecuteJob(spanCtx, job)
+		endJobSpan(span, jobRes, err)
+	}
+	if err != nil {
+
+		// Record the failure, so that the job does not stay running and the definition can be retried
+		executorErr := &errors.ExecutorErr{JobId: job.Id, JobName: job.Name, Err: err}
+		failed := results.ResultJob{Id: job.Id, Name: job.Name, Logs: err.Error(), Status: results.Error}
+		metrics.JobDuration.WithLabelValues(job.Component, failed.Status.String()).Observe(time.Since(start).Seconds())
+		if updateErr := updateStatus(ctx, &failed, mutex, jobResults, datastore, broker, namespace, resultDefinitionId); updateErr != nil {
+			return fmt.Errorf("%w (its status could not be recorded: %s)", executorErr, updateErr.Error())
+		}
+		return executorErr
+	}
+	metrics.JobDuration.WithLabelValues(job.Component, jobRes.Status.String()).Observe(time.Since(start).Seconds())
+
+	// Explain why the job has been stopped if the definition has been cancelled
+	if reason := cancelReason(ctx); jobRes.Status == results.Cancelled && reason != "" {
+		jobRes.Logs = strings.TrimPrefix(jobRes.Logs+"\nCancelled: "+reason, "\n")
+	}
+
+	// Record the result
+	return updateStatus(ctx, jobRes, mutex, jobResults, datastore, broker, namespace, resultDefinitionId)
+}
+
+// updateStatus function records the result of a job in the local variable and in the remote
+// datastore, and publishes it in the broker. It takes as input the context of the execution, the
+// pointer to the ResultJob, the pointer to a sync.RWMutex variable, the pointer to a ResultJob map, a
+// pointer to a Datastore variable, a pointer to a Broker variable and the namespace and id of the
+// ResultDefinition. In the output it provides an error variable to report any problems.
+func updateStatus(ctx context.Context, jobRes *results.ResultJob, mutex *sync.RWMutex, jobResults *map[string]results.ResultJob, datastore *datastores.Datastore, broker *events.Broker, namespace string, resultDefinitionId string) error {
+
 	// Save result in local storage (with control access)
 	mutex.Lock()
-	(*jobResults)[job.Name] = *jobRes
+	(*jobResults)[jobRes.Name] = *jobRes
 	mutex.Unlock()
 
 	// Save result in remote storage
-	updateErr := (*datastore).UpdateResultJob(jobRes, resultDefinitionId)
+	updateErr := updateResultJob(ctx, jobRes, datastore, namespace, resultDefinitionId)
 	if updateErr != nil {
 		return updateErr
 	}
 
+	// Notify the subscribers
+	broker.Publish(events.Event{Namespace: namespace, DefinitionId: resultDefinitionId, ResultJob: *jobRes})
+
 	return nil
 }
+
+// updateResultJob function records the result of a job in the datastore within a span of the
+// execution. It takes as input the context of the execution, the pointer to the ResultJob, the
+// pointer of the Datastore and the namespace and id of the ResultDefinition. In the output it
+// provides an error variable to report any problems.
+func updateResultJob(ctx context.Context, jobRes *results.ResultJob, datastore *datastores.Datastore, namespace string, resultDefinitionId string) error {
+	_, span := tracing.Start(ctx, "UpdateResultJob")
+	span.SetAttribute(logging.JobIdField, jobRes.Id)
+	span.SetAttribute("job", jobRes.Name)
+	span.SetAttribute("status", jobRes.Status.String())
+
+	err := (*datastore).UpdateResultJob(jobRes, namespace, resultDefinitionId)
+	span.End(err)
+	return err
+}
+
+// startJobSpan function starts the span of the run of a job by the executor, whose context allows
+// the executor to add the spans of its own steps. It takes as input the context of the execution,
+// the name of the span and the pointer of the Job. Returns the context of the span and the span.
+func startJobSpan(ctx context.Context, name string, job *jobs.Job) (context.Context, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, name)
+	span.SetAttribute(logging.JobIdField, job.Id)
+	span.SetAttribute("job", job.Name)
+	span.SetAttribute("component", job.Component)
+	span.SetAttribute("image", job.Image)
+	return ctx, span
+}
+
+// endJobSpan function ends the span of the run of a job, recording the status of its result. It
+// takes as input the span, the pointer of the ResultJob (nil if the run has failed) and the error of
+// the run.
+func endJobSpan(span *tracing.Span, jobRes *results.ResultJob, err error) {
+	if jobRes != nil {
+		span.SetAttribute("status", jobRes.Status.String())
+	}
+	span.End(err)
+}
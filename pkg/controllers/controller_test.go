@@ -1,22 +1,32 @@
 package controllers
 
 import (
+	"context"
 	"dag/hector/golang/module/pkg"
 	"dag/hector/golang/module/pkg/components"
 	"dag/hector/golang/module/pkg/datastores"
 	"dag/hector/golang/module/pkg/datastores/dbmock"
 	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/dispatchers"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/events"
 	"dag/hector/golang/module/pkg/executors"
 	"dag/hector/golang/module/pkg/executors/execmock"
 	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/logging"
 	"dag/hector/golang/module/pkg/results"
 	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tracing"
 	"dag/hector/golang/module/pkg/validators"
+	"dag/hector/golang/module/pkg/webhooks"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
@@ -26,7 +36,8 @@ func TestGetAndCheckSpecPlanning(t *testing.T) {
 
 	// Declare test specification
 	testSpecification := specifications.Specification{
-		Id: "Spec-ID",
+		Id:        "Spec-ID",
+		Namespace: "default",
 		Spec: specifications.Spec{
 			Dag: specifications.Dag{
 				Tasks: []specifications.SpecificationTask{
@@ -59,6 +70,7 @@ func TestGetAndCheckSpecPlanning(t *testing.T) {
 
 	// Declare test definitions
 	goodDefinition := definitions.Definition{
+		Namespace:       "default",
 		SpecificationId: "Spec-ID",
 		Data: definitions.Data{
 			Tasks: []definitions.DefinitionTask{
@@ -105,7 +117,7 @@ func TestGetAndCheckSpecPlanning(t *testing.T) {
 			definition:    &badDefinition1,
 			specification: nil,
 			planning:      nil,
-			err:           "specifications.Specification with id Bad-Spec-ID not found in database.",
+			err:           "specifications.Specification with id default/Bad-Spec-ID not found in database.",
 		},
 		{
 			definition:    &badDefinition2,
@@ -122,7 +134,7 @@ func TestGetAndCheckSpecPlanning(t *testing.T) {
 	datastore.AddSpecification(&testSpecification)
 
 	// Insert test planning
-	datastore.AddPlanning(&testPlanning, testSpecification.Id)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
 
 	// Create Validator
 	validator := validators.NewValidator()
@@ -161,8 +173,9 @@ func TestGetAndCheckJob(t *testing.T) {
 
 	// Declare test job
 	testJob := jobs.Job{
-		Name:  "A",
-		Image: "image/name",
+		Name:      "A",
+		Image:     "image/name",
+		Component: "Comp1-ID",
 		Arguments: []definitions.Parameter{
 			{
 				Name:  "input_1",
@@ -292,7 +305,7 @@ func TestGetAndCheckJob(t *testing.T) {
 	datastore.AddSpecification(&testSpecification)
 
 	// Insert test planning
-	datastore.AddPlanning(&testPlanning, testSpecification.Id)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
 
 	// Create Validator
 	validator := validators.NewValidator()
@@ -320,6 +333,54 @@ func TestGetAndCheckJob(t *testing.T) {
 
 }
 
+func TestGetAndCheckJobNamespaces(t *testing.T) {
+
+	// Create Datastore with a component in the namespace of the specification, another in the shared
+	// namespace and another in a foreign namespace
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&components.Component{Id: "Comp1-ID", Namespace: "team-a", ContainerImage: "image/own"})
+	datastore.AddComponent(&components.Component{Id: "Comp2-ID", Namespace: "shared", ContainerImage: "image/shared"})
+	datastore.AddComponent(&components.Component{Id: "Comp3-ID", Namespace: "team-b", ContainerImage: "image/foreign"})
+
+	definition := definitions.Definition{Namespace: "team-a", SpecificationId: "Spec-ID", Data: definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A"}}}}
+
+	// Classic tests variable
+	var tests = []struct {
+		component string
+		image     string
+		err       string
+	}{
+		{"Comp1-ID", "image/own", ""},
+		{"shared/Comp2-ID", "image/shared", ""},
+		{"Comp2-ID", "", "components.Component with id team-a/Comp2-ID not found in database."},
+		{"Comp3-ID", "", "components.Component with id team-a/Comp3-ID not found in database."},
+		{"team-b/Comp3-ID", "", "component team-b/Comp3-ID belongs to the namespace team-b, but only components of the namespace team-a or the shared namespace can be used"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			specification := specifications.Specification{
+				Id:        "Spec-ID",
+				Namespace: "team-a",
+				Spec:      specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{{Name: "A", Component: tt.component}}}},
+			}
+			job, err := getAndCheckJob(&definition, "A", &specification, &datastore, validators.NewValidator())
+			if err == nil {
+				if job.Image != tt.image {
+					t.Error("The job uses the image " + job.Image + " but want " + tt.image)
+				}
+				err = fmt.Errorf("")
+			}
+
+			if tt.err != err.Error() {
+				t.Error("The error obtained was not as expected. Got " + err.Error() + " but want " + tt.err)
+			}
+		})
+	}
+}
+
 func TestGetOrDefaultResultDefinition(t *testing.T) {
 
 	// Declare test definitions
@@ -405,7 +466,7 @@ func TestGetOrDefaultResultDefinition(t *testing.T) {
 
 		testname := "test_" + strconv.Itoa(i)
 		t.Run(testname, func(t *testing.T) {
-			resultDefinition, _ := getOrDefaultResultDefinition(tt.definition, &datastore, tt.nestedJobs)
+			resultDefinition, _ := getOrDefaultResultDefinition(tt.definition, &datastore, tt.nestedJobs, logging.Discard())
 
 			equal, message := pkg.DeepValueEqual(*resultDefinition, *tt.resultDefinition, true)
 			if !equal {
@@ -524,7 +585,7 @@ func TestCheckJobExecutionRequirements(t *testing.T) {
 
 		testname := "test_" + strconv.Itoa(i)
 		t.Run(testname, func(t *testing.T) {
-			validForExecution, err := checkJobExecutionRequirements(tt.job, &jobResults, &datastore, resultDefinition.Id)
+			validForExecution, err := checkJobExecutionRequirements(context.Background(), tt.job, &jobResults, &datastore, nil, resultDefinition.Namespace, resultDefinition.Id)
 
 			if err != nil {
 				t.Error("Unexpected error detected: " + err.Error())
@@ -534,7 +595,7 @@ func TestCheckJobExecutionRequirements(t *testing.T) {
 				if status := jobResults[tt.job.Name].Status; status != results.Cancelled {
 					t.Error("The status recorded in the local storage is not correct. Cancelled was expected but obtained " + fmt.Sprintf("%v", status))
 				}
-				resDef, _ := datastore.GetResultDefinition(resultDefinition.Id)
+				resDef, _ := datastore.GetResultDefinition(resultDefinition.Namespace, resultDefinition.Id)
 				idx := slices.IndexFunc(resDef.ResultJobs, func(rj results.ResultJob) bool { return rj.Id == tt.job.Id })
 				if status := resDef.ResultJobs[idx].Status; status != results.Cancelled {
 					t.Error("The status recorded in the remote storage is not correct. Cancelled was expected but obtained " + fmt.Sprintf("%v", status))
@@ -578,15 +639,861 @@ func TestRunAndUpdateStatus(t *testing.T) {
 	// Add result definition to the datastore
 	datastore.AddResultDefinition(&resultDefinition)
 
+	// Create Broker and subscribe to the result definition
+	broker := events.NewBroker()
+	subscription, _ := broker.Subscribe(resultDefinition.Id)
+
 	t.Run("test", func(t *testing.T) {
-		err := runAndUpdateStatus(&executor, &job, mutex, &jobResults, &datastore, resultDefinition.Id)
+		err := runAndUpdateStatus(context.Background(), &executor, &job, mutex, &jobResults, &datastore, broker, resultDefinition.Namespace, resultDefinition.Id)
 
 		if err != nil {
 			t.Error("Unexpected error detected: " + err.Error())
-		} else if jobResults[job.Name].Status == results.Waiting {
+		} else if jobResults[job.Name].Status.Pending() {
 			t.Error("The status registered in the local storage has not been updated")
-		} else if rd, _ := datastore.GetResultDefinition(resultDefinition.Id); rd.ResultJobs[0].Status == results.Waiting {
+		} else if rd, _ := datastore.GetResultDefinition(resultDefinition.Namespace, resultDefinition.Id); rd.ResultJobs[0].Status.Pending() {
 			t.Error("The status registered in the remote storage has not been updated")
+		} else if event := <-subscription; event.ResultJob.Status != results.Running {
+			t.Error("The first published transition must be Running but obtained " + event.ResultJob.Status.String())
+		} else if event := <-subscription; event.ResultJob.Status != jobResults[job.Name].Status {
+			t.Error("The last published transition does not match the result " + event.ResultJob.Status.String())
 		}
 	})
 }
+
+func TestSubmit(t *testing.T) {
+
+	// Declare test component
+	testComponent := components.Component{
+		Id: "Comp1-ID",
+		Inputs: []components.Put{
+			{
+				Name: "input_1",
+				Type: "string",
+			},
+		},
+		ContainerImage: "image/name",
+	}
+
+	// Declare test specification
+	testSpecification := specifications.Specification{
+		Id: "Spec-ID",
+		Spec: specifications.Spec{
+			Dag: specifications.Dag{
+				Tasks: []specifications.SpecificationTask{
+					{
+						Name:      "A",
+						Component: "Comp1-ID",
+					},
+					{
+						Name:      "B",
+						Component: "Comp1-ID",
+					},
+				},
+			},
+		},
+	}
+
+	// Declare test planning
+	testPlanning := [][]string{{"A", "B"}}
+
+	// Declare test definitions
+	goodDefinition := definitions.Definition{
+		Id:              "Good-Def-ID",
+		SpecificationId: "Spec-ID",
+		Data: definitions.Data{
+			Tasks: []definitions.DefinitionTask{
+				{
+					Name: "A",
+					Inputs: []definitions.Parameter{
+						{
+							Name:  "input_1",
+							Value: "Input string value",
+						},
+					},
+				},
+				{
+					Name: "B",
+					Inputs: []definitions.Parameter{
+						{
+							Name:  "input_1",
+							Value: "Input string value",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Both tasks are invalid, so both of them must be reported at once
+	badDefinition := definitions.Definition{
+		Id:              "Bad-Def-ID",
+		SpecificationId: "Spec-ID",
+		Data: definitions.Data{
+			Tasks: []definitions.DefinitionTask{
+				{
+					Name: "A",
+				},
+				{
+					Name: "B",
+					Inputs: []definitions.Parameter{
+						{
+							Name:  "input_1",
+							Value: 3,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	unknownSpecDefinition := definitions.Definition{
+		Id:              "Unknown-Spec-Def-ID",
+		SpecificationId: "Unknown-Spec-ID",
+		Data: definitions.Data{
+			Tasks: []definitions.DefinitionTask{
+				{
+					Name: "A",
+				},
+			},
+		},
+	}
+
+	// A definition whose result cannot be stored must not be stored either
+	orphanDefinition := goodDefinition
+	orphanDefinition.Id = "Orphan-Def-ID"
+
+	// Classic tests variable
+	var tests = []struct {
+		definition *definitions.Definition
+		queued     bool
+		err        string
+		violations []string
+	}{
+		{
+			definition: &goodDefinition,
+			queued:     true,
+			err:        "",
+		},
+		{
+			definition: &badDefinition,
+			queued:     false,
+			err:        "error while trying to get jobs parameter input_1 is required but is not present in the definition file; parameter input_1 has an invalid value in the definition file",
+			violations: []string{"/data/tasks/0/inputs missing_parameter", "/data/tasks/1/inputs/0/value invalid_type"},
+		},
+		{
+			definition: &unknownSpecDefinition,
+			queued:     false,
+			err:        "error while trying to get jobs specifications.Specification with id /Unknown-Spec-ID not found in database.",
+			violations: []string{"/specificationId not_found"},
+		},
+		{
+			definition: &orphanDefinition,
+			queued:     false,
+			err:        "error while trying to insert the definition in the datastore A results.ResultDefinition with id /Orphan-Def-ID is already stored in the database.",
+		},
+	}
+
+	// Create Datastore
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+
+	// Insert test component, specification and planning, and a result left without definition
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+	datastore.AddResultDefinition(&results.ResultDefinition{Id: orphanDefinition.Id})
+
+	// Create Controller (workers are not started, so the queue is not drained)
+	var executor executors.Executor = execmock.NewExecMock()
+	controller := NewController(&executor, nil, &datastore, validators.NewValidator())
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			resultDefinition, err := controller.Submit(tt.definition)
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+
+			var violations validators.Violations
+			var got []string
+			if stderrors.As(err, &violations) {
+				for _, violation := range violations {
+					got = append(got, violation.Pointer+" "+violation.Code)
+				}
+			}
+
+			if tt.err != err.Error() {
+				t.Error("The error obtained was not as expected. Got " + err.Error() + " but want " + tt.err)
+			} else if !slices.Equal(got, tt.violations) {
+				t.Errorf("The violations obtained were not as expected. Got %v but want %v", got, tt.violations)
+			} else if tt.queued {
+				if resultDefinition.ResultJobs[0].Status != results.Waiting {
+					t.Error("The jobs of a submitted definition must be waiting")
+				}
+				queuedDefinition, err := datastore.PopQueuedDefinition()
+				if err != nil {
+					t.Error("Unexpected error detected: " + err.Error())
+				} else if queuedDefinition.Id != tt.definition.Id {
+					t.Error("The queued definition is not the submitted one. Got " + queuedDefinition.Id + " but want " + tt.definition.Id)
+				}
+			} else if _, err := datastore.GetDefinition(tt.definition.Namespace, tt.definition.Id); err == nil {
+				t.Error("An invalid definition must not be stored in the datastore")
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+
+	// Declare a test specification with two consecutive tasks
+	testComponent := components.Component{Id: "Comp1-ID", Namespace: "default", ContainerImage: "image/name"}
+	testSpecification := specifications.Specification{
+		Id:        "Spec-ID",
+		Namespace: "default",
+		Spec: specifications.Spec{
+			Dag: specifications.Dag{
+				Tasks: []specifications.SpecificationTask{
+					{
+						Name:      "A",
+						Component: "Comp1-ID",
+					},
+					{
+						Name:         "B",
+						Dependencies: []string{"A"},
+						Component:    "Comp1-ID",
+					},
+				},
+			},
+		},
+	}
+	testPlanning := [][]string{{"A"}, {"B"}}
+	testDefinition := definitions.Definition{
+		Id:              "Def-ID",
+		Namespace:       "default",
+		SpecificationId: "Spec-ID",
+		Data: definitions.Data{
+			Tasks: []definitions.DefinitionTask{{Name: "A"}, {Name: "B"}},
+		},
+	}
+
+	// Create Datastore
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+
+	// Create Controller and submit the definition
+	var executor executors.Executor = execmock.NewExecMock()
+	controller := NewController(&executor, nil, &datastore, validators.NewValidator())
+	if _, err := controller.Submit(&testDefinition); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invoke the definition and wait until its first job is running
+	subscription, _ := controller.Broker.Subscribe(testDefinition.Id)
+	start := time.Now()
+	go controller.Invoke(&testDefinition)
+	if event := <-subscription; event.ResultJob.Status != results.Running {
+		t.Fatal("The first job should be running but obtained " + event.ResultJob.Status.String())
+	}
+
+	// Classic tests variable
+	var tests = []struct {
+		definitionId string
+		err          string
+	}{
+		{
+			definitionId: testDefinition.Id,
+			err:          "",
+		},
+		{
+			definitionId: testDefinition.Id,
+			err:          "The definition with id Def-ID has already finished.",
+		},
+		{
+			definitionId: "Unknown-ID",
+			err:          "results.ResultDefinition with id default/Unknown-ID not found in database.",
+		},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			resultDefinition, err := controller.Cancel(testDefinition.Namespace, tt.definitionId, "requested by the test")
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+
+			if tt.err != err.Error() {
+				t.Error("The error obtained was not as expected. Got " + err.Error() + " but want " + tt.err)
+			} else if tt.err == "" {
+				if elapsed := time.Since(start); elapsed >= 5*time.Second {
+					t.Error("The running job has not been stopped")
+				}
+				for _, resultJob := range resultDefinition.ResultJobs {
+					if resultJob.Status != results.Cancelled {
+						t.Error("The job " + resultJob.Name + " should be cancelled but obtained " + resultJob.Status.String())
+					}
+				}
+				idx := slices.IndexFunc(resultDefinition.ResultJobs, func(rj results.ResultJob) bool { return rj.Name == "B" })
+				if logs := resultDefinition.ResultJobs[idx].Logs; logs != "Cancelled: requested by the test" {
+					t.Error("The reason of the cancellation has not been recorded. Got " + logs)
+				}
+				idx = slices.IndexFunc(resultDefinition.ResultJobs, func(rj results.ResultJob) bool { return rj.Name == "A" })
+				if logs := resultDefinition.ResultJobs[idx].Logs; logs != "The job was stopped before completion\nCancelled: requested by the test" {
+					t.Error("The reason of the cancellation has not been passed to the running job. Got " + logs)
+				}
+			}
+		})
+	}
+}
+
+// countingExecutor simulates an executor that completes its jobs at once, counting how many of
+// them have been executed.
+type countingExecutor struct {
+	executed int32
+}
+
+func (ce *countingExecutor) ExecuteJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
+	atomic.AddInt32(&ce.executed, 1)
+	return &results.ResultJob{Id: job.Id, Name: job.Name, Status: results.Done}, nil
+}
+
+func TestCancelQueued(t *testing.T) {
+
+	// Declare a test specification with two consecutive tasks
+	testComponent := components.Component{Id: "Comp1-ID", Namespace: "default", ContainerImage: "image/name"}
+	testSpecification := specifications.Specification{
+		Id:        "Spec-ID",
+		Namespace: "default",
+		Spec: specifications.Spec{
+			Dag: specifications.Dag{
+				Tasks: []specifications.SpecificationTask{
+					{Name: "A", Component: "Comp1-ID"},
+					{Name: "B", Dependencies: []string{"A"}, Component: "Comp1-ID"},
+				},
+			},
+		},
+	}
+	testPlanning := [][]string{{"A"}, {"B"}}
+	testDefinition := definitions.Definition{
+		Id:              "Def-ID",
+		Namespace:       "default",
+		SpecificationId: "Spec-ID",
+		Data: definitions.Data{
+			Tasks: []definitions.DefinitionTask{{Name: "A"}, {Name: "B"}},
+		},
+	}
+
+	// Create Datastore
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+
+	// Create Controller (with webhooks, whose deliveries are only stored) and submit the definition
+	executor := &countingExecutor{}
+	var testExecutor executors.Executor = executor
+	controller := NewController(&testExecutor, nil, &datastore, validators.NewValidator())
+	dispatcher, _ := dispatchers.NewDispatcher(&datastore, "secret", []string{"http://webhooks.example.com"}, nil)
+	controller.UseDispatcher(dispatcher)
+	if _, err := controller.Submit(&testDefinition); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancel the queued definition
+	resultDefinition, err := controller.Cancel(testDefinition.Namespace, testDefinition.Id, "requested by the test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, resultJob := range resultDefinition.ResultJobs {
+		if resultJob.Status != results.Cancelled || resultJob.Logs != "Cancelled: requested by the test" {
+			t.Error("The job " + resultJob.Name + " should be cancelled by the test but obtained " + resultJob.Status.String() + " (" + resultJob.Logs + ")")
+		}
+	}
+	if queued, _ := datastore.IsQueuedDefinition(testDefinition.Namespace, testDefinition.Id); queued {
+		t.Error("The cancelled definition should have been removed from the queue")
+	}
+
+	// Neither the workers nor a worker that had already extracted the definition can invoke it
+	controller.StartWorkers(1)
+	time.Sleep(100 * time.Millisecond)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := controller.Shutdown(shutdownCtx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := controller.Invoke(&testDefinition); err == nil || err.Error() != "The definition with id Def-ID has been cancelled." {
+		t.Errorf("The invocation of a cancelled definition should be refused, but obtained %v", err)
+	}
+	if executed := atomic.LoadInt32(&executor.executed); executed != 0 {
+		t.Error("The cancelled definition should not run, but " + strconv.Itoa(int(executed)) + " jobs have been executed")
+	}
+
+	// The result is kept and the end of the definition is notified only once
+	resultDefinition, _ = datastore.GetResultDefinition(testDefinition.Namespace, testDefinition.Id)
+	for _, resultJob := range resultDefinition.ResultJobs {
+		if resultJob.Status != results.Cancelled {
+			t.Error("The job " + resultJob.Name + " should be cancelled but obtained " + resultJob.Status.String())
+		}
+	}
+	if err := dispatcher.Flush(shutdownCtx); err != nil {
+		t.Fatal(err)
+	}
+	finished := 0
+	for _, delivery := range datastore.(*dbmock.DBMock).DeliveryStructs {
+		if delivery.Event == webhooks.DefinitionEvent {
+			finished++
+		}
+	}
+	if finished != 1 {
+		t.Error("The end of the definition should be notified once, but obtained " + strconv.Itoa(finished) + " notifications")
+	}
+}
+
+func TestRetry(t *testing.T) {
+
+	// Declare test component (shared with all the namespaces) and specification (B depends on A and C depends on B)
+	testComponent := components.Component{
+		Id:             "Comp1-ID",
+		Namespace:      "shared",
+		Inputs:         []components.Put{{Name: "input_1", Type: "string"}},
+		ContainerImage: "image/name",
+	}
+	testSpecification := specifications.Specification{
+		Id:        "Spec-ID",
+		Namespace: "team-a",
+		Spec: specifications.Spec{
+			Dag: specifications.Dag{
+				Tasks: []specifications.SpecificationTask{
+					{Name: "A", Component: "shared/Comp1-ID"},
+					{Name: "B", Dependencies: []string{"A"}, Component: "shared/Comp1-ID"},
+					{Name: "C", Dependencies: []string{"B"}, Component: "shared/Comp1-ID"},
+				},
+			},
+		},
+	}
+	testPlanning := [][]string{{"A"}, {"B"}, {"C"}}
+
+	// Declare test definitions with their results
+	newDefinition := func(id string) definitions.Definition {
+		var tasks []definitions.DefinitionTask
+		for _, name := range []string{"A", "B", "C"} {
+			tasks = append(tasks, definitions.DefinitionTask{Name: name, Inputs: []definitions.Parameter{{Name: "input_1", Value: "value"}}})
+		}
+		return definitions.Definition{Id: id, Namespace: "team-a", SpecificationId: "Spec-ID", Data: definitions.Data{Tasks: tasks}}
+	}
+	failedResult := func(id string) results.ResultDefinition {
+		return results.ResultDefinition{Id: id, Namespace: "team-a", ResultJobs: []results.ResultJob{
+			{Id: "JA", Name: "A", Logs: "All right", Status: results.Done},
+			{Id: "JB", Name: "B", Logs: "File not found exception", Status: results.Error},
+			{Id: "JC", Name: "C", Logs: "Cancelled due to errors in its dependencies", Status: results.Cancelled},
+		}}
+	}
+
+	// Create Datastore
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+	for _, id := range []string{"Def-1", "Def-2", "Def-3", "Def-4"} {
+		definition, resultDefinition := newDefinition(id), failedResult(id)
+		datastore.AddDefinition(&definition)
+		datastore.AddResultDefinition(&resultDefinition)
+	}
+	pendingDefinition := newDefinition("Def-5")
+	datastore.AddDefinition(&pendingDefinition)
+	datastore.AddResultDefinition(&results.ResultDefinition{Id: "Def-5", Namespace: "team-a", ResultJobs: []results.ResultJob{{Id: "JA", Name: "A", Status: results.Waiting}}})
+
+	// Create Controller (workers are not started, so the queue is not drained)
+	var executor executors.Executor = execmock.NewExecMock()
+	controller := NewController(&executor, nil, &datastore, validators.NewValidator())
+
+	// Classic tests variable
+	var tests = []struct {
+		definitionId string
+		overrides    []definitions.DefinitionTask
+		value        interface{}
+		err          string
+	}{
+		{
+			definitionId: "Def-1",
+			overrides:    nil,
+			value:        "value",
+			err:          "",
+		},
+		{
+			definitionId: "Def-2",
+			overrides:    []definitions.DefinitionTask{{Name: "B", Inputs: []definitions.Parameter{{Name: "input_1", Value: "new value"}}}},
+			value:        "new value",
+			err:          "",
+		},
+		{
+			definitionId: "Def-3",
+			overrides:    []definitions.DefinitionTask{{Name: "A", Inputs: []definitions.Parameter{{Name: "input_1", Value: "new value"}}}},
+			err:          "task A has not failed, so its parameters cannot be overridden",
+		},
+		{
+			definitionId: "Def-4",
+			overrides:    []definitions.DefinitionTask{{Name: "B", Inputs: []definitions.Parameter{{Name: "input_1", Value: 3}}}},
+			err:          "error while trying to get jobs parameter input_1 has an invalid value in the definition file",
+		},
+		{
+			definitionId: "Def-5",
+			err:          "The definition with id Def-5 is still pending execution.",
+		},
+		{
+			definitionId: "Def-1",
+			err:          "The definition with id Def-1 is still pending execution.",
+		},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			resultDefinition, err := controller.Retry(context.Background(), "team-a", tt.definitionId, tt.overrides)
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+
+			if tt.err != err.Error() {
+				t.Error("The error obtained was not as expected. Got " + err.Error() + " but want " + tt.err)
+			} else if tt.err == "" {
+				for _, resultJob := range resultDefinition.ResultJobs {
+					want := results.Waiting
+					if resultJob.Name == "A" {
+						want = results.Done
+					}
+					if resultJob.Status != want {
+						t.Error("The job " + resultJob.Name + " should be " + want.String() + " but obtained " + resultJob.Status.String())
+					}
+				}
+				if queuedDefinition, err := datastore.PopQueuedDefinition(); err != nil || queuedDefinition.Id != tt.definitionId {
+					t.Error("The definition has not been queued again")
+				}
+				definition, _ := datastore.GetDefinition("team-a", tt.definitionId)
+				if value := definition.Data.Tasks[1].Inputs[0].Value; value != tt.value {
+					t.Error("The parameters of the failed task are not as expected. Got " + fmt.Sprintf("%v", value) + " but want " + fmt.Sprintf("%v", tt.value))
+				}
+			} else if definition, _ := datastore.GetDefinition("team-a", tt.definitionId); definition.Data.Tasks[1].Inputs[0].Value != "value" {
+				t.Error("The stored definition must not be modified by a rejected retry")
+			}
+		})
+	}
+}
+
+// reconcilingExecutor simulates an executor whose jobs survive a restart of the server. It only
+// knows the jobs whose identifiers are in known.
+type reconcilingExecutor struct {
+	known []string
+}
+
+func (re *reconcilingExecutor) ExecuteJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
+	return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: "executed", Status: results.Done}, nil
+}
+
+func (re *reconcilingExecutor) ReconcileJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
+	if !slices.Contains(re.known, job.Id) {
+		return nil, nil
+	}
+	return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: "reconciled", Status: results.Done}, nil
+}
+
+func TestRunAndUpdateStatusReconcile(t *testing.T) {
+
+	// Create Executor (it knows the job J1)
+	var executor executors.Executor = &reconcilingExecutor{known: []string{"J1"}}
+
+	// Classic tests variable
+	var tests = []struct {
+		job    jobs.Job
+		status results.Status
+		logs   string
+	}{
+		{jobs.Job{Id: "J1", Name: "NameJ1"}, results.Running, "reconciled"},
+		{jobs.Job{Id: "J2", Name: "NameJ2"}, results.Running, "executed"},
+		{jobs.Job{Id: "J1", Name: "NameJ1"}, results.Waiting, "executed"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			jobResults := map[string]results.ResultJob{tt.job.Name: {Id: tt.job.Id, Name: tt.job.Name, Status: tt.status}}
+			var datastore datastores.Datastore = dbmock.NewDBMock()
+			datastore.AddResultDefinition(&results.ResultDefinition{Id: "RD-ID", ResultJobs: maps.Values(jobResults)})
+
+			err := runAndUpdateStatus(context.Background(), &executor, &tt.job, &sync.RWMutex{}, &jobResults, &datastore, events.NewBroker(), "", "RD-ID")
+			if err != nil {
+				t.Error("Unexpected error detected: " + err.Error())
+			} else if logs := jobResults[tt.job.Name].Logs; logs != tt.logs {
+				t.Error("The job should have been " + tt.logs + " but obtained " + logs)
+			}
+		})
+	}
+}
+
+// failingExecutor simulates an executor that cannot run the jobs (e.g. when nomad cannot be reached).
+type failingExecutor struct{}
+
+func (fe *failingExecutor) ExecuteJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestRunAndUpdateStatusExecutorError(t *testing.T) {
+	var executor executors.Executor = &failingExecutor{}
+	job := jobs.Job{Id: "J1", Name: "NameJ1"}
+	jobResults := map[string]results.ResultJob{job.Name: {Id: job.Id, Name: job.Name, Status: results.Waiting}}
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddResultDefinition(&results.ResultDefinition{Id: "RD-ID", ResultJobs: maps.Values(jobResults)})
+
+	// The error of the executor is reported
+	err := runAndUpdateStatus(context.Background(), &executor, &job, &sync.RWMutex{}, &jobResults, &datastore, events.NewBroker(), "", "RD-ID")
+	var executorErr *errors.ExecutorErr
+	if !stderrors.As(err, &executorErr) {
+		t.Fatal("got ", err, ", want an executor error")
+	}
+
+	// And the job is not left running, neither in memory nor in the datastore
+	stored, _ := datastore.GetResultDefinition("", "RD-ID")
+	for _, resultJob := range []results.ResultJob{jobResults[job.Name], stored.ResultJobs[0]} {
+		if resultJob.Status != results.Error || resultJob.Logs != "connection refused" {
+			t.Error("got the job ", resultJob, ", want it failed with the error of the executor")
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+
+	// Declare a definition for each situation: interrupted while running, waiting in the queue and finished
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	for _, status := range []results.Status{results.Running, results.Waiting, results.Done} {
+		definition := definitions.Definition{Id: "Def-" + status.String(), Namespace: "default"}
+		datastore.AddDefinition(&definition)
+		datastore.AddResultDefinition(&results.ResultDefinition{Id: definition.Id, Namespace: "default", ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: status}}})
+	}
+	datastore.AddQueuedDefinition("default", "Def-Waiting")
+
+	// Create Controller (workers are not started, so the queue is not drained)
+	controller := NewController(nil, nil, &datastore, validators.NewValidator())
+
+	t.Run("test", func(t *testing.T) {
+		recovered, err := controller.Recover()
+		if err != nil {
+			t.Fatal("Unexpected error detected: " + err.Error())
+		}
+		if recovered != 1 {
+			t.Error("Only the interrupted definition should be queued but obtained " + strconv.Itoa(recovered))
+		}
+		for _, id := range []string{"Def-Waiting", "Def-Running"} {
+			if definition, err := datastore.PopQueuedDefinition(); err != nil || definition.Id != id {
+				t.Error("The definition " + id + " should be in the queue")
+			}
+		}
+		if _, err := datastore.PopQueuedDefinition(); err == nil {
+			t.Error("The finished definition must not be queued")
+		}
+	})
+}
+
+func TestShutdown(t *testing.T) {
+
+	// Declare a test specification with a single task
+	testComponent := components.Component{Id: "Comp1-ID", Namespace: "default", ContainerImage: "image/name"}
+	testSpecification := specifications.Specification{
+		Id:        "Spec-ID",
+		Namespace: "default",
+		Spec:      specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{{Name: "A", Component: "Comp1-ID"}}}},
+	}
+	testPlanning := [][]string{{"A"}}
+	testDefinition := definitions.Definition{
+		Id:              "Def-ID",
+		Namespace:       "default",
+		SpecificationId: "Spec-ID",
+		Data:            definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A"}}},
+	}
+
+	// Create Datastore
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+
+	// Create Controller, submit the definition and wait until its job is running
+	var executor executors.Executor = execmock.NewExecMock()
+	controller := NewController(&executor, nil, &datastore, validators.NewValidator())
+	subscription, _ := controller.Broker.Subscribe(testDefinition.Id)
+	if _, err := controller.Submit(&testDefinition); err != nil {
+		t.Fatal(err)
+	}
+	controller.StartWorkers(1)
+	if event := <-subscription; event.ResultJob.Status != results.Running {
+		t.Fatal("The job should be running but obtained " + event.ResultJob.Status.String())
+	}
+
+	t.Run("test", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if err := controller.Shutdown(ctx); err != context.DeadlineExceeded {
+			t.Error("The shutdown should report the interrupted invocation but obtained ", err)
+		}
+		if queued, _ := datastore.IsQueuedDefinition(testDefinition.Namespace, testDefinition.Id); !queued {
+			t.Error("The interrupted definition has not been queued again")
+		}
+		if resultDefinition, _ := datastore.GetResultDefinition(testDefinition.Namespace, testDefinition.Id); resultDefinition.ResultJobs[0].Status != results.Running {
+			t.Error("The running job must not be cancelled but obtained " + resultDefinition.ResultJobs[0].Status.String())
+		}
+
+		// Once the invocation ends, the workers exit without extracting the queued definition
+		if err := controller.Shutdown(context.Background()); err != nil {
+			t.Error("Unexpected error detected: " + err.Error())
+		}
+		if queued, _ := datastore.IsQueuedDefinition(testDefinition.Namespace, testDefinition.Id); !queued {
+			t.Error("The workers must not extract definitions after the shutdown")
+		}
+	})
+}
+
+// recordingExporter keeps the exported spans in memory.
+type recordingExporter struct {
+	mutex sync.Mutex
+	spans []*tracing.Span
+}
+
+func (re *recordingExporter) Export(span *tracing.Span) error {
+	re.mutex.Lock()
+	defer re.mutex.Unlock()
+	re.spans = append(re.spans, span)
+	return nil
+}
+
+func TestInvokeTracing(t *testing.T) {
+
+	// Declare a test specification with a single task, whose definition was submitted within a trace
+	testComponent := components.Component{Id: "Comp1-ID", Namespace: "default", ContainerImage: "image/name"}
+	testSpecification := specifications.Specification{
+		Id:        "Spec-ID",
+		Namespace: "default",
+		Spec:      specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{{Name: "A", Component: "Comp1-ID"}}}},
+	}
+	testPlanning := [][]string{{"A"}}
+	testDefinition := definitions.Definition{
+		Id:              "Def-ID",
+		Namespace:       "default",
+		SpecificationId: "Spec-ID",
+		Data:            definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A"}}},
+		Traceparent:     "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+
+	// Create Datastore
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+	datastore.AddDefinition(&testDefinition)
+
+	// Create Controller with a tracer
+	var executor executors.Executor = &reconcilingExecutor{}
+	controller := NewController(&executor, nil, &datastore, validators.NewValidator())
+	exporter := &recordingExporter{}
+	controller.Tracer = tracing.NewTracer(exporter)
+
+	t.Run("test", func(t *testing.T) {
+		if _, err := controller.Invoke(&testDefinition); err != nil {
+			t.Fatal("Unexpected error detected: " + err.Error())
+		}
+
+		// The spans are exported once they end, so the span of the invocation is the last one
+		var names []string
+		for _, span := range exporter.spans {
+			names = append(names, span.Name)
+		}
+		want := []string{"getJobs", "UpdateResultJob", "ExecuteJob", "UpdateResultJob", "Invoke"}
+		if !slices.Equal(names, want) {
+			t.Fatal(fmt.Sprintf("The spans should be %v but obtained %v", want, names))
+		}
+
+		// All of them belong to the trace of the request, under the span of the invocation
+		invoke := exporter.spans[len(exporter.spans)-1]
+		if invoke.ParentId != "b7ad6b7169203331" || invoke.Attributes["status"] != results.Done.String() {
+			t.Error("The invocation should be a child of the request span but obtained " + invoke.ParentId)
+		}
+		for _, span := range exporter.spans {
+			if span.TraceId != "0af7651916cd43dd8448eb211c80319c" {
+				t.Error("The span " + span.Name + " does not belong to the trace of the request")
+			}
+			if span != invoke && span.ParentId != invoke.SpanId {
+				t.Error("The span " + span.Name + " should be a child of the invocation")
+			}
+		}
+		if exporter.spans[2].Attributes["job"] != "A" || exporter.spans[2].Attributes["status"] != results.Done.String() {
+			t.Error(fmt.Sprintf("The span of the job has the attributes %v", exporter.spans[2].Attributes))
+		}
+	})
+}
+
+func TestDryRun(t *testing.T) {
+
+	// Declare a test specification whose second task references a component that does not exist
+	testComponent := components.Component{Id: "Comp1-ID", Namespace: "default", ContainerImage: "image/name", Inputs: []components.Put{{Name: "input_1", Type: "string"}}}
+	testSpecification := specifications.Specification{
+		Id:        "Spec-ID",
+		Namespace: "default",
+		Spec: specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{
+			{Name: "A", Component: "Comp1-ID"},
+			{Name: "B", Component: "Missing-ID", Dependencies: []string{"A"}},
+		}}},
+	}
+	testPlanning := [][]string{{"A"}, {"B"}}
+
+	// And another one whose task references a component of a foreign namespace
+	refSpecification := specifications.Specification{
+		Id:        "Ref-Spec-ID",
+		Namespace: "default",
+		Spec:      specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{{Name: "A", Component: "team-b/Comp1-ID"}}}},
+	}
+	refPlanning := [][]string{{"A"}}
+
+	// Create Datastore and Controller
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddComponent(&testComponent)
+	datastore.AddSpecification(&testSpecification)
+	datastore.AddPlanning(&testPlanning, testSpecification.Namespace, testSpecification.Id)
+	datastore.AddSpecification(&refSpecification)
+	datastore.AddPlanning(&refPlanning, refSpecification.Namespace, refSpecification.Id)
+	controller := NewController(nil, nil, &datastore, validators.NewValidator())
+
+	// Classic tests variable
+	var tests = []struct {
+		definition definitions.Definition
+		violations []string
+	}{
+		{definitions.Definition{Name: "Def", ApiVersion: "v1", SpecificationId: "Spec-ID", Namespace: "default", Data: definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A", Inputs: []definitions.Parameter{{Name: "input_1", Value: 1}}}, {Name: "B"}}}}, []string{"/data/tasks/0/inputs/0/value invalid_type", "/data/tasks/1 not_found"}},
+		{definitions.Definition{ApiVersion: "v1", SpecificationId: "Spec-ID", Namespace: "default", Data: definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A"}}}}, []string{"/name required", "/data/tasks missing_task"}},
+		{definitions.Definition{Name: "Def", ApiVersion: "v1", SpecificationId: "Other-ID", Namespace: "default"}, []string{"/specificationId not_found"}},
+		{definitions.Definition{Name: "Def", ApiVersion: "v1", SpecificationId: "Ref-Spec-ID", Namespace: "default", Data: definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A"}}}}, []string{"/data/tasks/0 invalid_reference"}},
+		{definitions.Definition{Name: "Def", ApiVersion: "v1", SpecificationId: "Spec-ID", Namespace: "default", Webhooks: []string{"https://example.com/hooks"}, Data: definitions.Data{Tasks: []definitions.DefinitionTask{{Name: "A", Inputs: []definitions.Parameter{{Name: "input_1", Value: "value"}}}, {Name: "B"}}}}, []string{"/data/tasks/1 not_found", "/webhooks/0 invalid_value"}},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			_, err := controller.DryRun(&tt.definition)
+			violations, ok := err.(validators.Violations)
+			if !ok {
+				t.Fatal(fmt.Sprintf("Some violations were expected but obtained %v", err))
+			}
+			var pointers []string
+			for _, violation := range violations {
+				pointers = append(pointers, violation.Pointer+" "+violation.Code)
+			}
+			if !slices.Equal(pointers, tt.violations) {
+				t.Error(fmt.Sprintf("The violations should be %v but obtained %v", tt.violations, pointers))
+			}
+		})
+	}
+}
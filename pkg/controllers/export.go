@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"dag/hector/golang/module/pkg/argo"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/specifications"
+)
+
+// PlanDefinition function resolves the jobs that a stored definition would run, validating it as if
+// it were going to be executed, so that the definition can be exported to other engines. Nothing is
+// executed nor stored. It takes as input the namespace and the identifier of the definition. Returns
+// the pointer to the Definition, the pointer to its Specification, the pointer to its jobs in
+// topological order and an error variable to report any problems.
+func (c *Controller) PlanDefinition(namespace string, definitionId string) (*definitions.Definition, *specifications.Specification, *[][]jobs.Job, error) {
+
+	// We extract the definition and its specification from the datastore
+	definition, err := (*c.Datastore).GetDefinition(namespace, definitionId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	specification, err := (*c.Datastore).GetSpecification(definition.Namespace, definition.SpecificationId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// We resolve the images and parameters of its jobs
+	nestedJobs, err := getJobs(definition, c.Datastore, c.Validator)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return definition, specification, nestedJobs, nil
+}
+
+// ExportArgo function renders a stored definition, together with its specification and components,
+// as an Argo Workflow. It takes as input the namespace and the identifier of the definition. Returns
+// the pointer to the Workflow and an error variable to report any problems.
+func (c *Controller) ExportArgo(namespace string, definitionId string) (*argo.Workflow, error) {
+	definition, specification, nestedJobs, err := c.PlanDefinition(namespace, definitionId)
+	if err != nil {
+		return nil, err
+	}
+	return argo.Export(definition, specification, nestedJobs), nil
+}
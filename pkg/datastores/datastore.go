@@ -1,25 +1,72 @@
 package datastores
 
 import (
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/components"
 	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/results"
 	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/webhooks"
+	"time"
 )
 
+// Datastore is the interface of the storages of the elements. All of them (except the tokens and
+// the webhook deliveries) belong to a namespace, so they are identified by their namespace and their id.
 type Datastore interface {
-	GetComponent(id string) (*components.Component, error)
-	GetSpecification(id string) (*specifications.Specification, error)
-	GetPlanning(id string) (*[][]string, error)
-	GetDefinition(id string) (*definitions.Definition, error)
-	GetResultDefinition(id string) (*results.ResultDefinition, error)
+	GetComponent(namespace string, id string) (*components.Component, error)
+	GetSpecification(namespace string, id string) (*specifications.Specification, error)
+	GetPlanning(namespace string, id string) (*[][]string, error)
+	GetDefinition(namespace string, id string) (*definitions.Definition, error)
+	GetResultDefinition(namespace string, id string) (*results.ResultDefinition, error)
+
+	ListComponents(namespace string, options *ListOptions) (*Page[components.Component], error)
+	ListSpecifications(namespace string, options *ListOptions) (*Page[specifications.Specification], error)
+	ListDefinitions(namespace string, options *ListOptions) (*Page[definitions.Definition], error)
+	ListResultDefinitions(namespace string, options *ListOptions) (*Page[results.ResultDefinition], error)
 
 	AddComponent(component *components.Component) error
 	AddSpecification(specification *specifications.Specification) error
-	AddPlanning(planning *[][]string, specificationId string) error
+	AddPlanning(planning *[][]string, namespace string, specificationId string) error
 	AddDefinition(definition *definitions.Definition) error
 	AddResultDefinition(resultDefinition *results.ResultDefinition) error
 
-	UpdateResultJob(resultJob *results.ResultJob, resultDefinitionId string) error
+	UpdateComponent(component *components.Component) error
+	UpdateSpecification(specification *specifications.Specification) error
+	UpdatePlanning(planning *[][]string, namespace string, specificationId string) error
+	UpdateDefinition(definition *definitions.Definition) error
+	UpdateResultJob(resultJob *results.ResultJob, namespace string, resultDefinitionId string) error
+
+	// UpdateSpecificationWithPlanning replaces a specification together with its planning, or none
+	// of them if any replacement fails.
+	UpdateSpecificationWithPlanning(specification *specifications.Specification, planning *[][]string) error
+
+	DeleteComponent(namespace string, id string) error
+	DeleteSpecification(namespace string, id string) error
 	GetDefinitionsWithWaitings() (*[]definitions.Definition, error)
+
+	AddQueuedDefinition(namespace string, definitionId string) error
+	IsQueuedDefinition(namespace string, definitionId string) (bool, error)
+	CountQueuedDefinitions() (int, error)
+	PopQueuedDefinition() (*definitions.Definition, error)
+
+	// CancelResultDefinition removes a definition from the execution queue and marks its pending jobs
+	// as Cancelled with the given logs in a single operation, so that no worker can extract it
+	// afterwards. It returns the resulting ResultDefinition and the jobs that have been cancelled.
+	CancelResultDefinition(namespace string, id string, logs string) (*results.ResultDefinition, []results.ResultJob, error)
+
+	// AddBundle stores all the elements of a bundle, or none of them if any insertion fails. The
+	// definition (if any) is also added to the execution queue.
+	AddBundle(bundle *bundles.Bundle, planning *[][]string, resultDefinition *results.ResultDefinition) error
+
+	AddToken(token *tokens.Token) error
+	GetTokenByHash(hash string) (*tokens.Token, error)
+	ListTokens(options *ListOptions) (*Page[tokens.Token], error)
+	DeleteToken(id string) error
+
+	AddDelivery(delivery *webhooks.Delivery) error
+	UpdateDelivery(delivery *webhooks.Delivery) error
+	DeleteDelivery(id string) error
+	ListPendingDeliveries() (*[]webhooks.Delivery, error)
+	DeleteFailedDeliveries(before time.Time) error
 }
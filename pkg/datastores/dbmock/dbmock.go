@@ -1,13 +1,21 @@
 package dbmock
 
 import (
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
 	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/namespaces"
 	"dag/hector/golang/module/pkg/results"
 	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/webhooks"
 	"fmt"
+	"strings"
+	"time"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
@@ -18,6 +26,9 @@ type DBMock struct {
 	PlanningOfSpecifications map[string][][]string
 	DefinitionStructs        []definitions.Definition
 	ResultDefinitionStructs  []results.ResultDefinition
+	QueuedDefinitionIds      []string // Qualified identifiers (namespace/id)
+	TokenStructs             []tokens.Token
+	DeliveryStructs          []webhooks.Delivery
 }
 
 // NewDBMock function creates a new instance of the DBMock type. It returns the pointer
@@ -28,78 +39,128 @@ func NewDBMock() *DBMock {
 	return &db
 }
 
-// GetComponent function extracts a concrete Component given its id. It takes as input
-// the identifier of the Component. It returns the pointer of the Component extracted
+// qualify function joins a namespace and an identifier (e.g. default/count-letters), which is
+// how the elements that are not structs are indexed.
+func qualify(namespace string, id string) string {
+	return namespace + namespaces.Separator + id
+}
+
+// filterNamespace function returns the elements that belong to the given namespace. It takes as
+// input the elements, the function that returns the namespace of an element and the namespace.
+func filterNamespace[V any](elements []V, namespaceOf func(V) string, namespace string) []V {
+	res := []V{}
+	for _, element := range elements {
+		if namespaceOf(element) == namespace {
+			res = append(res, element)
+		}
+	}
+	return res
+}
+
+// GetComponent function extracts a concrete Component given its namespace and id. It takes
+// as input the namespace and the identifier of the Component. It returns the pointer of the Component extracted
 // from the datastore and an error variable in charge of notifying any problem.
-func (dbm *DBMock) GetComponent(id string) (*components.Component, error) {
+func (dbm *DBMock) GetComponent(namespace string, id string) (*components.Component, error) {
 
-	idx := slices.IndexFunc(dbm.ComponentStructs, func(c components.Component) bool { return c.Id == id })
+	idx := slices.IndexFunc(dbm.ComponentStructs, func(c components.Component) bool { return c.Namespace == namespace && c.Id == id })
 	if idx == -1 {
-		return nil, &errors.ElementNotFoundErr{Type: "components.Component", Id: id}
+		return nil, &errors.ElementNotFoundErr{Type: "components.Component", Id: qualify(namespace, id)}
 	}
 	component := dbm.ComponentStructs[idx]
 	return &component, nil
 }
 
-// GetSpecification function extracts a concrete Specification given its id. It takes as input
-// the identifier of the Specification. It returns the pointer of the Specification extracted
+// GetSpecification function extracts a concrete Specification given its namespace and id. It takes
+// as input the namespace and the identifier of the Specification. It returns the pointer of the Specification extracted
 // from the datastore and an error variable in charge of notifying any problem.
-func (dbm *DBMock) GetSpecification(id string) (*specifications.Specification, error) {
+func (dbm *DBMock) GetSpecification(namespace string, id string) (*specifications.Specification, error) {
 
-	idx := slices.IndexFunc(dbm.SpecificationStructs, func(s specifications.Specification) bool { return s.Id == id })
+	idx := slices.IndexFunc(dbm.SpecificationStructs, func(s specifications.Specification) bool { return s.Namespace == namespace && s.Id == id })
 	if idx == -1 {
-		return nil, &errors.ElementNotFoundErr{Type: "specifications.Specification", Id: id}
+		return nil, &errors.ElementNotFoundErr{Type: "specifications.Specification", Id: qualify(namespace, id)}
 	}
 	specification := dbm.SpecificationStructs[idx]
 	return &specification, nil
 }
 
-// GetPlanning function extracts a concrete Planning given its id. It takes as input
-// the identifier of the Planning. It returns the pointer of the Planning extracted
+// GetPlanning function extracts a concrete Planning given its namespace and id. It takes
+// as input the namespace and the identifier of the Planning. It returns the pointer of the Planning extracted
 // from the datastore and an error variable in charge of notifying any problem.
-func (dbm *DBMock) GetPlanning(id string) (*[][]string, error) {
+func (dbm *DBMock) GetPlanning(namespace string, id string) (*[][]string, error) {
 
-	planning := dbm.PlanningOfSpecifications[id]
+	planning := dbm.PlanningOfSpecifications[qualify(namespace, id)]
 	if len(planning) == 0 {
-		return nil, &errors.ElementNotFoundErr{Type: "Planning", Id: id}
+		return nil, &errors.ElementNotFoundErr{Type: "Planning", Id: qualify(namespace, id)}
 	}
 	return &planning, nil
 }
 
-// GetDefinition function extracts a concrete Definition given its id. It takes as input
-// the identifier of the Definition. It returns the pointer of the Definition extracted
+// GetDefinition function extracts a concrete Definition given its namespace and id. It takes
+// as input the namespace and the identifier of the Definition. It returns the pointer of the Definition extracted
 // from the datastore and an error variable in charge of notifying any problem.
-func (dbm *DBMock) GetDefinition(id string) (*definitions.Definition, error) {
+func (dbm *DBMock) GetDefinition(namespace string, id string) (*definitions.Definition, error) {
 
-	idx := slices.IndexFunc(dbm.DefinitionStructs, func(d definitions.Definition) bool { return d.Id == id })
+	idx := slices.IndexFunc(dbm.DefinitionStructs, func(d definitions.Definition) bool { return d.Namespace == namespace && d.Id == id })
 	if idx == -1 {
-		return nil, &errors.ElementNotFoundErr{Type: "definitions.Definition", Id: id}
+		return nil, &errors.ElementNotFoundErr{Type: "definitions.Definition", Id: qualify(namespace, id)}
 	}
 	definition := dbm.DefinitionStructs[idx]
 	return &definition, nil
 }
 
-// GetResultDefinition function extracts a concrete ResultDefinition given its id. It takes as input
-// the identifier of the ResultDefinition. It returns the pointer of the ResultDefinition extracted
+// GetResultDefinition function extracts a concrete ResultDefinition given its namespace and id. It takes
+// as input the namespace and the identifier of the ResultDefinition. It returns the pointer of the ResultDefinition extracted
 // from the datastore and an error variable in charge of notifying any problem.
-func (dbm *DBMock) GetResultDefinition(id string) (*results.ResultDefinition, error) {
+func (dbm *DBMock) GetResultDefinition(namespace string, id string) (*results.ResultDefinition, error) {
 
-	idx := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool { return rd.Id == id })
+	idx := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool { return rd.Namespace == namespace && rd.Id == id })
 	if idx == -1 {
-		return nil, &errors.ElementNotFoundErr{Type: "results.ResultDefinition", Id: id}
+		return nil, &errors.ElementNotFoundErr{Type: "results.ResultDefinition", Id: qualify(namespace, id)}
 	}
 	resultDefinition := dbm.ResultDefinitionStructs[idx]
 	return &resultDefinition, nil
 }
 
+// ListComponents function extracts the page of Components of a namespace that match the given
+// options. It takes as input the namespace and the pointer of the listing options. It returns the
+// pointer of the resulting page and an error variable in charge of notifying any problem.
+func (dbm *DBMock) ListComponents(namespace string, options *datastores.ListOptions) (*datastores.Page[components.Component], error) {
+	elements := filterNamespace(dbm.ComponentStructs, func(c components.Component) string { return c.Namespace }, namespace)
+	return datastores.ListElements(elements, datastores.SummarizeComponent, options)
+}
+
+// ListSpecifications function extracts the page of Specifications of a namespace that match the given
+// options. It takes as input the namespace and the pointer of the listing options. It returns the
+// pointer of the resulting page and an error variable in charge of notifying any problem.
+func (dbm *DBMock) ListSpecifications(namespace string, options *datastores.ListOptions) (*datastores.Page[specifications.Specification], error) {
+	elements := filterNamespace(dbm.SpecificationStructs, func(s specifications.Specification) string { return s.Namespace }, namespace)
+	return datastores.ListElements(elements, datastores.SummarizeSpecification, options)
+}
+
+// ListDefinitions function extracts the page of Definitions of a namespace that match the given
+// options. It takes as input the namespace and the pointer of the listing options. It returns the
+// pointer of the resulting page and an error variable in charge of notifying any problem.
+func (dbm *DBMock) ListDefinitions(namespace string, options *datastores.ListOptions) (*datastores.Page[definitions.Definition], error) {
+	elements := filterNamespace(dbm.DefinitionStructs, func(d definitions.Definition) string { return d.Namespace }, namespace)
+	return datastores.ListElements(elements, datastores.SummarizeDefinition, options)
+}
+
+// ListResultDefinitions function extracts the page of ResultDefinitions of a namespace that match the given
+// options. It takes as input the namespace and the pointer of the listing options. It returns the
+// pointer of the resulting page and an error variable in charge of notifying any problem.
+func (dbm *DBMock) ListResultDefinitions(namespace string, options *datastores.ListOptions) (*datastores.Page[results.ResultDefinition], error) {
+	elements := filterNamespace(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) string { return rd.Namespace }, namespace)
+	return datastores.ListElements(elements, datastores.SummarizeResultDefinition, options)
+}
+
 // AddComponent function inserts a given Component into the datastore. It takes as input
 // the pointer of the Component to be registered. It provides as output an error variable
 // in charge of notifying any problem.
 func (dbm *DBMock) AddComponent(component *components.Component) error {
 
-	idx := slices.IndexFunc(dbm.ComponentStructs, func(c components.Component) bool { return c.Id == component.Id })
+	idx := slices.IndexFunc(dbm.ComponentStructs, func(c components.Component) bool { return c.Namespace == component.Namespace && c.Id == component.Id })
 	if idx != -1 {
-		return &errors.DuplicateIDErr{Type: "components.Component", Id: component.Id}
+		return &errors.DuplicateIDErr{Type: "components.Component", Id: qualify(component.Namespace, component.Id)}
 	}
 	dbm.ComponentStructs = append(dbm.ComponentStructs, *component)
 	return nil
@@ -110,23 +171,26 @@ func (dbm *DBMock) AddComponent(component *components.Component) error {
 // in charge of notifying any problem.
 func (dbm *DBMock) AddSpecification(specification *specifications.Specification) error {
 
-	idx := slices.IndexFunc(dbm.SpecificationStructs, func(s specifications.Specification) bool { return s.Id == specification.Id })
+	idx := slices.IndexFunc(dbm.SpecificationStructs, func(s specifications.Specification) bool {
+		return s.Namespace == specification.Namespace && s.Id == specification.Id
+	})
 	if idx != -1 {
-		return &errors.DuplicateIDErr{Type: "specifications.Specification", Id: specification.Id}
+		return &errors.DuplicateIDErr{Type: "specifications.Specification", Id: qualify(specification.Namespace, specification.Id)}
 	}
 	dbm.SpecificationStructs = append(dbm.SpecificationStructs, *specification)
 	return nil
 }
 
-// AddPlanning function inserts a given Planning into the datastore. It takes as input
-// the pointer of the Planning to be registered. It provides as output an error variable
+// AddPlanning function inserts a given Planning into the datastore. It takes as input the
+// pointer of the Planning to be registered and the namespace and identifier of its Specification. It provides as output an error variable
 // in charge of notifying any problem.
-func (dbm *DBMock) AddPlanning(planning *[][]string, specificationId string) error {
+func (dbm *DBMock) AddPlanning(planning *[][]string, namespace string, specificationId string) error {
 
-	if _, exists := dbm.PlanningOfSpecifications[specificationId]; exists {
-		return &errors.DuplicateIDErr{Type: "Planning", Id: specificationId}
+	id := qualify(namespace, specificationId)
+	if _, exists := dbm.PlanningOfSpecifications[id]; exists {
+		return &errors.DuplicateIDErr{Type: "Planning", Id: id}
 	}
-	dbm.PlanningOfSpecifications[specificationId] = *planning
+	dbm.PlanningOfSpecifications[id] = *planning
 	return nil
 }
 
@@ -135,9 +199,11 @@ func (dbm *DBMock) AddPlanning(planning *[][]string, specificationId string) err
 // in charge of notifying any problem.
 func (dbm *DBMock) AddDefinition(definition *definitions.Definition) error {
 
-	idx := slices.IndexFunc(dbm.DefinitionStructs, func(d definitions.Definition) bool { return d.Id == definition.Id })
+	idx := slices.IndexFunc(dbm.DefinitionStructs, func(d definitions.Definition) bool {
+		return d.Namespace == definition.Namespace && d.Id == definition.Id
+	})
 	if idx != -1 {
-		return &errors.DuplicateIDErr{Type: "definitions.Definition", Id: definition.Id}
+		return &errors.DuplicateIDErr{Type: "definitions.Definition", Id: qualify(definition.Namespace, definition.Id)}
 	}
 	dbm.DefinitionStructs = append(dbm.DefinitionStructs, *definition)
 	return nil
@@ -148,23 +214,101 @@ func (dbm *DBMock) AddDefinition(definition *definitions.Definition) error {
 // in charge of notifying any problem.
 func (dbm *DBMock) AddResultDefinition(resultDefinition *results.ResultDefinition) error {
 
-	idx := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool { return rd.Id == resultDefinition.Id })
+	idx := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool {
+		return rd.Namespace == resultDefinition.Namespace && rd.Id == resultDefinition.Id
+	})
 	if idx != -1 {
-		return &errors.DuplicateIDErr{Type: "results.ResultDefinition", Id: resultDefinition.Id}
+		return &errors.DuplicateIDErr{Type: "results.ResultDefinition", Id: qualify(resultDefinition.Namespace, resultDefinition.Id)}
 	}
 	dbm.ResultDefinitionStructs = append(dbm.ResultDefinitionStructs, *resultDefinition)
 	return nil
 }
 
+// UpdateComponent function replaces a given Component in the datastore. It takes as input the
+// pointer of the Component with the new content. It provides as output an error variable in
+// charge of notifying any problem.
+func (dbm *DBMock) UpdateComponent(component *components.Component) error {
+
+	idx := slices.IndexFunc(dbm.ComponentStructs, func(c components.Component) bool { return c.Namespace == component.Namespace && c.Id == component.Id })
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "components.Component", Id: qualify(component.Namespace, component.Id)}
+	}
+	dbm.ComponentStructs[idx] = *component
+	return nil
+}
+
+// UpdateSpecification function replaces a given Specification in the datastore. It takes as input
+// the pointer of the Specification with the new content. It provides as output an error variable
+// in charge of notifying any problem.
+func (dbm *DBMock) UpdateSpecification(specification *specifications.Specification) error {
+
+	idx := slices.IndexFunc(dbm.SpecificationStructs, func(s specifications.Specification) bool {
+		return s.Namespace == specification.Namespace && s.Id == specification.Id
+	})
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "specifications.Specification", Id: qualify(specification.Namespace, specification.Id)}
+	}
+	dbm.SpecificationStructs[idx] = *specification
+	return nil
+}
+
+// UpdatePlanning function replaces the Planning of a given Specification in the datastore. It takes
+// as input the pointer of the new Planning and the namespace and identifier of the Specification. It provides as
+// output an error variable in charge of notifying any problem.
+func (dbm *DBMock) UpdatePlanning(planning *[][]string, namespace string, specificationId string) error {
+
+	id := qualify(namespace, specificationId)
+	if _, exists := dbm.PlanningOfSpecifications[id]; !exists {
+		return &errors.ElementNotFoundErr{Type: "Planning", Id: id}
+	}
+	dbm.PlanningOfSpecifications[id] = *planning
+	return nil
+}
+
+// UpdateSpecificationWithPlanning function replaces a given Specification together with its Planning
+// in the datastore, or none of them if any of them does not exist. It takes as input the pointer of the
+// Specification with the new content and the pointer of the new Planning. It provides as output an
+// error variable in charge of notifying any problem.
+func (dbm *DBMock) UpdateSpecificationWithPlanning(specification *specifications.Specification, planning *[][]string) error {
+
+	if _, err := dbm.GetSpecification(specification.Namespace, specification.Id); err != nil {
+		return err
+	}
+	if _, err := dbm.GetPlanning(specification.Namespace, specification.Id); err != nil {
+		return err
+	}
+	if err := dbm.UpdatePlanning(planning, specification.Namespace, specification.Id); err != nil {
+		return err
+	}
+	return dbm.UpdateSpecification(specification)
+}
+
+// UpdateDefinition function replaces a given Definition in the datastore. It takes as input the
+// pointer of the Definition with the new content. It provides as output an error variable in
+// charge of notifying any problem.
+func (dbm *DBMock) UpdateDefinition(definition *definitions.Definition) error {
+
+	idx := slices.IndexFunc(dbm.DefinitionStructs, func(d definitions.Definition) bool {
+		return d.Namespace == definition.Namespace && d.Id == definition.Id
+	})
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "definitions.Definition", Id: qualify(definition.Namespace, definition.Id)}
+	}
+	dbm.DefinitionStructs[idx] = *definition
+	return nil
+}
+
 // UpdateResultJob function updates a given ResultJob in the datastore by modifying its content in
-// the relevant ResultDefinition. It takes as input the pointer of the ResultJob and the identifier
-// of the ResultDefinition to which it belongs. It provides as output an error variable in charge
+// the relevant ResultDefinition. It takes as input the pointer of the ResultJob and the namespace
+// and identifier of the ResultDefinition to which it belongs. It provides as output an error variable in charge
 // of notifying any problem.
-func (dbm *DBMock) UpdateResultJob(resultJob *results.ResultJob, resultDefinitionId string) error {
+func (dbm *DBMock) UpdateResultJob(resultJob *results.ResultJob, namespace string, resultDefinitionId string) error {
 
-	idxResultDefinition := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool { return rd.Id == resultDefinitionId })
+	idxResultDefinition := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool {
+		return rd.Namespace == namespace && rd.Id == resultDefinitionId
+	})
 	if idxResultDefinition == -1 {
-		return &errors.ElementNotFoundErr{Type: "results.ResultDefinition", Id: resultDefinitionId}
+		return &errors.ElementNotFoundErr{Type: "results.ResultDefinition", Id: qualify(namespace, resultDefinitionId)}
 	}
 	resultDefinition := dbm.ResultDefinitionStructs[idxResultDefinition]
 	idxResultJob := slices.IndexFunc(resultDefinition.ResultJobs, func(jobRes results.ResultJob) bool { return jobRes.Id == resultJob.Id })
@@ -178,6 +322,43 @@ func (dbm *DBMock) UpdateResultJob(resultJob *results.ResultJob, resultDefinitio
 	return nil
 }
 
+// DeleteComponent function removes a given Component from the datastore, as long as no Specification
+// references it (the Components of the shared namespace can be referenced from any namespace). It takes
+// as input the namespace and the identifier of the Component. It provides as output an error
+// variable in charge of notifying any problem.
+func (dbm *DBMock) DeleteComponent(namespace string, id string) error {
+
+	idx := slices.IndexFunc(dbm.ComponentStructs, func(c components.Component) bool { return c.Namespace == namespace && c.Id == id })
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "components.Component", Id: qualify(namespace, id)}
+	}
+	for _, spec := range dbm.SpecificationStructs {
+		idxTask := slices.IndexFunc(spec.Spec.Dag.Tasks, func(task specifications.SpecificationTask) bool {
+			refNamespace, refId, err := namespaces.ResolveComponent(spec.Namespace, task.Component)
+			return err == nil && refNamespace == namespace && refId == id
+		})
+		if idxTask != -1 {
+			return &errors.ReferencedElementErr{Type: "components.Component", Id: qualify(namespace, id), ReferencedByType: "specifications.Specification", ReferencedById: qualify(spec.Namespace, spec.Id)}
+		}
+	}
+	dbm.ComponentStructs = slices.Delete(dbm.ComponentStructs, idx, idx+1)
+	return nil
+}
+
+// DeleteSpecification function removes a given Specification and its Planning from the datastore.
+// It takes as input the namespace and the identifier of the Specification. It provides as output an
+// error variable in charge of notifying any problem.
+func (dbm *DBMock) DeleteSpecification(namespace string, id string) error {
+
+	idx := slices.IndexFunc(dbm.SpecificationStructs, func(s specifications.Specification) bool { return s.Namespace == namespace && s.Id == id })
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "specifications.Specification", Id: qualify(namespace, id)}
+	}
+	dbm.SpecificationStructs = slices.Delete(dbm.SpecificationStructs, idx, idx+1)
+	delete(dbm.PlanningOfSpecifications, qualify(namespace, id))
+	return nil
+}
+
 // GetDefinitionsWithWaitings returns those definitions where some of their tasks are pending
 // execution. Returns a pointer to the resulting list of definitions and an error variable in
 // charge of notifying any problem.
@@ -186,9 +367,9 @@ func (dbm *DBMock) GetDefinitionsWithWaitings() (*[]definitions.Definition, erro
 	var res []definitions.Definition
 
 	for _, resDef := range dbm.ResultDefinitionStructs {
-		idxSomeWaiting := slices.IndexFunc(resDef.ResultJobs, func(jobRes results.ResultJob) bool { return jobRes.Status == results.Waiting })
+		idxSomeWaiting := slices.IndexFunc(resDef.ResultJobs, func(jobRes results.ResultJob) bool { return jobRes.Status.Pending() })
 		if idxSomeWaiting != -1 {
-			def, err := dbm.GetDefinition(resDef.Id)
+			def, err := dbm.GetDefinition(resDef.Namespace, resDef.Id)
 			if err != nil {
 				return nil, fmt.Errorf("error during definition extraction %s", err.Error())
 			}
@@ -198,3 +379,219 @@ func (dbm *DBMock) GetDefinitionsWithWaitings() (*[]definitions.Definition, erro
 
 	return &res, nil
 }
+
+// AddQueuedDefinition function inserts the identifier of a given Definition at the end of the
+// execution queue. It takes as input the namespace and the identifier of the Definition. It provides
+// as output an error variable in charge of notifying any problem.
+func (dbm *DBMock) AddQueuedDefinition(namespace string, definitionId string) error {
+
+	dbm.QueuedDefinitionIds = append(dbm.QueuedDefinitionIds, qualify(namespace, definitionId))
+	return nil
+}
+
+// IsQueuedDefinition function reports whether a Definition is waiting in the execution queue. It
+// takes as input the namespace and the identifier of the Definition. It provides as output a
+// boolean value and an error variable in charge of notifying any problem.
+func (dbm *DBMock) IsQueuedDefinition(namespace string, definitionId string) (bool, error) {
+	return slices.Contains(dbm.QueuedDefinitionIds, qualify(namespace, definitionId)), nil
+}
+
+// CountQueuedDefinitions function returns the number of Definitions waiting in the execution queue
+// and an error variable in charge of notifying any problem.
+func (dbm *DBMock) CountQueuedDefinitions() (int, error) {
+	return len(dbm.QueuedDefinitionIds), nil
+}
+
+// PopQueuedDefinition function extracts the oldest Definition of the execution queue and removes
+// it from the queue. It returns the pointer of the Definition extracted from the datastore and an
+// error variable in charge of notifying any problem.
+func (dbm *DBMock) PopQueuedDefinition() (*definitions.Definition, error) {
+
+	if len(dbm.QueuedDefinitionIds) == 0 {
+		return nil, &errors.EmptyQueueErr{}
+	}
+	namespace, definitionId, _ := strings.Cut(dbm.QueuedDefinitionIds[0], namespaces.Separator)
+	dbm.QueuedDefinitionIds = dbm.QueuedDefinitionIds[1:]
+	return dbm.GetDefinition(namespace, definitionId)
+}
+
+// CancelResultDefinition function removes a given Definition from the execution queue and marks the
+// pending jobs of its ResultDefinition as Cancelled. It takes as input the namespace and the identifier
+// of the Definition and the logs recorded in the cancelled jobs. It returns the pointer of the resulting
+// ResultDefinition, the cancelled jobs and an error variable in charge of notifying any problem.
+func (dbm *DBMock) CancelResultDefinition(namespace string, id string, logs string) (*results.ResultDefinition, []results.ResultJob, error) {
+
+	idx := slices.IndexFunc(dbm.ResultDefinitionStructs, func(rd results.ResultDefinition) bool { return rd.Namespace == namespace && rd.Id == id })
+	if idx == -1 {
+		return nil, nil, &errors.ElementNotFoundErr{Type: "results.ResultDefinition", Id: qualify(namespace, id)}
+	}
+	if queuedIdx := slices.Index(dbm.QueuedDefinitionIds, qualify(namespace, id)); queuedIdx != -1 {
+		dbm.QueuedDefinitionIds = slices.Delete(dbm.QueuedDefinitionIds, queuedIdx, queuedIdx+1)
+	}
+
+	resultDefinition := dbm.ResultDefinitionStructs[idx]
+	resultDefinition.ResultJobs = slices.Clone(resultDefinition.ResultJobs)
+	cancelled := []results.ResultJob{}
+	for i, resultJob := range resultDefinition.ResultJobs {
+		if !resultJob.Status.Pending() {
+			continue
+		}
+		resultDefinition.ResultJobs[i] = results.ResultJob{Id: resultJob.Id, Name: resultJob.Name, Logs: logs, Status: results.Cancelled}
+		cancelled = append(cancelled, resultDefinition.ResultJobs[i])
+	}
+	dbm.ResultDefinitionStructs[idx] = resultDefinition
+	return &resultDefinition, cancelled, nil
+}
+
+// AddBundle function inserts the elements of a given Bundle into the datastore and adds its definition
+// (if any) to the execution queue. If any insertion fails, the datastore is restored to its previous
+// state. It takes as input the pointer of the Bundle, the pointer of the Planning of its specification
+// and the pointer of the ResultDefinition of its definition. It provides as output an error variable
+// in charge of notifying any problem.
+func (dbm *DBMock) AddBundle(bundle *bundles.Bundle, planning *[][]string, resultDefinition *results.ResultDefinition) error {
+
+	snapshot := *dbm
+	snapshot.PlanningOfSpecifications = maps.Clone(dbm.PlanningOfSpecifications)
+	if err := dbm.addBundle(bundle, planning, resultDefinition); err != nil {
+		*dbm = snapshot
+		return err
+	}
+	return nil
+}
+
+// addBundle function performs the insertions of AddBundle, stopping at the first one that fails.
+func (dbm *DBMock) addBundle(bundle *bundles.Bundle, planning *[][]string, resultDefinition *results.ResultDefinition) error {
+
+	for i := range bundle.Components {
+		if err := dbm.AddComponent(&bundle.Components[i]); err != nil {
+			return err
+		}
+	}
+	if specification := bundle.Specification; specification != nil {
+		if err := dbm.AddPlanning(planning, specification.Namespace, specification.Id); err != nil {
+			return err
+		}
+		if err := dbm.AddSpecification(specification); err != nil {
+			return err
+		}
+	}
+	if definition := bundle.Definition; definition != nil {
+		if err := dbm.AddDefinition(definition); err != nil {
+			return err
+		}
+		if err := dbm.AddResultDefinition(resultDefinition); err != nil {
+			return err
+		}
+		return dbm.AddQueuedDefinition(definition.Namespace, definition.Id)
+	}
+	return nil
+}
+
+// AddToken function inserts a given Token in the datastore. It takes as input the pointer of
+// the Token. It provides as output an error variable in charge of notifying any problem.
+func (dbm *DBMock) AddToken(token *tokens.Token) error {
+
+	idx := slices.IndexFunc(dbm.TokenStructs, func(t tokens.Token) bool { return t.Id == token.Id })
+	if idx != -1 {
+		return &errors.DuplicateIDErr{Type: "tokens.Token", Id: token.Id}
+	}
+	dbm.TokenStructs = append(dbm.TokenStructs, *token)
+	return nil
+}
+
+// GetTokenByHash function extracts the Token whose secret has the given hash. It takes as input
+// the hash. It returns the pointer of the Token extracted from the datastore and an error variable
+// in charge of notifying any problem.
+func (dbm *DBMock) GetTokenByHash(hash string) (*tokens.Token, error) {
+
+	idx := slices.IndexFunc(dbm.TokenStructs, func(t tokens.Token) bool { return t.Hash == hash })
+	if idx == -1 {
+		return nil, &errors.ElementNotFoundErr{Type: "tokens.Token", Id: "(hidden)"}
+	}
+	token := dbm.TokenStructs[idx]
+	return &token, nil
+}
+
+// ListTokens function extracts the page of Tokens that match the given options. It takes as input
+// the listing options. It returns the pointer of the resulting page and an error variable in
+// charge of notifying any problem.
+func (dbm *DBMock) ListTokens(options *datastores.ListOptions) (*datastores.Page[tokens.Token], error) {
+	return datastores.ListElements(dbm.TokenStructs, datastores.SummarizeToken, options)
+}
+
+// DeleteToken function removes a given Token from the datastore. It takes as input the identifier
+// of the Token. It provides as output an error variable in charge of notifying any problem.
+func (dbm *DBMock) DeleteToken(id string) error {
+
+	idx := slices.IndexFunc(dbm.TokenStructs, func(t tokens.Token) bool { return t.Id == id })
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "tokens.Token", Id: id}
+	}
+	dbm.TokenStructs = slices.Delete(dbm.TokenStructs, idx, idx+1)
+	return nil
+}
+
+// AddDelivery function inserts a given webhook Delivery in the datastore. It takes as input the
+// pointer of the Delivery. It provides as output an error variable in charge of notifying any problem.
+func (dbm *DBMock) AddDelivery(delivery *webhooks.Delivery) error {
+
+	idx := slices.IndexFunc(dbm.DeliveryStructs, func(d webhooks.Delivery) bool { return d.Id == delivery.Id })
+	if idx != -1 {
+		return &errors.DuplicateIDErr{Type: "webhooks.Delivery", Id: delivery.Id}
+	}
+	dbm.DeliveryStructs = append(dbm.DeliveryStructs, *delivery)
+	return nil
+}
+
+// UpdateDelivery function replaces a stored webhook Delivery. It takes as input the pointer of the
+// Delivery. It provides as output an error variable in charge of notifying any problem.
+func (dbm *DBMock) UpdateDelivery(delivery *webhooks.Delivery) error {
+
+	idx := slices.IndexFunc(dbm.DeliveryStructs, func(d webhooks.Delivery) bool { return d.Id == delivery.Id })
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "webhooks.Delivery", Id: delivery.Id}
+	}
+	dbm.DeliveryStructs[idx] = *delivery
+	return nil
+}
+
+// DeleteDelivery function removes a given webhook Delivery from the datastore. It takes as input the
+// identifier of the Delivery. It provides as output an error variable in charge of notifying any problem.
+func (dbm *DBMock) DeleteDelivery(id string) error {
+
+	idx := slices.IndexFunc(dbm.DeliveryStructs, func(d webhooks.Delivery) bool { return d.Id == id })
+	if idx == -1 {
+		return &errors.ElementNotFoundErr{Type: "webhooks.Delivery", Id: id}
+	}
+	dbm.DeliveryStructs = slices.Delete(dbm.DeliveryStructs, idx, idx+1)
+	return nil
+}
+
+// ListPendingDeliveries function extracts the webhook Deliveries that have not been sent yet and
+// have not exhausted their attempts. It provides as output the pointer of the slice of Deliveries
+// and an error variable in charge of notifying any problem.
+func (dbm *DBMock) ListPendingDeliveries() (*[]webhooks.Delivery, error) {
+
+	pending := []webhooks.Delivery{}
+	for _, delivery := range dbm.DeliveryStructs {
+		if !delivery.Failed {
+			pending = append(pending, delivery)
+		}
+	}
+	return &pending, nil
+}
+
+// DeleteFailedDeliveries function removes the webhook Deliveries that exhausted their attempts before
+// a given time. It takes as input the time. It provides as output an error variable in charge of
+// notifying any problem.
+func (dbm *DBMock) DeleteFailedDeliveries(before time.Time) error {
+
+	kept := []webhooks.Delivery{}
+	for _, delivery := range dbm.DeliveryStructs {
+		if !delivery.Failed || !delivery.FailedAt.Before(before) {
+			kept = append(kept, delivery)
+		}
+	}
+	dbm.DeliveryStructs = kept
+	return nil
+}
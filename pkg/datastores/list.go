@@ -0,0 +1,246 @@
+package datastores
+
+import (
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// We declare the limits applied to the size of the pages
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// We declare the fields by which the listings can be sorted
+const (
+	SortById   = "id"
+	SortByName = "name"
+)
+
+type ListOptions struct {
+	Cursor          string
+	Limit           int
+	SortBy          string
+	Descending      bool
+	NamePrefix      string
+	SpecificationId string
+	Status          *results.Status
+}
+
+type Page[V any] struct {
+	Items      []V    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Summary collects the fields of a stored element that can be used to filter and sort listings.
+type Summary struct {
+	Id              string
+	Name            string
+	SpecificationId string
+	Status          *results.Status
+}
+
+// cursor is the decoded content of the opaque cursor handed to the clients. It records the sort
+// key and the identifier of the last element of the previous page.
+type cursor struct {
+	Key string `json:"key"`
+	Id  string `json:"id"`
+}
+
+// SummarizeComponent function extracts the listing fields of a Component.
+func SummarizeComponent(component *components.Component) Summary {
+	return Summary{Id: component.Id, Name: component.Name}
+}
+
+// SummarizeSpecification function extracts the listing fields of a Specification.
+func SummarizeSpecification(specification *specifications.Specification) Summary {
+	return Summary{Id: specification.Id, Name: specification.Name}
+}
+
+// SummarizeDefinition function extracts the listing fields of a Definition.
+func SummarizeDefinition(definition *definitions.Definition) Summary {
+	return Summary{Id: definition.Id, Name: definition.Name, SpecificationId: definition.SpecificationId}
+}
+
+// SummarizeResultDefinition function extracts the listing fields of a ResultDefinition.
+func SummarizeResultDefinition(resultDefinition *results.ResultDefinition) Summary {
+	status := resultDefinition.Status()
+	return Summary{Id: resultDefinition.Id, Name: resultDefinition.Name, SpecificationId: resultDefinition.SpecificationId, Status: &status}
+}
+
+// SummarizeToken function extracts the listing fields of a Token.
+func SummarizeToken(token *tokens.Token) Summary {
+	return Summary{Id: token.Id, Name: token.Name}
+}
+
+// ListQuery contains the listing options once they have been checked, with the default values
+// applied and the cursor decoded, so that the datastores can select the page by themselves.
+type ListQuery struct {
+	Limit      int
+	SortBy     string
+	Descending bool
+	after      *cursor // Last element of the previous page (nil for the first page)
+	options    *ListOptions
+}
+
+// NewListQuery function checks the listing options and sets their default values. It takes as
+// input the listing options. Returns the pointer to the resulting query and an error variable to
+// report any problems.
+func NewListQuery(options *ListOptions) (*ListQuery, error) {
+	query := ListQuery{Limit: options.Limit, SortBy: options.SortBy, Descending: options.Descending, options: options}
+	if query.Limit == 0 {
+		query.Limit = DefaultListLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxListLimit {
+		return nil, &errors.InvalidRequestErr{Field: "limit", Message: fmt.Sprintf("the limit must be between 1 and %d", MaxListLimit)}
+	}
+	if query.SortBy == "" {
+		query.SortBy = SortById
+	}
+	if query.SortBy != SortById && query.SortBy != SortByName {
+		return nil, &errors.InvalidRequestErr{Field: "sort", Message: "elements cannot be sorted by " + query.SortBy}
+	}
+	if options.Cursor != "" {
+		after, err := decodeCursor(options.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.after = after
+	}
+	return &query, nil
+}
+
+// After function is applied to ListQuery variables and returns the sort key and the identifier of
+// the last element of the previous page, together with a bool that is false for the first page.
+func (q *ListQuery) After() (string, string, bool) {
+	if q.after == nil {
+		return "", "", false
+	}
+	return q.after.Key, q.after.Id, true
+}
+
+// SortKey function is applied to ListQuery variables and returns the value of the selected sort
+// field of an element. It takes as input the summary of the element.
+func (q *ListQuery) SortKey(summary *Summary) string {
+	if q.SortBy == SortByName {
+		return summary.Name
+	}
+	return summary.Id
+}
+
+// Matches function is applied to ListQuery variables and reports whether an element passes the
+// filters of the options. It takes as input the summary of the element.
+func (q *ListQuery) Matches(summary *Summary) bool {
+	if !strings.HasPrefix(summary.Name, q.options.NamePrefix) {
+		return false
+	}
+	if q.options.SpecificationId != "" && summary.SpecificationId != q.options.SpecificationId {
+		return false
+	}
+	if q.options.Status != nil && (summary.Status == nil || *summary.Status != *q.options.Status) {
+		return false
+	}
+	return true
+}
+
+// NewPage function builds the page of a listing. It takes as input the elements that follow the
+// cursor in the order of the query, which must include one more element than the limit if the
+// listing continues, the function that extracts their listing fields and the query. Returns the
+// pointer to the page, whose cursor points to the next one when there are more elements.
+func NewPage[V any](elements []V, summarize func(*V) Summary, query *ListQuery) *Page[V] {
+	page := Page[V]{Items: []V{}}
+	if len(elements) > query.Limit {
+		elements = elements[:query.Limit]
+		last := summarize(&elements[query.Limit-1])
+		page.NextCursor = encodeCursor(&cursor{Key: query.SortKey(&last), Id: last.Id})
+	}
+	page.Items = append(page.Items, elements...)
+	return &page
+}
+
+// ListElements function implements the listing procedure of the datastores that keep their elements
+// in memory. It filters the input elements according to the options, sorts them by the selected field
+// (using the identifier to break ties) and returns the page that follows the cursor. It takes as input
+// the elements, the function that extracts their listing fields and the listing options. Returns the
+// pointer to the resulting page and an error variable to report any problems.
+func ListElements[V any](elements []V, summarize func(*V) Summary, options *ListOptions) (*Page[V], error) {
+
+	// Check the options and set the default values
+	query, err := NewListQuery(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// We keep the elements that pass the filters together with their summaries
+	type entry struct {
+		element V
+		summary Summary
+	}
+	var entries []entry
+	for i := range elements {
+		summary := summarize(&elements[i])
+		if query.Matches(&summary) {
+			entries = append(entries, entry{element: elements[i], summary: summary})
+		}
+	}
+
+	// We sort the elements by the selected key and identifier (an element is never less than itself, so
+	// that the descending listings do not repeat the element of the cursor)
+	less := func(key1, id1, key2, id2 string) bool {
+		if key1 != key2 {
+			return (key1 < key2) != query.Descending
+		}
+		return id1 != id2 && (id1 < id2) != query.Descending
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return less(query.SortKey(&entries[i].summary), entries[i].summary.Id, query.SortKey(&entries[j].summary), entries[j].summary.Id)
+	})
+
+	// We skip the elements up to the cursor position
+	start := 0
+	if key, id, ok := query.After(); ok {
+		start = sort.Search(len(entries), func(i int) bool {
+			return less(key, id, query.SortKey(&entries[i].summary), entries[i].summary.Id)
+		})
+	}
+
+	// We build the page with the elements that follow the cursor (one more to know if there are more)
+	var following []V
+	for _, e := range entries[start:] {
+		if len(following) > query.Limit {
+			break
+		}
+		following = append(following, e.element)
+	}
+
+	return NewPage(following, summarize, query), nil
+}
+
+// encodeCursor function converts a cursor into the opaque string handed to the clients.
+func encodeCursor(c *cursor) string {
+	content, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+// decodeCursor function recovers the cursor from the opaque string provided by the clients. It
+// returns the pointer to the cursor and an error variable to report any problems.
+func decodeCursor(str string) (*cursor, error) {
+	content, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, &errors.InvalidRequestErr{Field: "cursor", Message: "invalid cursor " + str}
+	}
+	var c cursor
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, &errors.InvalidRequestErr{Field: "cursor", Message: "invalid cursor " + str}
+	}
+	return &c, nil
+}
@@ -0,0 +1,93 @@
+package datastores
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/results"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestListElements(t *testing.T) {
+
+	// Declare test result definitions
+	resultDefinitions := []results.ResultDefinition{
+		{Id: "RD-3", Name: "beta", SpecificationId: "Spec-1", ResultJobs: []results.ResultJob{{Status: results.Done}}},
+		{Id: "RD-1", Name: "alpha", SpecificationId: "Spec-1", ResultJobs: []results.ResultJob{{Status: results.Done}, {Status: results.Error}}},
+		{Id: "RD-2", Name: "alpha", SpecificationId: "Spec-2", ResultJobs: []results.ResultJob{{Status: results.Waiting}}},
+		{Id: "RD-4", Name: "gamma", SpecificationId: "Spec-2", ResultJobs: []results.ResultJob{{Status: results.Cancelled}}},
+	}
+
+	// Classic tests variable
+	var tests = []struct {
+		options *ListOptions
+		ids     []string
+		next    bool
+		err     string
+	}{
+		{
+			options: &ListOptions{},
+			ids:     []string{"RD-1", "RD-2", "RD-3", "RD-4"},
+		},
+		{
+			options: &ListOptions{Limit: 2},
+			ids:     []string{"RD-1", "RD-2"},
+			next:    true,
+		},
+		{
+			options: &ListOptions{Limit: 2, Cursor: encodeCursor(&cursor{Key: "RD-2", Id: "RD-2"})},
+			ids:     []string{"RD-3", "RD-4"},
+		},
+		{
+			options: &ListOptions{SortBy: SortByName, Descending: true},
+			ids:     []string{"RD-4", "RD-3", "RD-2", "RD-1"},
+		},
+		{
+			options: &ListOptions{Limit: 2, Descending: true, Cursor: encodeCursor(&cursor{Key: "RD-3", Id: "RD-3"})},
+			ids:     []string{"RD-2", "RD-1"},
+		},
+		{
+			options: &ListOptions{NamePrefix: "al", SpecificationId: "Spec-1"},
+			ids:     []string{"RD-1"},
+		},
+		{
+			options: &ListOptions{Status: pkg.Ptr(results.Waiting)},
+			ids:     []string{"RD-2"},
+		},
+		{
+			options: &ListOptions{SortBy: "logs"},
+			err:     "elements cannot be sorted by logs",
+		},
+		{
+			options: &ListOptions{Cursor: "bad cursor"},
+			err:     "invalid cursor bad cursor",
+		},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			page, err := ListElements(resultDefinitions, SummarizeResultDefinition, tt.options)
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+
+			if tt.err != err.Error() {
+				t.Error("The error obtained was not as expected. Got " + err.Error() + " but want " + tt.err)
+			} else if page != nil {
+				ids := []string{}
+				for _, item := range page.Items {
+					ids = append(ids, item.Id)
+				}
+				if !reflect.DeepEqual(ids, tt.ids) {
+					t.Error("got ", ids, ", want ", tt.ids)
+				}
+				if (page.NextCursor != "") != tt.next {
+					t.Error("Unexpected next cursor " + page.NextCursor)
+				}
+			}
+		})
+	}
+}
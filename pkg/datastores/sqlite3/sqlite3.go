@@ -1,15 +1,24 @@
 package sqlite3
 
 import (
+	"context"
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
 	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/metrics"
+	"dag/hector/golang/module/pkg/namespaces"
 	"dag/hector/golang/module/pkg/results"
 	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/webhooks"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"golang.org/x/exp/slices"
@@ -22,7 +31,7 @@ type SQLite3 struct {
 
 // Element is an interface that encompasses all the types collected in the datastore.
 type Element interface {
-	components.Component | specifications.Specification | [][]string | definitions.Definition | results.ResultDefinition
+	components.Component | specifications.Specification | [][]string | definitions.Definition | results.ResultDefinition | tokens.Token | webhooks.Delivery
 }
 
 // We declare all the prefixes of our table
@@ -34,40 +43,150 @@ const (
 	PlanningPrefix      Prefix = "plan-"
 	DefinitionPrefix    Prefix = "def-"
 	ResultDefPrefix     Prefix = "resdef-"
+	TokenPrefix         Prefix = "tok-"
+	DeliveryPrefix      Prefix = "hook-"
 )
 
-// We create a specific constructor for our problem
-func NewSQLite3() (*SQLite3, error) {
+// preparer is implemented by the database and by its transactions, so the same statements can be
+// executed inside and outside a transaction.
+type preparer interface {
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// listFields contains the paths of the listing fields in the stored content of an element type, since
+// their names depend on the tags of its struct.
+type listFields struct {
+	name            string
+	specificationId string
+}
+
+// We declare the paths of the element types with tagged fields and of the result definitions.
+var (
+	taggedFields = listFields{name: "$.name", specificationId: "$.specificationId"}
+	resultFields = listFields{name: "$.Name", specificationId: "$.SpecificationId"}
+)
+
+// key function builds the identifier under which an element of a namespace is stored (e.g.
+// comp-default/count-letters). It takes as input the prefix of the element type, the namespace
+// and the identifier of the element. Returns the key.
+func key(prefix Prefix, namespace string, id string) string {
+	return string(prefix) + namespace + namespaces.Separator + id
+}
+
+// DefaultPath is the database file used when no other path is configured.
+const DefaultPath = "hector.sqlite"
+
+// connectionOptions make the transactions take the write lock as soon as they begin (BEGIN IMMEDIATE),
+// so that concurrent read-modify-write transactions are serialized instead of overwriting each other,
+// and make them wait for the lock instead of failing at once.
+const connectionOptions = "_txlock=immediate&_busy_timeout=5000"
+
+// We create a specific constructor for our problem, which takes as input the path of the
+// database file (created if it does not exist)
+func NewSQLite3(path string) (*SQLite3, error) {
 	db := SQLite3{}
 
-	sql, err := sql.Open("sqlite3", "hector.sqlite")
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	sql, err := sql.Open("sqlite3", path+separator+connectionOptions)
 	if err != nil {
 		return nil, err
 	}
 
 	strCreate := `
         CREATE TABLE IF NOT EXISTS hector(id TEXT PRIMARY KEY, content TEXT);
+        CREATE TABLE IF NOT EXISTS queue(position INTEGER PRIMARY KEY AUTOINCREMENT, namespace TEXT, definitionId TEXT);
+        CREATE INDEX IF NOT EXISTS pending_deliveries ON hector(id) WHERE id LIKE 'hook-%' AND json_extract(content, '$.failed') = 0;
     `
 	_, err = sql.Exec(strCreate)
 	if err != nil {
 		return nil, err
 	}
 
+	// We move the elements stored before the namespaces existed to the default namespace
+	if err := migrateNamespaces(sql); err != nil {
+		return nil, fmt.Errorf("cannot migrate the database to namespaces: %w", err)
+	}
+
 	db.Backend = sql
 
 	return &db, nil
 }
 
-func genericGetFunction[V Element](dbsql *SQLite3, id string) (*V, error) {
+// namespacedPrefixes are the prefixes of the elements that belong to a namespace, together with the
+// JSON path of the namespace in their content (plannings do not record it).
+var namespacedPrefixes = []struct {
+	prefix Prefix
+	path   string
+}{
+	{ComponentPrefix, "$.namespace"},
+	{SpecificationPrefix, "$.namespace"},
+	{PlanningPrefix, ""},
+	{DefinitionPrefix, "$.namespace"},
+	{ResultDefPrefix, "$.Namespace"},
+}
+
+func migrateNamespaces(db *sql.DB) error {
 	/*
-	   Generic function for data extraction
+		Move the elements stored before the namespaces existed (whose keys do not contain the namespace,
+		e.g. comp-count-letters) and the queued definitions to the default namespace. It can be executed
+		any number of times, since it only modifies the elements that have not been migrated yet.
+	*/
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// We add the namespace to the queue if it was created without it
+	var hasNamespace bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM pragma_table_info('queue') WHERE name = 'namespace')`).Scan(&hasNamespace); err != nil {
+		return err
+	}
+	if !hasNamespace {
+		if _, err := tx.Exec(`ALTER TABLE queue ADD COLUMN namespace TEXT DEFAULT '` + namespaces.Default + `'`); err != nil {
+			return err
+		}
+	}
+
+	// We rewrite the keys without namespace (and the namespace recorded in their content)
+	for _, namespaced := range namespacedPrefixes {
+		content := `content`
+		if namespaced.path != "" {
+			content = `json_set(content, '` + namespaced.path + `', '` + namespaces.Default + `')`
+		}
+		strUpdate := `UPDATE hector SET id = ? || substr(id, ?), content = ` + content + ` WHERE id LIKE ? AND instr(substr(id, ?), ?) = 0`
+		start := len(namespaced.prefix) + 1
+		if _, err := tx.Exec(strUpdate, key(namespaced.prefix, namespaces.Default, ""), start, string(namespaced.prefix)+"%", start, namespaces.Separator); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (dbsql *SQLite3) Ping(ctx context.Context) error {
+	/*
+		Checks that the database can be reached
+	*/
+
+	return dbsql.Backend.PingContext(ctx)
+}
+
+func genericGetFunction[V Element](db preparer, id string) (*V, error) {
+	/*
+	   Generic function for data extraction (from the database or in a transaction)
 	*/
 
 	// Define the query
 	strSelect := `SELECT content FROM hector WHERE id=?`
 
 	// We prepare the request corresponding to the query
-	statement, err := dbsql.Backend.Prepare(strSelect)
+	statement, err := db.Prepare(strSelect)
 	if err != nil {
 		return nil, err
 	}
@@ -98,16 +217,16 @@ func genericGetFunction[V Element](dbsql *SQLite3, id string) (*V, error) {
 	return &emptyStruct, nil
 }
 
-func genericAddFunction[V Element](dbsql *SQLite3, id string, filledStructPointer *V) error {
+func genericAddFunction[V Element](db preparer, id string, filledStructPointer *V) error {
 	/*
-	   Generic function for data insertion
+	   Generic function for data insertion (in the database or in a transaction)
 	*/
 
 	// Define the query
 	strInsert := `INSERT INTO hector(id, content) VALUES(?, ?)`
 
 	// We prepare the request corresponding to the query
-	statement, err := dbsql.Backend.Prepare(strInsert)
+	statement, err := db.Prepare(strInsert)
 	if err != nil {
 		return err
 	}
@@ -138,93 +257,447 @@ func genericAddFunction[V Element](dbsql *SQLite3, id string, filledStructPointe
 	return nil
 }
 
-func (dbsql *SQLite3) GetComponent(id string) (*components.Component, error) {
+func genericUpdateFunction[V Element](db preparer, id string, filledStructPointer *V) error {
 	/*
-	   Performs a query to extract a component given its identifier
+	   Generic function for data replacement (in the database or in a transaction)
 	*/
 
-	return genericGetFunction[components.Component](dbsql, string(ComponentPrefix)+id)
+	// Define the query
+	strUpdate := `UPDATE hector SET content=? WHERE id=?`
+
+	// We prepare the request corresponding to the query
+	statement, err := db.Prepare(strUpdate)
+	if err != nil {
+		return err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// Convert struct to string
+	bytesStruct, _ := json.Marshal(*filledStructPointer)
+	strStruct := string(bytesStruct)
+
+	// We execute the request passing the corresponding data.
+	r, err := statement.Exec(strStruct, id)
+	if err != nil {
+		return err
+	}
+
+	// We confirm that a row has been affected in the table
+	i, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return &errors.ElementNotFoundErr{Type: reflect.TypeOf(*filledStructPointer).String(), Id: id}
+	}
+
+	// If everything went well, we do not return any errors.
+	return nil
 }
 
-func (dbsql *SQLite3) GetSpecification(id string) (*specifications.Specification, error) {
+func genericDeleteFunction[V Element](db preparer, id string) error {
 	/*
-	   Performs a query to extract a specification given its identifier
+	   Generic function for data removal
 	*/
 
-	return genericGetFunction[specifications.Specification](dbsql, string(SpecificationPrefix)+id)
+	// Define the query
+	strDelete := `DELETE FROM hector WHERE id=?`
+
+	// We prepare the request corresponding to the query
+	statement, err := db.Prepare(strDelete)
+	if err != nil {
+		return err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// We execute the request passing the corresponding data.
+	r, err := statement.Exec(id)
+	if err != nil {
+		return err
+	}
+
+	// We confirm that a row has been affected in the table
+	i, err := r.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		var emptyStruct V
+		return &errors.ElementNotFoundErr{Type: reflect.TypeOf(emptyStruct).String(), Id: id}
+	}
+
+	// If everything went well, we do not return any errors.
+	return nil
 }
 
-func (dbsql *SQLite3) GetPlanning(id string) (*[][]string, error) {
+func genericListFunction[V Element](db preparer, prefix string) ([]V, error) {
 	/*
-	   Performs a query to extract a planning given its identifier
+	   Generic function for the extraction of all the elements whose key starts with a prefix
 	*/
 
-	return genericGetFunction[[][]string](dbsql, string(PlanningPrefix)+id)
+	return genericQueryFunction[V](db, `SELECT content FROM hector WHERE id LIKE ?`, prefix+"%")
 }
 
-func (dbsql *SQLite3) GetDefinition(id string) (*definitions.Definition, error) {
+func genericQueryFunction[V Element](db preparer, strSelect string, args ...any) ([]V, error) {
 	/*
-	   Performs a query to extract a definition given its identifier
+	   Generic function for the extraction of the elements selected by a query (whose only column is the content)
 	*/
 
-	return genericGetFunction[definitions.Definition](dbsql, string(DefinitionPrefix)+id)
+	// We prepare the request corresponding to the query
+	statement, err := db.Prepare(strSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// We execute the request and since it will have more than one solution, we store the result in the variable rows.
+	rows, err := statement.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer rows.Close()
+
+	// We declare a slice of elements to store the results
+	elements := []V{}
+
+	// The Next method returns a bool, as long as it is true it will indicate that there is a next value to read.
+	for rows.Next() {
+
+		// We create an empty string to later store the resulting data
+		var content string
+
+		// We create an empty struct to later unmarshall data from content variable
+		var emptyStruct V
+
+		// We insert the output in the content variable.
+		if err := rows.Scan(&content); err != nil {
+			return nil, err
+		}
+
+		// Add the content to the empty struct
+		json.Unmarshal([]byte(content), &emptyStruct)
+
+		// We add the element to the slice we declared before.
+		elements = append(elements, emptyStruct)
+	}
+
+	return elements, rows.Err()
 }
 
-func (dbsql *SQLite3) GetResultDefinition(id string) (*results.ResultDefinition, error) {
+func genericPageFunction[V Element](dbsql *SQLite3, prefix string, fields listFields, summarize func(*V) datastores.Summary, options *datastores.ListOptions) (*datastores.Page[V], error) {
 	/*
-	   Performs a query to extract a result definition given its identifier
+	   Generic function for the extraction of a page of the elements whose key starts with a prefix. The filters, the
+	   order and the limit are applied by the query, so that only the elements of the page are read
 	*/
 
-	return genericGetFunction[results.ResultDefinition](dbsql, string(ResultDefPrefix)+id)
+	// We check the options
+	query, err := datastores.NewListQuery(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// We build the conditions of the filters stored in the content
+	name := "json_extract(content, '" + fields.name + "')"
+	conditions := []string{"id LIKE ?"}
+	args := []any{prefix + "%"}
+	if options.NamePrefix != "" {
+		conditions = append(conditions, "instr("+name+", ?) = 1")
+		args = append(args, options.NamePrefix)
+	}
+	if options.SpecificationId != "" {
+		conditions = append(conditions, "json_extract(content, '"+fields.specificationId+"') = ?")
+		args = append(args, options.SpecificationId)
+	}
+
+	// We build the order, using the key to break ties (it sorts as the identifier, since all of them share the prefix)
+	direction, comparison := "ASC", ">"
+	if query.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	order := "id " + direction
+	if query.SortBy == datastores.SortByName {
+		order = name + " " + direction + ", " + order
+	}
+
+	// We read the elements that follow the cursor. The status is not stored, so when the elements are filtered by it
+	// we keep reading batches until the page is full or there are no more elements
+	var following []V
+	afterKey, afterId, after := query.After()
+	for {
+		batchConditions := append([]string{}, conditions...)
+		batchArgs := append([]any{}, args...)
+		if after && query.SortBy == datastores.SortByName {
+			batchConditions = append(batchConditions, "("+name+" "+comparison+" ? OR ("+name+" = ? AND id "+comparison+" ?))")
+			batchArgs = append(batchArgs, afterKey, afterKey, prefix+afterId)
+		} else if after {
+			batchConditions = append(batchConditions, "id "+comparison+" ?")
+			batchArgs = append(batchArgs, prefix+afterId)
+		}
+		batchArgs = append(batchArgs, query.Limit+1)
+
+		batch, err := genericQueryFunction[V](dbsql.Backend, `SELECT content FROM hector WHERE `+strings.Join(batchConditions, " AND ")+` ORDER BY `+order+` LIMIT ?`, batchArgs...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			if summary := summarize(&batch[i]); query.Matches(&summary) {
+				following = append(following, batch[i])
+			}
+		}
+		if len(batch) <= query.Limit || len(following) > query.Limit {
+			break
+		}
+		last := summarize(&batch[len(batch)-1])
+		afterKey, afterId, after = query.SortKey(&last), last.Id, true
+	}
+
+	return datastores.NewPage(following, summarize, query), nil
+}
+
+func (dbsql *SQLite3) GetComponent(namespace string, id string) (*components.Component, error) {
+	/*
+	   Performs a query to extract a component given its namespace and identifier
+	*/
+	defer metrics.ObserveDatastore("GetComponent", time.Now())
+
+	return genericGetFunction[components.Component](dbsql.Backend, key(ComponentPrefix, namespace, id))
+}
+
+func (dbsql *SQLite3) GetSpecification(namespace string, id string) (*specifications.Specification, error) {
+	/*
+	   Performs a query to extract a specification given its namespace and identifier
+	*/
+	defer metrics.ObserveDatastore("GetSpecification", time.Now())
+
+	return genericGetFunction[specifications.Specification](dbsql.Backend, key(SpecificationPrefix, namespace, id))
+}
+
+func (dbsql *SQLite3) GetPlanning(namespace string, id string) (*[][]string, error) {
+	/*
+	   Performs a query to extract a planning given its namespace and identifier
+	*/
+	defer metrics.ObserveDatastore("GetPlanning", time.Now())
+
+	return genericGetFunction[[][]string](dbsql.Backend, key(PlanningPrefix, namespace, id))
+}
+
+func (dbsql *SQLite3) GetDefinition(namespace string, id string) (*definitions.Definition, error) {
+	/*
+	   Performs a query to extract a definition given its namespace and identifier
+	*/
+	defer metrics.ObserveDatastore("GetDefinition", time.Now())
+
+	return genericGetFunction[definitions.Definition](dbsql.Backend, key(DefinitionPrefix, namespace, id))
+}
+
+func (dbsql *SQLite3) GetResultDefinition(namespace string, id string) (*results.ResultDefinition, error) {
+	/*
+	   Performs a query to extract a result definition given its namespace and identifier
+	*/
+	defer metrics.ObserveDatastore("GetResultDefinition", time.Now())
+
+	return genericGetFunction[results.ResultDefinition](dbsql.Backend, key(ResultDefPrefix, namespace, id))
+}
+
+func (dbsql *SQLite3) ListComponents(namespace string, options *datastores.ListOptions) (*datastores.Page[components.Component], error) {
+	/*
+	   Lists the components of a namespace that match the given options
+	*/
+	defer metrics.ObserveDatastore("ListComponents", time.Now())
+
+	return genericPageFunction[components.Component](dbsql, key(ComponentPrefix, namespace, ""), taggedFields, datastores.SummarizeComponent, options)
+}
+
+func (dbsql *SQLite3) ListSpecifications(namespace string, options *datastores.ListOptions) (*datastores.Page[specifications.Specification], error) {
+	/*
+	   Lists the specifications of a namespace that match the given options
+	*/
+	defer metrics.ObserveDatastore("ListSpecifications", time.Now())
+
+	return genericPageFunction[specifications.Specification](dbsql, key(SpecificationPrefix, namespace, ""), taggedFields, datastores.SummarizeSpecification, options)
+}
+
+func (dbsql *SQLite3) ListDefinitions(namespace string, options *datastores.ListOptions) (*datastores.Page[definitions.Definition], error) {
+	/*
+	   Lists the definitions of a namespace that match the given options
+	*/
+	defer metrics.ObserveDatastore("ListDefinitions", time.Now())
+
+	return genericPageFunction[definitions.Definition](dbsql, key(DefinitionPrefix, namespace, ""), taggedFields, datastores.SummarizeDefinition, options)
+}
+
+func (dbsql *SQLite3) ListResultDefinitions(namespace string, options *datastores.ListOptions) (*datastores.Page[results.ResultDefinition], error) {
+	/*
+	   Lists the result definitions of a namespace that match the given options
+	*/
+	defer metrics.ObserveDatastore("ListResultDefinitions", time.Now())
+
+	return genericPageFunction[results.ResultDefinition](dbsql, key(ResultDefPrefix, namespace, ""), resultFields, datastores.SummarizeResultDefinition, options)
 }
 
 func (dbsql *SQLite3) AddComponent(componentPointer *components.Component) error {
 	/*
 	   Insert component in datastoreeeeee
 	*/
+	defer metrics.ObserveDatastore("AddComponent", time.Now())
 
-	return genericAddFunction(dbsql, string(ComponentPrefix)+(*componentPointer).Id, componentPointer)
+	return genericAddFunction(dbsql.Backend, key(ComponentPrefix, (*componentPointer).Namespace, (*componentPointer).Id), componentPointer)
 }
 
 func (dbsql *SQLite3) AddSpecification(specificationPointer *specifications.Specification) error {
 	/*
-	   Insert specification in datastoree
+	   Insert specification in datastoree (in a transaction, so that its components cannot be deleted
+	   while it is inserted)
 	*/
+	defer metrics.ObserveDatastore("AddSpecification", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return genericAddFunction(dbsql, string(SpecificationPrefix)+(*specificationPointer).Id, specificationPointer)
+	// We insert the specification once its components are found
+	if err := checkComponents(tx, specificationPointer); err != nil {
+		return err
+	}
+	if err := genericAddFunction(tx, key(SpecificationPrefix, (*specificationPointer).Namespace, (*specificationPointer).Id), specificationPointer); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (dbsql *SQLite3) AddPlanning(planningPointer *[][]string, specificationId string) error {
+func (dbsql *SQLite3) AddPlanning(planningPointer *[][]string, namespace string, specificationId string) error {
 	/*
 	   Insert planning in datastore
 	*/
+	defer metrics.ObserveDatastore("AddPlanning", time.Now())
 
-	return genericAddFunction(dbsql, string(PlanningPrefix)+specificationId, planningPointer)
+	return genericAddFunction(dbsql.Backend, key(PlanningPrefix, namespace, specificationId), planningPointer)
 }
 
 func (dbsql *SQLite3) AddDefinition(definitionPointer *definitions.Definition) error {
 	/*
 	   Insert definition in datastore
 	*/
+	defer metrics.ObserveDatastore("AddDefinition", time.Now())
 
-	return genericAddFunction(dbsql, string(DefinitionPrefix)+(*definitionPointer).Id, definitionPointer)
+	return genericAddFunction(dbsql.Backend, key(DefinitionPrefix, (*definitionPointer).Namespace, (*definitionPointer).Id), definitionPointer)
 }
 
 func (dbsql *SQLite3) AddResultDefinition(resultDefinitionPointer *results.ResultDefinition) error {
 	/*
 	   Insert result definition in datastoreee
 	*/
+	defer metrics.ObserveDatastore("AddResultDefinition", time.Now())
+
+	return genericAddFunction(dbsql.Backend, key(ResultDefPrefix, (*resultDefinitionPointer).Namespace, (*resultDefinitionPointer).Id), resultDefinitionPointer)
+}
+
+func (dbsql *SQLite3) UpdateComponent(componentPointer *components.Component) error {
+	/*
+	   Replace component in datastore
+	*/
+	defer metrics.ObserveDatastore("UpdateComponent", time.Now())
+
+	return genericUpdateFunction(dbsql.Backend, key(ComponentPrefix, (*componentPointer).Namespace, (*componentPointer).Id), componentPointer)
+}
+
+func (dbsql *SQLite3) UpdateSpecification(specificationPointer *specifications.Specification) error {
+	/*
+	   Replace specification in datastore (in a transaction, so that its components cannot be deleted
+	   while it is replaced)
+	*/
+	defer metrics.ObserveDatastore("UpdateSpecification", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// We replace the specification once its components are found
+	if err := checkComponents(tx, specificationPointer); err != nil {
+		return err
+	}
+	if err := genericUpdateFunction(tx, key(SpecificationPrefix, (*specificationPointer).Namespace, (*specificationPointer).Id), specificationPointer); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (dbsql *SQLite3) UpdatePlanning(planningPointer *[][]string, namespace string, specificationId string) error {
+	/*
+	   Replace planning in datastore
+	*/
+	defer metrics.ObserveDatastore("UpdatePlanning", time.Now())
+
+	return genericUpdateFunction(dbsql.Backend, key(PlanningPrefix, namespace, specificationId), planningPointer)
+}
+
+func (dbsql *SQLite3) UpdateSpecificationWithPlanning(specificationPointer *specifications.Specification, planningPointer *[][]string) error {
+	/*
+	   Replace specification and its planning in datastore in a single transaction
+	*/
+	defer metrics.ObserveDatastore("UpdateSpecificationWithPlanning", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// We replace both of them once the components of the specification are found
+	if err := checkComponents(tx, specificationPointer); err != nil {
+		return err
+	}
+	if err := genericUpdateFunction(tx, key(PlanningPrefix, specificationPointer.Namespace, specificationPointer.Id), planningPointer); err != nil {
+		return err
+	}
+	if err := genericUpdateFunction(tx, key(SpecificationPrefix, specificationPointer.Namespace, specificationPointer.Id), specificationPointer); err != nil {
+		return err
+	}
+
+	// If everything went well, we commit both replacements at once.
+	return tx.Commit()
+}
+
+func (dbsql *SQLite3) UpdateDefinition(definitionPointer *definitions.Definition) error {
+	/*
+	   Replace definition in datastore
+	*/
+	defer metrics.ObserveDatastore("UpdateDefinition", time.Now())
 
-	return genericAddFunction(dbsql, string(ResultDefPrefix)+(*resultDefinitionPointer).Id, resultDefinitionPointer)
+	return genericUpdateFunction(dbsql.Backend, key(DefinitionPrefix, (*definitionPointer).Namespace, (*definitionPointer).Id), definitionPointer)
 }
 
-func (dbsql *SQLite3) UpdateResultJob(resultJobPointer *results.ResultJob, resultDefinitionId string) error {
+func (dbsql *SQLite3) UpdateResultJob(resultJobPointer *results.ResultJob, namespace string, resultDefinitionId string) error {
 	/*
-		Update Result Job into Result Definition in datastore
+		Update Result Job into Result Definition in datastore (in a transaction, so that the concurrent
+		updates of the jobs of a definition are not lost)
 	*/
+	defer metrics.ObserveDatastore("UpdateResultJob", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
 	// Get Result Definition
-	resultDefinitionPointer, getErr := dbsql.GetResultDefinition(resultDefinitionId)
+	resultDefinitionPointer, getErr := genericGetFunction[results.ResultDefinition](tx, key(ResultDefPrefix, namespace, resultDefinitionId))
 	if getErr != nil {
 		return getErr
 	}
@@ -237,44 +710,101 @@ func (dbsql *SQLite3) UpdateResultJob(resultJobPointer *results.ResultJob, resul
 		(*resultDefinitionPointer).ResultJobs[idxResultJob] = *resultJobPointer
 	}
 
-	// Define the query
-	strUpdate := `UPDATE hector SET content=? WHERE id=?`
+	// Replace the result definition in the datastore
+	if err := genericUpdateFunction(tx, key(ResultDefPrefix, namespace, resultDefinitionId), resultDefinitionPointer); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	// We prepare the request corresponding to the query
-	statement, err := dbsql.Backend.Prepare(strUpdate)
+func (dbsql *SQLite3) DeleteComponent(namespace string, id string) error {
+	/*
+		Remove component from datastore as long as no specification references it (the components
+		of the shared namespace can be referenced from any namespace). The references are checked in
+		the same transaction, so that no specification can start to use it in between
+	*/
+	defer metrics.ObserveDatastore("DeleteComponent", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// We make sure to close the resource before the end of the function.
-	defer statement.Close()
+	// Get the specifications of all the namespaces
+	specs, err := genericListFunction[specifications.Specification](tx, string(SpecificationPrefix))
+	if err != nil {
+		return err
+	}
 
-	// Convert struct to string
-	bytesStruct, _ := json.Marshal(*resultDefinitionPointer)
-	strStruct := string(bytesStruct)
+	// Refuse the deletion if any of their tasks uses the component
+	for _, spec := range specs {
+		idxTask := slices.IndexFunc(spec.Spec.Dag.Tasks, func(task specifications.SpecificationTask) bool {
+			refNamespace, refId, err := namespaces.ResolveComponent(spec.Namespace, task.Component)
+			return err == nil && refNamespace == namespace && refId == id
+		})
+		if idxTask != -1 {
+			return &errors.ReferencedElementErr{Type: "components.Component", Id: namespace + namespaces.Separator + id, ReferencedByType: "specifications.Specification", ReferencedById: spec.Namespace + namespaces.Separator + spec.Id}
+		}
+	}
 
-	// We execute the request passing the corresponding data.
-	r, err := statement.Exec(strStruct, string(ResultDefPrefix)+(*resultDefinitionPointer).Id)
+	if err := genericDeleteFunction[components.Component](tx, key(ComponentPrefix, namespace, id)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkComponents function ensures that the components used by the tasks of a specification are
+// stored. It must be called in the transaction that writes the specification, so that none of them
+// can be deleted before it is committed. It takes as input the transaction and the specification.
+// Returns an error variable to report any problems.
+func checkComponents(db preparer, specificationPointer *specifications.Specification) error {
+	for _, task := range specificationPointer.Spec.Dag.Tasks {
+		refNamespace, refId, err := namespaces.ResolveComponent(specificationPointer.Namespace, task.Component)
+		if err != nil {
+			return err
+		}
+		if _, err := genericGetFunction[components.Component](db, key(ComponentPrefix, refNamespace, refId)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dbsql *SQLite3) DeleteSpecification(namespace string, id string) error {
+	/*
+		Remove specification and its planning from datastore in a single transaction
+	*/
+	defer metrics.ObserveDatastore("DeleteSpecification", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// We confirm that a row has been affected in the table
-	if i, err := r.RowsAffected(); err != nil || i != 1 {
-		return fmt.Errorf("an affected row was expected")
+	// We remove both of them
+	if err := genericDeleteFunction[specifications.Specification](tx, key(SpecificationPrefix, namespace, id)); err != nil {
+		return err
+	}
+	if err := genericDeleteFunction[[][]string](tx, key(PlanningPrefix, namespace, id)); err != nil {
+		return err
 	}
 
-	// If everything went well, we do not return any errors.
-	return nil
+	// If everything went well, we commit both removals at once.
+	return tx.Commit()
 }
 
 func (dbsql *SQLite3) GetDefinitionsWithWaitings() (*[]definitions.Definition, error) {
 	/*
 		Returns those definitions where some of their tasks are pending execution.
 	*/
+	defer metrics.ObserveDatastore("GetDefinitionsWithWaitings", time.Now())
 
-	// Define the query
-	strSelect := `SELECT content FROM hector WHERE`
+	// Define the query (the result definitions of all the namespaces)
+	strSelect := `SELECT content FROM hector WHERE id LIKE ?`
 
 	// We prepare the request corresponding to the query
 	statement, err := dbsql.Backend.Prepare(strSelect)
@@ -286,7 +816,7 @@ func (dbsql *SQLite3) GetDefinitionsWithWaitings() (*[]definitions.Definition, e
 	defer statement.Close()
 
 	// We execute the request and since it will have more than one solution, we store the result in the variable rows.
-	rows, err := statement.Query(ResultDefPrefix)
+	rows, err := statement.Query(string(ResultDefPrefix) + "%")
 	if err != nil {
 		return nil, err
 	}
@@ -307,19 +837,21 @@ func (dbsql *SQLite3) GetDefinitionsWithWaitings() (*[]definitions.Definition, e
 		resDef := results.ResultDefinition{}
 
 		// We insert the output in the content variable.
-		rows.Scan(&content)
+		if err := rows.Scan(&content); err != nil {
+			return nil, err
+		}
 
 		// Add the content to the empty struct
 		json.Unmarshal([]byte(content), &resDef)
 
 		// We search if any of the tasks are pending execution
-		idxSomeWaiting := slices.IndexFunc(resDef.ResultJobs, func(jobRes results.ResultJob) bool { return jobRes.Status == results.Waiting })
+		idxSomeWaiting := slices.IndexFunc(resDef.ResultJobs, func(jobRes results.ResultJob) bool { return jobRes.Status.Pending() })
 
 		// If there are any task pending execution ...
 		if idxSomeWaiting != -1 {
 
 			// We extract the corresponding definition
-			defPointer, err := dbsql.GetDefinition(resDef.Id)
+			defPointer, err := dbsql.GetDefinition(resDef.Namespace, resDef.Id)
 			if err != nil {
 				return nil, fmt.Errorf("error during definition extraction %s", err.Error())
 			}
@@ -330,5 +862,348 @@ func (dbsql *SQLite3) GetDefinitionsWithWaitings() (*[]definitions.Definition, e
 	}
 
 	// We return the slice of definitions
-	return &definitions, nil
+	return &definitions, rows.Err()
+}
+
+func (dbsql *SQLite3) AddQueuedDefinition(namespace string, definitionId string) error {
+	/*
+		Insert the namespace and identifier of a definition at the end of the execution queue
+	*/
+	defer metrics.ObserveDatastore("AddQueuedDefinition", time.Now())
+
+	return addQueuedDefinition(dbsql.Backend, namespace, definitionId)
+}
+
+func addQueuedDefinition(db preparer, namespace string, definitionId string) error {
+	/*
+		Insert a definition in the execution queue (in the database or in a transaction)
+	*/
+
+	// Define the query
+	strInsert := `INSERT INTO queue(namespace, definitionId) VALUES(?, ?)`
+
+	// We prepare the request corresponding to the query
+	statement, err := db.Prepare(strInsert)
+	if err != nil {
+		return err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// We execute the request passing the corresponding data.
+	r, err := statement.Exec(namespace, definitionId)
+	if err != nil {
+		return err
+	}
+
+	// We confirm that a row has been affected in the table
+	if i, err := r.RowsAffected(); err != nil || i != 1 {
+		return fmt.Errorf("an affected row was expected")
+	}
+
+	// If everything went well, we do not return any errors.
+	return nil
+}
+
+func (dbsql *SQLite3) AddBundle(bundlePointer *bundles.Bundle, planningPointer *[][]string, resultDefinitionPointer *results.ResultDefinition) error {
+	/*
+		Insert the elements of a bundle in a single transaction: the components, the specification with
+		its planning and the definition with its result, which is also added to the execution queue
+	*/
+	defer metrics.ObserveDatastore("AddBundle", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// We insert the components
+	for i := range bundlePointer.Components {
+		component := &bundlePointer.Components[i]
+		if err := genericAddFunction(tx, key(ComponentPrefix, component.Namespace, component.Id), component); err != nil {
+			return err
+		}
+	}
+
+	// We insert the specification together with its planning, whose components must be stored (either
+	// before or in the bundle itself)
+	if specification := bundlePointer.Specification; specification != nil {
+		if err := checkComponents(tx, specification); err != nil {
+			return err
+		}
+		if err := genericAddFunction(tx, key(PlanningPrefix, specification.Namespace, specification.Id), planningPointer); err != nil {
+			return err
+		}
+		if err := genericAddFunction(tx, key(SpecificationPrefix, specification.Namespace, specification.Id), specification); err != nil {
+			return err
+		}
+	}
+
+	// We insert the definition with its result and queue it
+	if definition := bundlePointer.Definition; definition != nil {
+		if err := genericAddFunction(tx, key(DefinitionPrefix, definition.Namespace, definition.Id), definition); err != nil {
+			return err
+		}
+		if err := genericAddFunction(tx, key(ResultDefPrefix, resultDefinitionPointer.Namespace, resultDefinitionPointer.Id), resultDefinitionPointer); err != nil {
+			return err
+		}
+		if err := addQueuedDefinition(tx, definition.Namespace, definition.Id); err != nil {
+			return err
+		}
+	}
+
+	// If everything went well, we commit all the insertions at once.
+	return tx.Commit()
+}
+
+func (dbsql *SQLite3) CancelResultDefinition(namespace string, id string, logs string) (*results.ResultDefinition, []results.ResultJob, error) {
+	/*
+		Remove a definition from the execution queue and mark its pending jobs as cancelled in a single
+		transaction, so that a worker cannot extract it in between
+	*/
+	defer metrics.ObserveDatastore("CancelResultDefinition", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// We remove the definition from the queue (if it is there)
+	if _, err := tx.Exec(`DELETE FROM queue WHERE namespace = ? AND definitionId = ?`, namespace, id); err != nil {
+		return nil, nil, err
+	}
+
+	// We mark the pending jobs of its result as cancelled
+	resultDefinitionPointer, err := genericGetFunction[results.ResultDefinition](tx, key(ResultDefPrefix, namespace, id))
+	if err != nil {
+		return nil, nil, err
+	}
+	cancelled := []results.ResultJob{}
+	for i, resultJob := range resultDefinitionPointer.ResultJobs {
+		if !resultJob.Status.Pending() {
+			continue
+		}
+		resultDefinitionPointer.ResultJobs[i] = results.ResultJob{Id: resultJob.Id, Name: resultJob.Name, Logs: logs, Status: results.Cancelled}
+		cancelled = append(cancelled, resultDefinitionPointer.ResultJobs[i])
+	}
+	if err := genericUpdateFunction(tx, key(ResultDefPrefix, namespace, id), resultDefinitionPointer); err != nil {
+		return nil, nil, err
+	}
+
+	// If everything went well, we commit both changes at once.
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return resultDefinitionPointer, cancelled, nil
+}
+
+func (dbsql *SQLite3) IsQueuedDefinition(namespace string, definitionId string) (bool, error) {
+	/*
+		Reports whether a definition is waiting in the execution queue
+	*/
+	defer metrics.ObserveDatastore("IsQueuedDefinition", time.Now())
+
+	// Define the query
+	strSelect := `SELECT EXISTS(SELECT 1 FROM queue WHERE namespace = ? AND definitionId = ?)`
+
+	// We prepare the request corresponding to the query
+	statement, err := dbsql.Backend.Prepare(strSelect)
+	if err != nil {
+		return false, err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// Execute the request and enter the result in the queued variable.
+	var queued bool
+	if err := statement.QueryRow(namespace, definitionId).Scan(&queued); err != nil {
+		return false, err
+	}
+	return queued, nil
+}
+
+func (dbsql *SQLite3) CountQueuedDefinitions() (int, error) {
+	/*
+		Returns the number of definitions waiting in the execution queue
+	*/
+	defer metrics.ObserveDatastore("CountQueuedDefinitions", time.Now())
+
+	// Execute the request and enter the result in the count variable.
+	var count int
+	if err := dbsql.Backend.QueryRow(`SELECT COUNT(*) FROM queue`).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (dbsql *SQLite3) PopQueuedDefinition() (*definitions.Definition, error) {
+	/*
+		Extracts the oldest definition of the execution queue and removes it from the queue
+	*/
+	defer metrics.ObserveDatastore("PopQueuedDefinition", time.Now())
+
+	// Define the query (the deletion and the selection are performed in a single atomic statement)
+	strDelete := `DELETE FROM queue WHERE position = (SELECT MIN(position) FROM queue) RETURNING namespace, definitionId`
+
+	// We prepare the request corresponding to the query
+	statement, err := dbsql.Backend.Prepare(strDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// Execute the request and enter the results in the namespace and definitionId variables.
+	var namespace, definitionId string
+	deleteErr := statement.QueryRow().Scan(&namespace, &definitionId)
+	if deleteErr != nil {
+		if deleteErr == sql.ErrNoRows {
+			return nil, &errors.EmptyQueueErr{}
+		} else {
+			return nil, deleteErr
+		}
+	}
+
+	// We return the corresponding definition
+	return dbsql.GetDefinition(namespace, definitionId)
+}
+
+func (dbsql *SQLite3) AddToken(tokenPointer *tokens.Token) error {
+	/*
+	   Insert token in datastore
+	*/
+	defer metrics.ObserveDatastore("AddToken", time.Now())
+
+	return genericAddFunction(dbsql.Backend, string(TokenPrefix)+(*tokenPointer).Id, tokenPointer)
+}
+
+func (dbsql *SQLite3) GetTokenByHash(hash string) (*tokens.Token, error) {
+	/*
+		Performs a query to extract a token given the hash of its secret
+	*/
+	defer metrics.ObserveDatastore("GetTokenByHash", time.Now())
+
+	// Define the query
+	strSelect := `SELECT content FROM hector WHERE id LIKE ? AND json_extract(content, '$.hash') = ?`
+
+	// We prepare the request corresponding to the query
+	statement, err := dbsql.Backend.Prepare(strSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	// We make sure to close the resource before the end of the function.
+	defer statement.Close()
+
+	// Execute the request and enter the results in the content variable.
+	var content string
+	selectErr := statement.QueryRow(string(TokenPrefix)+"%", hash).Scan(&content)
+	if selectErr != nil {
+		if selectErr == sql.ErrNoRows {
+			return nil, &errors.ElementNotFoundErr{Type: "tokens.Token", Id: "(hidden)"}
+		} else {
+			return nil, selectErr
+		}
+	}
+
+	// Add the content to the empty struct
+	token := tokens.Token{}
+	json.Unmarshal([]byte(content), &token)
+
+	return &token, nil
+}
+
+func (dbsql *SQLite3) ListTokens(options *datastores.ListOptions) (*datastores.Page[tokens.Token], error) {
+	/*
+	   Lists the tokens that match the given options
+	*/
+	defer metrics.ObserveDatastore("ListTokens", time.Now())
+
+	return genericPageFunction[tokens.Token](dbsql, string(TokenPrefix), taggedFields, datastores.SummarizeToken, options)
+}
+
+func (dbsql *SQLite3) DeleteToken(id string) error {
+	/*
+		Remove token from datastore
+	*/
+	defer metrics.ObserveDatastore("DeleteToken", time.Now())
+
+	return genericDeleteFunction[tokens.Token](dbsql.Backend, string(TokenPrefix)+id)
+}
+
+func (dbsql *SQLite3) AddDelivery(deliveryPointer *webhooks.Delivery) error {
+	/*
+	   Insert webhook delivery in datastore
+	*/
+	defer metrics.ObserveDatastore("AddDelivery", time.Now())
+
+	return genericAddFunction(dbsql.Backend, string(DeliveryPrefix)+(*deliveryPointer).Id, deliveryPointer)
+}
+
+func (dbsql *SQLite3) UpdateDelivery(deliveryPointer *webhooks.Delivery) error {
+	/*
+	   Update webhook delivery in datastore
+	*/
+	defer metrics.ObserveDatastore("UpdateDelivery", time.Now())
+
+	return genericUpdateFunction(dbsql.Backend, string(DeliveryPrefix)+(*deliveryPointer).Id, deliveryPointer)
+}
+
+func (dbsql *SQLite3) DeleteDelivery(id string) error {
+	/*
+		Remove webhook delivery from datastore
+	*/
+	defer metrics.ObserveDatastore("DeleteDelivery", time.Now())
+
+	return genericDeleteFunction[webhooks.Delivery](dbsql.Backend, string(DeliveryPrefix)+id)
+}
+
+func (dbsql *SQLite3) ListPendingDeliveries() (*[]webhooks.Delivery, error) {
+	/*
+	   Extracts the webhook deliveries that have not been sent yet and have not exhausted their attempts
+	   (the condition is the one of the pending_deliveries index, so the failed ones are not scanned)
+	*/
+	defer metrics.ObserveDatastore("ListPendingDeliveries", time.Now())
+
+	pending, err := genericQueryFunction[webhooks.Delivery](dbsql.Backend, `SELECT content FROM hector INDEXED BY pending_deliveries WHERE id LIKE 'hook-%' AND json_extract(content, '$.failed') = 0`)
+	if err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+func (dbsql *SQLite3) DeleteFailedDeliveries(before time.Time) error {
+	/*
+	   Remove the webhook deliveries that exhausted their attempts before a given time
+	*/
+	defer metrics.ObserveDatastore("DeleteFailedDeliveries", time.Now())
+
+	// We begin the transaction and make sure that it is rolled back if it is not committed
+	tx, err := dbsql.Backend.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// We select the failed deliveries and remove the expired ones
+	failed, err := genericQueryFunction[webhooks.Delivery](tx, `SELECT content FROM hector WHERE id LIKE 'hook-%' AND json_extract(content, '$.failed') = 1`)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range failed {
+		if !delivery.FailedAt.Before(before) {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM hector WHERE id=?`, string(DeliveryPrefix)+delivery.Id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
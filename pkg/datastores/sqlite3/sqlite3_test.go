@@ -1,10 +1,21 @@
 package sqlite3
 
 import (
+	"dag/hector/golang/module/pkg/bundles"
+	"dag/hector/golang/module/pkg/components"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
+	"dag/hector/golang/module/pkg/webhooks"
+	"database/sql"
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TODO: I leave the rest of the tests pending after the review.
@@ -12,6 +23,7 @@ import (
 func TestAddResultDefinition(t *testing.T) {
 	resultDefinition := results.ResultDefinition{
 		Id:              "Result-Definition-Id",
+		Namespace:       "default",
 		Name:            "Result Definition Name",
 		SpecificationId: "Specification Id",
 		ResultJobs: []results.ResultJob{
@@ -29,10 +41,10 @@ func TestAddResultDefinition(t *testing.T) {
 		want   string
 	}{
 		{&resultDefinition, ""},
-		{&resultDefinition, "A results.ResultDefinition with id resdef-Result-Definition-Id is already stored in the database."},
+		{&resultDefinition, "A results.ResultDefinition with id resdef-default/Result-Definition-Id is already stored in the database."},
 	}
 
-	sqlite3, _ := NewSQLite3()
+	sqlite3, _ := NewSQLite3(DefaultPath)
 
 	for i, tt := range tests {
 
@@ -53,6 +65,7 @@ func TestAddResultDefinition(t *testing.T) {
 func TestGetResultDefinition(t *testing.T) {
 	resultDefinition := results.ResultDefinition{
 		Id:              "Result-Definition-Id",
+		Namespace:       "default",
 		Name:            "Result Definition Name",
 		SpecificationId: "Specification-Id",
 		ResultJobs: []results.ResultJob{
@@ -65,7 +78,7 @@ func TestGetResultDefinition(t *testing.T) {
 		},
 	}
 
-	sqlite3, _ := NewSQLite3()
+	sqlite3, _ := NewSQLite3(DefaultPath)
 	sqlite3.AddResultDefinition(&resultDefinition)
 
 	var tests = []struct {
@@ -73,14 +86,14 @@ func TestGetResultDefinition(t *testing.T) {
 		want string
 	}{
 		{"Result-Definition-Id", ""},
-		{"Bad-Id", "results.ResultDefinition with id resdef-Bad-Id not found in database."},
+		{"Bad-Id", "results.ResultDefinition with id resdef-default/Bad-Id not found in database."},
 	}
 
 	for i, tt := range tests {
 
 		testname := "test_" + strconv.Itoa(i)
 		t.Run(testname, func(t *testing.T) {
-			resDefPointer, err := sqlite3.GetResultDefinition(tt.id)
+			resDefPointer, err := sqlite3.GetResultDefinition("default", tt.id)
 
 			if err == nil {
 				if (*resDefPointer).Id != resultDefinition.Id {
@@ -94,3 +107,607 @@ func TestGetResultDefinition(t *testing.T) {
 		})
 	}
 }
+
+func TestQueuedDefinitions(t *testing.T) {
+	definition := definitions.Definition{
+		Id:              "Queued-Definition-Id",
+		Namespace:       "default",
+		Name:            "Queued Definition Name",
+		SpecificationId: "Specification-Id",
+	}
+
+	sqlite3, _ := NewSQLite3(DefaultPath)
+	sqlite3.AddDefinition(&definition)
+
+	var tests = []struct {
+		queue []string
+		want  string
+	}{
+		{[]string{"Queued-Definition-Id"}, ""},
+		{[]string{}, "There are no definitions waiting in the queue."},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			for _, id := range tt.queue {
+				sqlite3.AddQueuedDefinition("default", id)
+			}
+			defPointer, err := sqlite3.PopQueuedDefinition()
+
+			if err == nil {
+				if (*defPointer).Id != definition.Id {
+					t.Error("The definition returned by the PopQueuedDefinition() function is not the correct one.")
+				}
+				err = fmt.Errorf("")
+			}
+			if err.Error() != tt.want {
+				t.Error("got ", err, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDefinitionsWithWaitings(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the definitions with waiting or running jobs are pending, whatever their namespace
+	for i, status := range []results.Status{results.Done, results.Running, results.Waiting, results.Error} {
+		id := "Def-" + strconv.Itoa(i)
+		namespace := []string{"default", "team-a"}[i%2]
+		sqlite3.AddDefinition(&definitions.Definition{Id: id, Namespace: namespace})
+		sqlite3.AddResultDefinition(&results.ResultDefinition{Id: id, Namespace: namespace, ResultJobs: []results.ResultJob{{Id: "Job-" + id, Status: status}}})
+	}
+	sqlite3.AddQueuedDefinition("team-a", "Def-1")
+
+	pending, err := sqlite3.GetDefinitionsWithWaitings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		namespace string
+		id        string
+		queued    bool
+	}{
+		{"team-a", "Def-1", true},
+		{"default", "Def-2", false},
+	}
+	if len(*pending) != len(tests) {
+		t.Fatal("got ", len(*pending), " pending definitions, want ", len(tests))
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if definition := (*pending)[i]; definition.Namespace != tt.namespace || definition.Id != tt.id {
+				t.Error("got ", definition.Namespace, "/", definition.Id, ", want ", tt.namespace, "/", tt.id)
+			}
+			if queued, err := sqlite3.IsQueuedDefinition(tt.namespace, tt.id); err != nil || queued != tt.queued {
+				t.Error("got queued ", queued, " and error ", err, ", want ", tt.queued)
+			}
+		})
+	}
+}
+
+func TestDeleteComponent(t *testing.T) {
+	referencedComponent := components.Component{Id: "Referenced-Component-Id", Namespace: "shared", Name: "Referenced Component Name"}
+	freeComponent := components.Component{Id: "Free-Component-Id", Namespace: "shared", Name: "Free Component Name"}
+	specification := specifications.Specification{
+		Id:        "Referencing-Specification-Id",
+		Namespace: "default",
+		Name:      "Referencing Specification Name",
+		Spec: specifications.Spec{
+			Dag: specifications.Dag{
+				Tasks: []specifications.SpecificationTask{
+					{
+						Name:      "A",
+						Component: "shared/Referenced-Component-Id",
+					},
+				},
+			},
+		},
+	}
+
+	sqlite3, _ := NewSQLite3(DefaultPath)
+	sqlite3.AddComponent(&referencedComponent)
+	sqlite3.AddComponent(&freeComponent)
+	sqlite3.AddSpecification(&specification)
+
+	var tests = []struct {
+		id   string
+		want string
+	}{
+		{"Referenced-Component-Id", "The components.Component with id shared/Referenced-Component-Id cannot be deleted because it is referenced by the specifications.Specification with id default/Referencing-Specification-Id."},
+		{"Free-Component-Id", ""},
+		{"Free-Component-Id", "components.Component with id comp-shared/Free-Component-Id not found in database."},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			err := sqlite3.DeleteComponent("shared", tt.id)
+
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+			if err.Error() != tt.want {
+				t.Error("got ", err, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTokenByHash(t *testing.T) {
+	token := tokens.Token{Id: "Token-Id", Name: "alice", Role: tokens.Submitter, Hash: tokens.HashSecret("secret")}
+
+	sqlite3, _ := NewSQLite3(DefaultPath)
+	sqlite3.AddToken(&token)
+
+	var tests = []struct {
+		secret string
+		want   string
+	}{
+		{"secret", ""},
+		{"other secret", "tokens.Token with id (hidden) not found in database."},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			tokenPointer, err := sqlite3.GetTokenByHash(tokens.HashSecret(tt.secret))
+
+			if err == nil {
+				if tokenPointer.Id != token.Id || tokenPointer.Hash != token.Hash {
+					t.Error("The token returned by the GetTokenByHash() function is not the correct one.")
+				}
+				err = fmt.Errorf("")
+			}
+			if err.Error() != tt.want {
+				t.Error("got ", err, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	componentA := components.Component{Id: "Isolated-Component-Id", Namespace: "team-a", Name: "Component of team A"}
+	componentB := components.Component{Id: "Isolated-Component-Id", Namespace: "team-b", Name: "Component of team B"}
+
+	sqlite3, _ := NewSQLite3(DefaultPath)
+	sqlite3.AddComponent(&componentA)
+
+	var tests = []struct {
+		namespace string
+		want      string
+	}{
+		{"team-a", "Component of team A"},
+		{"team-b", "Component of team B"},
+		{"team-c", "components.Component with id comp-team-c/Isolated-Component-Id not found in database."},
+	}
+
+	// The same identifier can be used in another namespace
+	if err := sqlite3.AddComponent(&componentB); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			got := ""
+			componentPointer, err := sqlite3.GetComponent(tt.namespace, "Isolated-Component-Id")
+			if err == nil {
+				got = componentPointer.Name
+			} else {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+
+	// The listings only contain the elements of the namespace
+	page, err := sqlite3.ListComponents("team-a", &datastores.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Namespace != "team-a" {
+		t.Error("The listing of the namespace team-a contains ", page.Items)
+	}
+}
+
+func TestAddBundle(t *testing.T) {
+	storedDefinition := definitions.Definition{Id: "Bundle-Stored-Definition-Id", Namespace: "default", Name: "Stored Definition Name"}
+
+	sqlite3, _ := NewSQLite3(DefaultPath)
+	sqlite3.AddDefinition(&storedDefinition)
+
+	var tests = []struct {
+		componentId  string
+		definitionId string
+		want         string
+	}{
+		{"Bundle-Component-Id-1", "Bundle-Definition-Id", ""},
+		{"Bundle-Component-Id-2", "Bundle-Stored-Definition-Id", "A definitions.Definition with id def-default/Bundle-Stored-Definition-Id is already stored in the database."},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			bundle := bundles.Bundle{
+				Components:    []components.Component{{Id: tt.componentId, Namespace: "default", Name: "Bundle Component Name"}},
+				Specification: &specifications.Specification{Id: tt.componentId, Namespace: "default", Name: "Bundle Specification Name"},
+				Definition:    &definitions.Definition{Id: tt.definitionId, Namespace: "default", Name: "Bundle Definition Name"},
+			}
+			resultDefinition := results.ResultDefinition{Id: tt.definitionId, Namespace: "default"}
+			err := sqlite3.AddBundle(&bundle, &[][]string{}, &resultDefinition)
+
+			// Either all the elements are stored or none of them
+			_, componentErr := sqlite3.GetComponent("default", tt.componentId)
+			_, planningErr := sqlite3.GetPlanning("default", tt.componentId)
+			if (componentErr == nil) != (err == nil) || (planningErr == nil) != (err == nil) {
+				t.Error("The elements of the bundle must be stored if and only if the bundle is accepted")
+			}
+
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+			if err.Error() != tt.want {
+				t.Error("got ", err, ", want ", tt.want)
+			}
+		})
+	}
+
+	// Only the definition of the accepted bundle is queued
+	for {
+		definitionPointer, err := sqlite3.PopQueuedDefinition()
+		if err != nil {
+			t.Fatal("The definition of the bundle must be queued")
+		}
+		if definitionPointer.Id == "Bundle-Definition-Id" {
+			break
+		}
+	}
+}
+
+func TestCancelResultDefinition(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A queued definition with a finished job and a waiting one
+	sqlite3.AddDefinition(&definitions.Definition{Id: "Def-ID", Namespace: "default"})
+	sqlite3.AddResultDefinition(&results.ResultDefinition{Id: "Def-ID", Namespace: "default", ResultJobs: []results.ResultJob{
+		{Id: "Job-1", Name: "A", Status: results.Done},
+		{Id: "Job-2", Name: "B", Status: results.Waiting},
+	}})
+	sqlite3.AddQueuedDefinition("default", "Def-ID")
+
+	resultDefinition, cancelled, err := sqlite3.CancelResultDefinition("default", "Def-ID", "Cancelled: test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cancelled) != 1 || cancelled[0].Name != "B" || cancelled[0].Status != results.Cancelled || cancelled[0].Logs != "Cancelled: test" {
+		t.Error("got cancelled jobs ", cancelled, ", want only B")
+	}
+	if status := resultDefinition.ResultJobs[0].Status; status != results.Done {
+		t.Error("got status ", status, " for the finished job, want ", results.Done)
+	}
+	if queued, err := sqlite3.IsQueuedDefinition("default", "Def-ID"); err != nil || queued {
+		t.Error("got queued ", queued, " and error ", err, ", want false")
+	}
+	if stored, _ := sqlite3.GetResultDefinition("default", "Def-ID"); stored.Status() != results.Cancelled {
+		t.Error("got stored status ", stored.Status(), ", want ", results.Cancelled)
+	}
+
+	// The definition must exist
+	if _, _, err := sqlite3.CancelResultDefinition("default", "Unknown-ID", "Cancelled: test"); err == nil {
+		t.Error("expected an error for an unknown definition")
+	}
+}
+
+func TestUpdateResultJobConcurrently(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A definition whose jobs are all updated at the same time
+	resultDefinition := results.ResultDefinition{Id: "Def-ID", Namespace: "default"}
+	for i := 0; i < 100; i++ {
+		resultDefinition.ResultJobs = append(resultDefinition.ResultJobs, results.ResultJob{Id: "Job-" + strconv.Itoa(i), Status: results.Waiting})
+	}
+	sqlite3.AddResultDefinition(&resultDefinition)
+
+	var wg sync.WaitGroup
+	for _, resultJob := range resultDefinition.ResultJobs {
+		wg.Add(1)
+		go func(resultJob results.ResultJob) {
+			defer wg.Done()
+			resultJob.Status = results.Done
+			if err := sqlite3.UpdateResultJob(&resultJob, "default", "Def-ID"); err != nil {
+				t.Error(err)
+			}
+		}(resultJob)
+	}
+	wg.Wait()
+
+	// No update must have been lost
+	stored, err := sqlite3.GetResultDefinition("default", "Def-ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, resultJob := range stored.ResultJobs {
+		if resultJob.Status != results.Done {
+			t.Error("got status ", resultJob.Status, " for the job ", resultJob.Id, ", want ", results.Done)
+		}
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A pending delivery, one that failed long ago and one that has just failed
+	now := time.Now()
+	sqlite3.AddDelivery(&webhooks.Delivery{Id: "Pending-ID", Url: "https://example.com"})
+	sqlite3.AddDelivery(&webhooks.Delivery{Id: "Old-ID", Url: "https://example.com", Failed: true, FailedAt: now.Add(-48 * time.Hour)})
+	sqlite3.AddDelivery(&webhooks.Delivery{Id: "Recent-ID", Url: "https://example.com", Failed: true, FailedAt: now})
+
+	pending, err := sqlite3.ListPendingDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*pending) != 1 || (*pending)[0].Id != "Pending-ID" {
+		t.Error("got pending deliveries ", *pending, ", want only Pending-ID")
+	}
+
+	// Only the failed deliveries older than the retention are removed
+	if err := sqlite3.DeleteFailedDeliveries(now.Add(-24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		id     string
+		exists bool
+	}{
+		{"Pending-ID", true},
+		{"Old-ID", false},
+		{"Recent-ID", true},
+	}
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			_, err := genericGetFunction[webhooks.Delivery](sqlite3.Backend, string(DeliveryPrefix)+tt.id)
+			if exists := err == nil; exists != tt.exists {
+				t.Error("got exists ", exists, ", want ", tt.exists)
+			}
+		})
+	}
+}
+
+func TestMigrateNamespaces(t *testing.T) {
+
+	// A database created before the namespaces existed
+	path := filepath.Join(t.TempDir(), "hector.sqlite")
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`
+        CREATE TABLE hector(id TEXT PRIMARY KEY, content TEXT);
+        CREATE TABLE queue(position INTEGER PRIMARY KEY AUTOINCREMENT, definitionId TEXT);
+        INSERT INTO hector VALUES('comp-Comp-ID', '{"id":"Comp-ID"}');
+        INSERT INTO hector VALUES('plan-Spec-ID', '[["A"]]');
+        INSERT INTO hector VALUES('resdef-Def-ID', '{"Id":"Def-ID"}');
+        INSERT INTO hector VALUES('tok-Token-ID', '{"id":"Token-ID"}');
+        INSERT INTO queue(definitionId) VALUES('Def-ID');
+    `)
+	old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The migration can be applied any number of times
+	for i := 0; i < 2; i++ {
+		sqlite3, err := NewSQLite3(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlite3.Backend.Close()
+	}
+	sqlite3, err := NewSQLite3(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if component, err := sqlite3.GetComponent("default", "Comp-ID"); err != nil || component.Namespace != "default" {
+		t.Error("got component ", component, " and error ", err, ", want it in the default namespace")
+	}
+	if _, err := sqlite3.GetPlanning("default", "Spec-ID"); err != nil {
+		t.Error("got error ", err, " for the planning, want it in the default namespace")
+	}
+	if resultDefinition, err := sqlite3.GetResultDefinition("default", "Def-ID"); err != nil || resultDefinition.Namespace != "default" {
+		t.Error("got result definition ", resultDefinition, " and error ", err, ", want it in the default namespace")
+	}
+	if _, err := genericGetFunction[tokens.Token](sqlite3.Backend, string(TokenPrefix)+"Token-ID"); err != nil {
+		t.Error("got error ", err, " for the token, whose key does not have a namespace")
+	}
+	if queued, err := sqlite3.IsQueuedDefinition("default", "Def-ID"); err != nil || !queued {
+		t.Error("got queued ", queued, " and error ", err, ", want the definition queued in the default namespace")
+	}
+}
+
+func TestUpdateSpecificationWithPlanning(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A planning whose specification has been lost is not replaced
+	sqlite3.AddPlanning(&[][]string{{"A"}}, "default", "Spec-ID")
+	specification := specifications.Specification{Id: "Spec-ID", Namespace: "default", Name: "New"}
+	if err := sqlite3.UpdateSpecificationWithPlanning(&specification, &[][]string{{"B"}}); err == nil {
+		t.Error("expected an error for an unknown specification")
+	}
+	if planning, _ := sqlite3.GetPlanning("default", "Spec-ID"); (*planning)[0][0] != "A" {
+		t.Error("got planning ", *planning, ", want the original one")
+	}
+
+	// Otherwise both of them are replaced
+	sqlite3.AddSpecification(&specifications.Specification{Id: "Spec-ID", Namespace: "default", Name: "Old"})
+	if err := sqlite3.UpdateSpecificationWithPlanning(&specification, &[][]string{{"B"}}); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := sqlite3.GetSpecification("default", "Spec-ID")
+	planning, _ := sqlite3.GetPlanning("default", "Spec-ID")
+	if stored.Name != "New" || (*planning)[0][0] != "B" {
+		t.Error("got specification ", stored.Name, " and planning ", *planning, ", want both of them replaced")
+	}
+}
+
+func TestListResultDefinitions(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// We store result definitions with repeated names and every status, and one of another namespace
+	statuses := []results.Status{results.Waiting, results.Done, results.Error, results.Cancelled, results.Running}
+	var stored []results.ResultDefinition
+	for i := 0; i < 20; i++ {
+		resultDefinition := results.ResultDefinition{
+			Id:              fmt.Sprintf("RD-%02d", (i*7)%20),
+			Namespace:       "default",
+			Name:            []string{"alpha", "beta", "alphabet", "gamma"}[i%4],
+			SpecificationId: "Spec-" + strconv.Itoa(i%2),
+			ResultJobs:      []results.ResultJob{{Id: "J", Status: statuses[i%5]}},
+		}
+		stored = append(stored, resultDefinition)
+		if err := sqlite3.AddResultDefinition(&resultDefinition); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sqlite3.AddResultDefinition(&results.ResultDefinition{Id: "RD-00", Namespace: "team-a", Name: "alpha"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		options datastores.ListOptions
+	}{
+		{datastores.ListOptions{}},
+		{datastores.ListOptions{Limit: 3}},
+		{datastores.ListOptions{Limit: 3, Descending: true}},
+		{datastores.ListOptions{Limit: 4, SortBy: datastores.SortByName}},
+		{datastores.ListOptions{Limit: 4, SortBy: datastores.SortByName, Descending: true}},
+		{datastores.ListOptions{Limit: 2, NamePrefix: "alpha"}},
+		{datastores.ListOptions{Limit: 2, SpecificationId: "Spec-1", SortBy: datastores.SortByName}},
+		{datastores.ListOptions{Limit: 1, Status: &statuses[2]}},
+		{datastores.ListOptions{Limit: 2, Status: &statuses[4], NamePrefix: "alpha", Descending: true}},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+
+			// We walk all the pages of the datastore and of the listing in memory, which must be the same
+			for options, want := tt.options, tt.options; ; {
+				page, err := sqlite3.ListResultDefinitions("default", &options)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantPage, _ := datastores.ListElements(stored, datastores.SummarizeResultDefinition, &want)
+
+				var ids, wantIds []string
+				for _, item := range page.Items {
+					ids = append(ids, item.Id)
+				}
+				for _, item := range wantPage.Items {
+					wantIds = append(wantIds, item.Id)
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(wantIds) || page.NextCursor != wantPage.NextCursor {
+					t.Fatal("got ", ids, " ", page.NextCursor, ", want ", wantIds, " ", wantPage.NextCursor)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				options.Cursor, want.Cursor = page.NextCursor, wantPage.NextCursor
+			}
+		})
+	}
+}
+
+func TestSpecificationReferences(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	specification := func(id string) *specifications.Specification {
+		return &specifications.Specification{Id: id, Namespace: "default", Name: id, Spec: specifications.Spec{Dag: specifications.Dag{Tasks: []specifications.SpecificationTask{{Name: "A", Component: "Comp-ID"}}}}}
+	}
+
+	// A specification cannot use a component that is not stored
+	if err := sqlite3.AddSpecification(specification("Spec-0")); err == nil {
+		t.Error("expected an error for an unknown component")
+	}
+
+	// And a component cannot be deleted while a specification that uses it is stored, even if both
+	// operations run at the same time
+	for i := 1; i <= 20; i++ {
+		sqlite3.AddComponent(&components.Component{Id: "Comp-ID", Namespace: "default", Name: "Component"})
+		var wg sync.WaitGroup
+		var addErr, deleteErr error
+		wg.Add(2)
+		go func() { defer wg.Done(); addErr = sqlite3.AddSpecification(specification("Spec-" + strconv.Itoa(i))) }()
+		go func() { defer wg.Done(); deleteErr = sqlite3.DeleteComponent("default", "Comp-ID") }()
+		wg.Wait()
+		if (addErr == nil) == (deleteErr == nil) {
+			t.Fatal("got ", addErr, " adding the specification and ", deleteErr, " deleting its component")
+		}
+		if addErr == nil {
+			sqlite3.AddPlanning(&[][]string{{"A"}}, "default", "Spec-"+strconv.Itoa(i))
+			if err := sqlite3.DeleteSpecification("default", "Spec-"+strconv.Itoa(i)); err != nil {
+				t.Fatal(err)
+			}
+			sqlite3.DeleteComponent("default", "Comp-ID")
+		}
+	}
+}
+
+func TestDeleteSpecification(t *testing.T) {
+	sqlite3, err := NewSQLite3(filepath.Join(t.TempDir(), "hector.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A specification whose planning has been lost is not removed
+	sqlite3.AddSpecification(&specifications.Specification{Id: "Spec-ID", Namespace: "default", Name: "Spec"})
+	if err := sqlite3.DeleteSpecification("default", "Spec-ID"); err == nil {
+		t.Error("expected an error for an unknown planning")
+	}
+	if _, err := sqlite3.GetSpecification("default", "Spec-ID"); err != nil {
+		t.Error("The specification must be kept when its planning cannot be removed")
+	}
+
+	// Otherwise both of them are removed, so the identifier can be submitted again
+	sqlite3.AddPlanning(&[][]string{{"A"}}, "default", "Spec-ID")
+	if err := sqlite3.DeleteSpecification("default", "Spec-ID"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite3.AddPlanning(&[][]string{{"A"}}, "default", "Spec-ID"); err != nil {
+		t.Error("The planning must have been removed: ", err)
+	}
+}
@@ -2,7 +2,9 @@ package definitions
 
 import (
 	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/formats"
 	"encoding/json"
+	"fmt"
 )
 
 type Parameter struct {
@@ -21,11 +23,17 @@ type Data struct {
 }
 
 type Definition struct {
-	Id              string `json:"id" validate:"isdefault"`
-	Name            string `json:"name" validate:"required"`
-	SpecificationId string `json:"specificationId" validate:"required"`
-	ApiVersion      string `json:"apiVersion" validate:"required"`
-	Data            Data   `json:"data" validate:"dive"`
+	Id              string   `json:"id" validate:"isdefault"`
+	Namespace       string   `json:"namespace" validate:"omitempty,namespace"`
+	Name            string   `json:"name" validate:"required"`
+	SpecificationId string   `json:"specificationId" validate:"required"`
+	ApiVersion      string   `json:"apiVersion" validate:"required"`
+	Data            Data     `json:"data" validate:"dive"`
+	SubmittedBy     string   `json:"submittedBy" validate:"isdefault"`
+	SubmitterId     string   `json:"submitterId,omitempty" validate:"isdefault"` // Token that submitted it, since the names of the identities are not unique
+	RequestId       string   `json:"requestId,omitempty" validate:"isdefault"`   // Request that submitted or retried it, to correlate the logs of its runs
+	Traceparent     string   `json:"traceparent,omitempty" validate:"isdefault"` // Trace context of that request, to which the spans of its runs belong
+	Webhooks        []string `json:"webhooks,omitempty" validate:"dive,url"`
 }
 
 // String function is applied to Definition variables and returns their content as a string.
@@ -35,15 +43,16 @@ func (def *Definition) String() string {
 }
 
 // FromFile function is applied on variables of type Definition and it is in charge of dumping
-// the content of a file in this variable. It takes as input the path of the file and returns
-// an error type variable in charge of notifying any problem.
+// the content of a file (JSON or, if its extension is .yaml or .yml, YAML) in this variable. It
+// takes as input the path of the file and returns an error type variable in charge of notifying
+// any problem.
 func (def *Definition) FromFile(file string) error {
 	content, err := pkg.ReadFile(file)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(content, def); err != nil {
-		return err
+	if err := formats.Unmarshal(content, formats.FromFileName(file), def); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
 	}
 	return nil
 }
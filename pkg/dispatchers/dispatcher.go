@@ -0,0 +1,422 @@
+package dispatchers
+
+import (
+	"context"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/webhooks"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+// We declare the default delivery policy. A delivery is retried with an exponential backoff
+// (5s, 10s, 20s, ...) that never exceeds maxBackoff, until it has been attempted maxAttempts times.
+// Then it is kept as failed during the retention period.
+const (
+	defaultMaxAttempts = 8
+	defaultBackoff     = 5 * time.Second
+	defaultRetention   = 7 * 24 * time.Hour
+	maxBackoff         = time.Hour
+	requestTimeout     = 10 * time.Second
+	maxRedirects       = 10
+)
+
+// We declare the maximum time the dispatcher waits before looking for due deliveries again and the
+// time between two removals of the expired failed deliveries.
+const (
+	pollInterval  = 5 * time.Second
+	pruneInterval = time.Hour
+)
+
+// notificationsBuffer is the number of notifications that can wait to be stored before the broker
+// is slowed down by the dispatcher.
+const notificationsBuffer = 1024
+
+// Dispatcher notifies the webhooks when the jobs and the definitions reach a final status. The
+// payloads are persisted in the datastore before being sent, so the deliveries that fail are
+// retried later (even after a restart).
+type Dispatcher struct {
+	Datastore   *datastores.Datastore
+	Secret      string   // Key used to sign the payloads
+	Urls        []string // Webhooks notified for all the definitions
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	Retention   time.Duration // Time during which the failed deliveries are kept
+	Logger      logrus.FieldLogger
+
+	// AllowedHosts are the hosts that the webhooks of the definitions can point to. If it is empty,
+	// any host is allowed as long as it is not a private address (see CheckUrl).
+	AllowedHosts []string
+
+	// signal wakes up the delivery loop when a new delivery is stored.
+	signal chan struct{}
+
+	// notifications contains the payloads whose deliveries have not been stored yet, so that the
+	// events are handled outside of the broker (and of the invocations that publish them).
+	notifications chan notification
+}
+
+// notification is a payload waiting for its deliveries to be stored. A notification without payload
+// only closes its flushed channel, once all the previous ones have been stored.
+type notification struct {
+	payload *webhooks.Payload
+	flushed chan struct{}
+}
+
+// NewDispatcher function creates a new instance of the Dispatcher type with the default delivery
+// policy. The requests can only reach private addresses (loopback, link-local or private networks)
+// through the hosts of the server-wide webhooks and the allowed hosts. It takes as input the pointer of
+// the datastore, the secret used to sign the payloads (which is required), the webhooks notified for
+// all the definitions and the hosts that the webhooks of the definitions can point to. It returns the
+// pointer to the constructed variable and an error variable to report any problems.
+func NewDispatcher(datastore *datastores.Datastore, secret string, urls []string, allowedHosts []string) (*Dispatcher, error) {
+	if secret == "" {
+		return nil, stderrors.New("a secret is required to sign the webhook payloads")
+	}
+	d := &Dispatcher{
+		Datastore:     datastore,
+		Secret:        secret,
+		Urls:          urls,
+		MaxAttempts:   defaultMaxAttempts,
+		Backoff:       defaultBackoff,
+		Retention:     defaultRetention,
+		Logger:        logging.Default(),
+		AllowedHosts:  allowedHosts,
+		signal:        make(chan struct{}, 1),
+		notifications: make(chan notification, notificationsBuffer),
+	}
+	d.Client = &http.Client{Timeout: requestTimeout, Transport: &http.Transport{DialContext: d.dial}, CheckRedirect: d.checkRedirect}
+	go d.record()
+	return d, nil
+}
+
+// CheckUrl function reports whether the webhook of a definition can be notified: it must be an http or
+// https url whose host is allowed and, unless it is explicitly allowed, is not a private address. Hosts
+// whose name resolves to private addresses are also refused when the payloads are sent. It takes as
+// input the url. Returns an error variable that describes the problem (nil if the url is valid).
+func (d *Dispatcher) CheckUrl(rawUrl string) error {
+	webhookUrl, err := url.Parse(rawUrl)
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Hostname() == "" {
+		return fmt.Errorf("webhook %s must be an http or https url", rawUrl)
+	}
+	host := webhookUrl.Hostname()
+	if slices.Contains(d.AllowedHosts, host) {
+		return nil
+	}
+	if len(d.AllowedHosts) > 0 {
+		return fmt.Errorf("webhook %s does not point to any of the allowed hosts", rawUrl)
+	}
+	if ip := net.ParseIP(host); (ip != nil && !publicIP(ip)) || host == "localhost" {
+		return fmt.Errorf("webhook %s points to a private address", rawUrl)
+	}
+	return nil
+}
+
+// trustedHost function reports whether the requests can reach private addresses through a host, which
+// is the case of the hosts of the server-wide webhooks and the allowed hosts. It takes as input the host.
+func (d *Dispatcher) trustedHost(host string) bool {
+	if slices.Contains(d.AllowedHosts, host) {
+		return true
+	}
+	return slices.IndexFunc(d.Urls, func(serverUrl string) bool {
+		parsed, err := url.Parse(serverUrl)
+		return err == nil && parsed.Hostname() == host
+	}) != -1
+}
+
+// dial function opens the connections of the webhook requests. The hosts that are not trusted are
+// resolved before connecting, so that the connection is refused if any of their addresses is private.
+// It takes as input the context of the request, the network and the address (host and port). Returns
+// the connection and an error variable to report any problems.
+func (d *Dispatcher) dial(ctx context.Context, network string, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if d.trustedHost(host) {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	// We connect to the checked address, so that a second resolution cannot return a different one
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if !publicIP(ip.IP) {
+			return nil, fmt.Errorf("the host %s resolves to the private address %s", host, ip.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("the host %s cannot be resolved", host)
+	}
+	return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+}
+
+// checkRedirect function decides whether the requests follow a redirect, which is only the case if it
+// stays on the same host or if its url could be used as the webhook of a definition (see CheckUrl). It
+// takes as input the redirected request and the requests already made. Returns an error variable to
+// stop following the redirects.
+func (d *Dispatcher) checkRedirect(request *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if request.URL.Hostname() == via[0].URL.Hostname() {
+		return nil
+	}
+	if err := d.CheckUrl(request.URL.String()); err != nil {
+		return fmt.Errorf("the redirect is not followed: %w", err)
+	}
+	return nil
+}
+
+// publicIP function reports whether an address can be reached from the webhook requests of the
+// definitions, which cannot point to the loopback, link-local, private or unspecified addresses.
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsPrivate() && !ip.IsUnspecified()
+}
+
+// HandleEvent function notifies the webhooks of a definition when one of its jobs reaches a final
+// status. It is meant to be registered as a handler of the broker, so the deliveries are stored in
+// the background. It takes as input the event.
+func (d *Dispatcher) HandleEvent(event events.Event) {
+	if event.ResultJob.Status.Pending() {
+		return
+	}
+
+	resultJob := event.ResultJob
+	d.enqueue(webhooks.Payload{
+		Event:        webhooks.JobEvent,
+		Namespace:    event.Namespace,
+		DefinitionId: event.DefinitionId,
+		Status:       resultJob.Status.String(),
+		ResultJob:    &resultJob,
+	})
+}
+
+// DefinitionFinished function notifies the webhooks of a definition when all its jobs have reached
+// a final status (in the background, as HandleEvent). It takes as input the pointer of the
+// ResultDefinition. It can be called on a nil Dispatcher, in which case nothing is notified.
+func (d *Dispatcher) DefinitionFinished(resultDefinition *results.ResultDefinition) {
+	if d == nil || resultDefinition.Status().Pending() {
+		return
+	}
+
+	d.enqueue(webhooks.Payload{
+		Event:            webhooks.DefinitionEvent,
+		Namespace:        resultDefinition.Namespace,
+		DefinitionId:     resultDefinition.Id,
+		Status:           resultDefinition.Status().String(),
+		ResultDefinition: resultDefinition,
+	})
+}
+
+// enqueue function hands a payload to the goroutine that stores its deliveries. It only waits if
+// too many notifications are already waiting. It takes as input the payload.
+func (d *Dispatcher) enqueue(payload webhooks.Payload) {
+	select {
+	case d.notifications <- notification{payload: &payload}:
+	default:
+		d.Logger.WithFields(logrus.Fields{logging.NamespaceField: payload.Namespace, logging.DefinitionIdField: payload.DefinitionId}).Warn("too many webhook notifications are waiting to be stored")
+		d.notifications <- notification{payload: &payload}
+	}
+}
+
+// record function stores the deliveries of the notifications in the order they were enqueued.
+func (d *Dispatcher) record() {
+	for n := range d.notifications {
+		if n.payload != nil {
+			d.notify(*n.payload)
+		} else {
+			close(n.flushed)
+		}
+	}
+}
+
+// Flush function waits until the deliveries of all the notifications enqueued so far have been
+// stored. It can be called on a nil Dispatcher, in which case it returns at once. It takes as input
+// the context that limits the wait. Returns an error variable if the context ends first.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case d.notifications <- notification{flushed: flushed}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start function launches the loop that sends the due deliveries in the background and removes
+// the failed ones once their retention period expires.
+func (d *Dispatcher) Start() {
+	go func() {
+		var pruned time.Time
+		for {
+			if now := time.Now(); now.Sub(pruned) >= pruneInterval {
+				d.prune(now)
+				pruned = now
+			}
+			d.deliverDue(time.Now())
+			select {
+			case <-d.signal:
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+}
+
+// notify function stores a delivery of the payload for each webhook of its definition and wakes up
+// the delivery loop. It takes as input the payload.
+func (d *Dispatcher) notify(payload webhooks.Payload) {
+
+	// We gather the webhooks of the server and those of the definition (if it can be found)
+	urls := slices.Clone(d.Urls)
+	definition, err := (*d.Datastore).GetDefinition(payload.Namespace, payload.DefinitionId)
+	if err != nil {
+		d.Logger.WithFields(logrus.Fields{logging.NamespaceField: payload.Namespace, logging.DefinitionIdField: payload.DefinitionId}).WithError(err).Error("cannot get the webhooks of the definition")
+	} else {
+		for _, webhookUrl := range definition.Webhooks {
+			if err := d.CheckUrl(webhookUrl); err != nil {
+				d.Logger.WithFields(logrus.Fields{logging.NamespaceField: payload.Namespace, logging.DefinitionIdField: payload.DefinitionId}).WithError(err).Warn("the webhook of the definition is not notified")
+				continue
+			}
+			if !slices.Contains(urls, webhookUrl) {
+				urls = append(urls, webhookUrl)
+			}
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	// We persist a delivery per webhook, all of them with the same body
+	payload.Timestamp = time.Now().UTC()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.Logger.WithFields(logrus.Fields{logging.NamespaceField: payload.Namespace, logging.DefinitionIdField: payload.DefinitionId}).WithError(err).Error("cannot encode the webhook payload")
+		return
+	}
+	for _, webhookUrl := range urls {
+		delivery := webhooks.Delivery{Id: xid.New().String(), Url: webhookUrl, Event: payload.Event, Body: string(body), NextAttempt: payload.Timestamp}
+		if err := (*d.Datastore).AddDelivery(&delivery); err != nil {
+			d.Logger.WithFields(logrus.Fields{logging.DefinitionIdField: payload.DefinitionId, "delivery_id": delivery.Id}).WithError(err).Error("cannot store the webhook delivery")
+		}
+	}
+
+	// We wake up the delivery loop without blocking
+	select {
+	case d.signal <- struct{}{}:
+	default:
+	}
+}
+
+// prune function removes the failed deliveries whose retention period has expired. It takes as input
+// the current time.
+func (d *Dispatcher) prune(now time.Time) {
+	if err := (*d.Datastore).DeleteFailedDeliveries(now.Add(-d.Retention)); err != nil {
+		d.Logger.WithError(err).Error("cannot remove the expired webhook deliveries")
+	}
+}
+
+// deliverDue function sends the pending deliveries whose next attempt is due. It takes as input the
+// current time.
+func (d *Dispatcher) deliverDue(now time.Time) {
+	deliveries, err := (*d.Datastore).ListPendingDeliveries()
+	if err != nil {
+		d.Logger.WithError(err).Error("cannot list the pending webhook deliveries")
+		return
+	}
+	for _, delivery := range *deliveries {
+		if delivery.NextAttempt.After(now) {
+			continue
+		}
+		d.deliver(&delivery, now)
+	}
+}
+
+// deliver function sends a delivery and records the outcome in the datastore: delivered payloads are
+// removed, while failed ones are scheduled again or marked as failed once they exhaust their attempts.
+// It takes as input the pointer of the Delivery and the current time.
+func (d *Dispatcher) deliver(delivery *webhooks.Delivery, now time.Time) {
+	sendErr := d.send(delivery)
+	if sendErr == nil {
+		if err := (*d.Datastore).DeleteDelivery(delivery.Id); err != nil {
+			d.Logger.WithField("delivery_id", delivery.Id).WithError(err).Error("cannot remove the webhook delivery")
+		}
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Failed = true
+		delivery.FailedAt = now
+		d.Logger.WithFields(logrus.Fields{"delivery_id": delivery.Id, "url": delivery.Url, "attempts": delivery.Attempts}).WithError(sendErr).Warn("the webhook delivery has failed")
+	} else {
+		delivery.NextAttempt = now.Add(d.backoff(delivery.Attempts))
+	}
+	if err := (*d.Datastore).UpdateDelivery(delivery); err != nil {
+		d.Logger.WithField("delivery_id", delivery.Id).WithError(err).Error("cannot update the webhook delivery")
+	}
+}
+
+// backoff function computes the time to wait before the next attempt of a delivery. It takes as
+// input the number of attempts already made. Returns the duration, which doubles with each attempt.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.Backoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// send function posts the body of a delivery to its webhook. It takes as input the pointer of the
+// Delivery. Returns an error variable if the request fails or the webhook does not accept it.
+func (d *Dispatcher) send(delivery *webhooks.Delivery) error {
+	request, err := http.NewRequest(http.MethodPost, delivery.Url, strings.NewReader(delivery.Body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhooks.EventHeader, string(delivery.Event))
+	request.Header.Set(webhooks.DeliveryHeader, delivery.Id)
+	request.Header.Set(webhooks.SignatureHeader, webhooks.Sign(d.Secret, []byte(delivery.Body)))
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("the webhook answered with the status %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package dispatchers
+
+import (
+	"context"
+	"dag/hector/golang/module/pkg/datastores"
+	"dag/hector/golang/module/pkg/datastores/dbmock"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/events"
+	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/webhooks"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	dispatcher, _ := NewDispatcher(nil, "secret", nil, nil)
+
+	var tests = []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{20, time.Hour},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := dispatcher.backoff(tt.attempts); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+
+	// Local webhook that rejects the first request and checks the signature of the rest
+	type request struct {
+		event    string
+		verified bool
+		payload  webhooks.Payload
+	}
+	var received []request
+	reject := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if reject {
+			reject = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		req := request{event: r.Header.Get(webhooks.EventHeader), verified: webhooks.Verify("secret", body, r.Header.Get(webhooks.SignatureHeader))}
+		json.Unmarshal(body, &req.payload)
+		received = append(received, req)
+	}))
+	defer server.Close()
+
+	// The definition registers the webhook (whose local host has to be allowed)
+	var datastore datastores.Datastore = dbmock.NewDBMock()
+	datastore.AddDefinition(&definitions.Definition{Id: "Def-ID", Namespace: "default", Webhooks: []string{server.URL}})
+	dispatcher, _ := NewDispatcher(&datastore, "secret", nil, []string{"127.0.0.1"})
+
+	// Only the final statuses are notified
+	dispatcher.HandleEvent(events.Event{Namespace: "default", DefinitionId: "Def-ID", ResultJob: results.ResultJob{Id: "J1", Name: "A", Status: results.Running}})
+	dispatcher.HandleEvent(events.Event{Namespace: "default", DefinitionId: "Def-ID", ResultJob: results.ResultJob{Id: "J1", Name: "A", Status: results.Done}})
+	dispatcher.DefinitionFinished(&results.ResultDefinition{Id: "Def-ID", Namespace: "default", ResultJobs: []results.ResultJob{{Id: "J1", Name: "A", Status: results.Done}}})
+	dispatcher.Flush(context.Background())
+
+	pending, _ := datastore.ListPendingDeliveries()
+	if len(*pending) != 2 {
+		t.Fatal("got ", len(*pending), " stored deliveries, want 2")
+	}
+
+	// The rejected delivery is kept and scheduled again
+	now := time.Now()
+	dispatcher.deliverDue(now)
+	pending, _ = datastore.ListPendingDeliveries()
+	if len(*pending) != 1 || (*pending)[0].Attempts != 1 || (*pending)[0].NextAttempt != now.Add(defaultBackoff) {
+		t.Fatal("The failed delivery must be persisted with its next attempt, got ", *pending)
+	}
+
+	// It is not sent again before its backoff expires, and it is removed once it is accepted
+	dispatcher.deliverDue(now)
+	if len(received) != 1 {
+		t.Fatal("got ", len(received), " received requests, want 1")
+	}
+	dispatcher.deliverDue(now.Add(defaultBackoff))
+	pending, _ = datastore.ListPendingDeliveries()
+	if len(*pending) != 0 || len(received) != 2 {
+		t.Fatal("got ", len(*pending), " pending deliveries and ", len(received), " received requests, want 0 and 2")
+	}
+
+	var tests = []struct {
+		event  string
+		status string
+	}{
+		{"definition", "Done"},
+		{"job", "Done"},
+	}
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			req := received[i]
+			if req.event != tt.event || string(req.payload.Event) != tt.event || req.payload.Status != tt.status {
+				t.Error("got ", req.event, " ", req.payload.Status, ", want ", tt.event, " ", tt.status)
+			}
+			if !req.verified {
+				t.Error("The signature of the payload is not valid")
+			}
+			if req.payload.DefinitionId != "Def-ID" || req.payload.Namespace != "default" {
+				t.Error("The payload does not identify the definition: ", req.payload)
+			}
+		})
+	}
+}
+
+func TestFailedDeliveries(t *testing.T) {
+
+	// Local webhook that always fails
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// The server-wide webhooks are notified even if the definition has none
+	dbm := dbmock.NewDBMock()
+	var datastore datastores.Datastore = dbm
+	datastore.AddDefinition(&definitions.Definition{Id: "Def-ID", Namespace: "default"})
+	dispatcher, _ := NewDispatcher(&datastore, "secret", []string{server.URL}, nil)
+	dispatcher.MaxAttempts = 2
+	dispatcher.HandleEvent(events.Event{Namespace: "default", DefinitionId: "Def-ID", ResultJob: results.ResultJob{Id: "J1", Name: "A", Status: results.Error}})
+	dispatcher.Flush(context.Background())
+
+	// The delivery is marked as failed once it exhausts its attempts
+	now := time.Now()
+	dispatcher.deliverDue(now)
+	dispatcher.deliverDue(now.Add(time.Hour))
+
+	pending, _ := datastore.ListPendingDeliveries()
+	if len(*pending) != 0 {
+		t.Error("A delivery that exhausts its attempts must not be pending")
+	}
+	if len(dbm.DeliveryStructs) != 1 || !dbm.DeliveryStructs[0].Failed || dbm.DeliveryStructs[0].LastError == "" {
+		t.Error("The failed delivery must be kept with its last error, got ", dbm.DeliveryStructs)
+	}
+
+	// And it is removed once its retention period expires
+	dispatcher.prune(now.Add(time.Hour + defaultRetention - time.Minute))
+	if len(dbm.DeliveryStructs) != 1 {
+		t.Error("The failed delivery must be kept during its retention period")
+	}
+	dispatcher.prune(now.Add(time.Hour + defaultRetention + time.Minute))
+	if len(dbm.DeliveryStructs) != 0 {
+		t.Error("The failed delivery must be removed after its retention period, got ", dbm.DeliveryStructs)
+	}
+}
+
+func TestNewDispatcherWithoutSecret(t *testing.T) {
+	if _, err := NewDispatcher(nil, "", nil, nil); err == nil {
+		t.Error("The payloads cannot be sent unsigned")
+	}
+}
+
+func TestCheckUrl(t *testing.T) {
+	open, _ := NewDispatcher(nil, "secret", nil, nil)
+	restricted, _ := NewDispatcher(nil, "secret", nil, []string{"hooks.example.com", "10.0.0.5"})
+
+	var tests = []struct {
+		dispatcher *Dispatcher
+		url        string
+		valid      bool
+	}{
+		{open, "https://example.com/hooks/hector", true},
+		{open, "http://93.184.216.34:8080/hooks", true},
+		{open, "ftp://example.com/hooks", false},
+		{open, "example.com/hooks", false},
+		{open, "http://localhost:8080/hooks", false},
+		{open, "http://127.0.0.1/hooks", false},
+		{open, "http://[::1]/hooks", false},
+		{open, "http://169.254.169.254/latest/meta-data", false},
+		{open, "http://192.168.1.10/hooks", false},
+		{open, "http://10.0.0.5/hooks", false},
+		{restricted, "https://hooks.example.com/hector", true},
+		{restricted, "http://10.0.0.5/hooks", true},
+		{restricted, "https://example.com/hooks/hector", false},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if err := tt.dispatcher.CheckUrl(tt.url); (err == nil) != tt.valid {
+				t.Error("got ", err, " for ", tt.url, ", want valid ", tt.valid)
+			}
+		})
+	}
+}
+
+func TestPrivateWebhooks(t *testing.T) {
+
+	// Local webhook that must never be reached
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	// The webhooks of the definitions cannot point to a private address, neither directly nor through
+	// a name that resolves to it
+	dbm := dbmock.NewDBMock()
+	var datastore datastores.Datastore = dbm
+	datastore.AddDefinition(&definitions.Definition{Id: "Def-ID", Namespace: "default", Webhooks: []string{server.URL}})
+	dispatcher, _ := NewDispatcher(&datastore, "secret", nil, nil)
+	dispatcher.HandleEvent(events.Event{Namespace: "default", DefinitionId: "Def-ID", ResultJob: results.ResultJob{Id: "J1", Name: "A", Status: results.Done}})
+	dispatcher.Flush(context.Background())
+	if len(dbm.DeliveryStructs) != 0 {
+		t.Error("No delivery must be stored for a private webhook, got ", dbm.DeliveryStructs)
+	}
+
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	if err := dispatcher.send(&webhooks.Delivery{Id: "D-ID", Url: "http://localhost:" + port, Body: "{}"}); err == nil || reached {
+		t.Error("The request to a name that resolves to a private address must be refused")
+	}
+}
+
+func TestRedirects(t *testing.T) {
+
+	// Local webhook that redirects to itself, either through the same host or through a name that is
+	// not allowed
+	reached := false
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, server.URL+"/target", http.StatusTemporaryRedirect)
+		case "/other":
+			http.Redirect(w, r, "http://localhost:"+port+"/target", http.StatusTemporaryRedirect)
+		case "/target":
+			reached = true
+		}
+	}))
+	defer server.Close()
+
+	// The name could be reached (as it is the host of a server-wide webhook), but the webhooks of the
+	// definitions cannot point to it
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	dispatcher, _ := NewDispatcher(nil, "secret", []string{"http://localhost:" + port}, []string{"127.0.0.1"})
+
+	var tests = []struct {
+		path     string
+		followed bool
+	}{
+		{"/same", true},
+		{"/other", false},
+	}
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			reached = false
+			err := dispatcher.send(&webhooks.Delivery{Id: "D-ID", Url: server.URL + tt.path, Body: "{}"})
+			if (err == nil) != tt.followed || reached != tt.followed {
+				t.Error("got ", err, " and reached ", reached, " for ", tt.path, ", want followed ", tt.followed)
+			}
+		})
+	}
+}
+
+// blockingDatastore is a datastore whose definitions cannot be read until it is released.
+type blockingDatastore struct {
+	datastores.Datastore
+	release chan struct{}
+}
+
+func (b *blockingDatastore) GetDefinition(namespace string, id string) (*definitions.Definition, error) {
+	<-b.release
+	return b.Datastore.GetDefinition(namespace, id)
+}
+
+func TestHandleEventInBackground(t *testing.T) {
+
+	// The datastore blocks the storage of the deliveries until the event has been handled
+	dbm := dbmock.NewDBMock()
+	dbm.AddDefinition(&definitions.Definition{Id: "Def-ID", Namespace: "default"})
+	blocking := &blockingDatastore{Datastore: dbm, release: make(chan struct{})}
+	var datastore datastores.Datastore = blocking
+	dispatcher, _ := NewDispatcher(&datastore, "secret", []string{"http://webhooks.example.com"}, nil)
+
+	handled := make(chan struct{})
+	go func() {
+		dispatcher.HandleEvent(events.Event{Namespace: "default", DefinitionId: "Def-ID", ResultJob: results.ResultJob{Id: "J1", Name: "A", Status: results.Done}})
+		close(handled)
+	}()
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("The event must be handled without waiting for the datastore")
+	}
+
+	// The delivery is stored once the datastore answers
+	close(blocking.release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := dispatcher.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(dbm.DeliveryStructs) != 1 {
+		t.Error("got ", len(dbm.DeliveryStructs), " stored deliveries, want 1")
+	}
+}
@@ -1,5 +1,10 @@
 package errors
 
+import (
+	"strconv"
+	"strings"
+)
+
 type ElementNotFoundErr struct {
 	Type string
 	Id   string
@@ -21,3 +26,133 @@ type DuplicateIDErr struct {
 func (e *DuplicateIDErr) Error() string {
 	return "A " + e.Type + " with id " + e.Id + " is already stored in the database."
 }
+
+type EmptyQueueErr struct{}
+
+// Error function applied on a variable of type EmptyQueueErr
+// returns the corresponding error message in the form of string.
+func (e *EmptyQueueErr) Error() string {
+	return "There are no definitions waiting in the queue."
+}
+
+type ReferencedElementErr struct {
+	Type             string
+	Id               string
+	ReferencedByType string
+	ReferencedById   string
+}
+
+// Error function applied on a variable of type ReferencedElementErr
+// returns the corresponding error message in the form of string.
+func (e *ReferencedElementErr) Error() string {
+	return "The " + e.Type + " with id " + e.Id + " cannot be deleted because it is referenced by the " + e.ReferencedByType + " with id " + e.ReferencedById + "."
+}
+
+type InvalidRequestErr struct {
+	Field   string
+	Line    int // Line of the field in YAML documents (zero if it is unknown)
+	Message string
+}
+
+// Error function applied on a variable of type InvalidRequestErr
+// returns the corresponding error message in the form of string,
+// prefixed by the line of the field if it is known.
+func (e *InvalidRequestErr) Error() string {
+	if e.Line > 0 {
+		return "line " + strconv.Itoa(e.Line) + ": " + e.Message
+	}
+	return e.Message
+}
+
+type ValidationErr struct {
+	Field   string
+	Line    int    // Line of the field in YAML documents (zero if it is unknown)
+	Code    string // Machine code of the problem (e.g. invalid_reference), if it is known
+	Message string
+}
+
+// Error function applied on a variable of type ValidationErr
+// returns the corresponding error message in the form of string,
+// prefixed by the line of the field if it is known.
+func (e *ValidationErr) Error() string {
+	if e.Line > 0 {
+		return "line " + strconv.Itoa(e.Line) + ": " + e.Message
+	}
+	return e.Message
+}
+
+type ExecutorErr struct {
+	JobId   string
+	JobName string
+	Err     error
+}
+
+// Error function applied on a variable of type ExecutorErr
+// returns the corresponding error message in the form of string.
+func (e *ExecutorErr) Error() string {
+	return "The executor failed while running the job " + e.JobName + " with id " + e.JobId + ": " + e.Err.Error()
+}
+
+// Unwrap function applied on a variable of type ExecutorErr
+// returns the error reported by the executor.
+func (e *ExecutorErr) Unwrap() error {
+	return e.Err
+}
+
+type InvalidStateErr struct {
+	Type    string
+	Id      string
+	Message string
+}
+
+// Error function applied on a variable of type InvalidStateErr
+// returns the corresponding error message in the form of string.
+func (e *InvalidStateErr) Error() string {
+	return "The " + e.Type + " with id " + e.Id + " " + e.Message + "."
+}
+
+type UnauthorizedErr struct {
+	Message string
+}
+
+// Error function applied on a variable of type UnauthorizedErr
+// returns the corresponding error message in the form of string.
+func (e *UnauthorizedErr) Error() string {
+	return e.Message
+}
+
+type ForbiddenErr struct {
+	Message string
+}
+
+// Error function applied on a variable of type ForbiddenErr
+// returns the corresponding error message in the form of string.
+func (e *ForbiddenErr) Error() string {
+	return e.Message
+}
+
+type ConversionErr struct {
+	Source   string
+	Problems []ValidationErr
+}
+
+// Error function applied on a variable of type ConversionErr
+// returns the corresponding error message in the form of string,
+// which lists every problem found in the converted document.
+func (e *ConversionErr) Error() string {
+	return "The " + e.Source + " cannot be converted: " + joinProblems(e.Problems) + "."
+}
+
+// joinProblems function lists some validation problems in a string,
+// each one of them prefixed by its line (if it is known) and its field.
+func joinProblems(validationErrs []ValidationErr) string {
+	var problems []string
+	for _, problem := range validationErrs {
+		location := problem.Field
+		if problem.Line > 0 {
+			location = "line " + strconv.Itoa(problem.Line) + ": " + location
+		}
+		problems = append(problems, location+": "+problem.Message)
+	}
+	return strings.Join(problems, "; ")
+}
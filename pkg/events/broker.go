@@ -0,0 +1,120 @@
+package events
+
+import (
+	"dag/hector/golang/module/pkg/results"
+	"sync"
+)
+
+// subscriberBuffer is the number of events that can be pending delivery to a subscriber. Slower
+// subscribers are disconnected so that they never block the execution of the definitions.
+const subscriberBuffer = 64
+
+// Event reports the transition of a job belonging to a running definition.
+type Event struct {
+	Namespace    string            `json:"namespace"`
+	DefinitionId string            `json:"definitionId"`
+	ResultJob    results.ResultJob `json:"resultJob"`
+}
+
+// Broker distributes the events published during the execution of the definitions among the
+// subscribers of each definition and the handlers of all of them.
+type Broker struct {
+	mutex       sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+	handlers    []func(Event)
+}
+
+// NewBroker function creates a new instance of the Broker type. It returns a pointer to the
+// constructed variable.
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe function registers a new subscriber to the events of a definition. It takes as input
+// the identifier of the definition. Returns the channel through which the events are received
+// and the function that cancels the subscription. The channel is closed when the execution of the
+// definition ends, when the subscription is cancelled or when the subscriber falls behind.
+func (b *Broker) Subscribe(definitionId string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mutex.Lock()
+	if b.subscribers[definitionId] == nil {
+		b.subscribers[definitionId] = make(map[chan Event]struct{})
+	}
+	b.subscribers[definitionId][ch] = struct{}{}
+	b.mutex.Unlock()
+
+	unsubscribe := func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		b.remove(definitionId, ch)
+	}
+	return ch, unsubscribe
+}
+
+// Subscribers function returns the number of subscribers of a definition.
+func (b *Broker) Subscribers(definitionId string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subscribers[definitionId])
+}
+
+// Handle function registers a function that is called with the events of every definition. Unlike
+// subscribers, handlers never miss an event: they are called synchronously by the publisher, so
+// they must return quickly. It takes as input the handler function.
+func (b *Broker) Handle(handler func(Event)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish function delivers an event to all the subscribers of its definition and to the handlers.
+// It never blocks on the subscribers: those whose buffer is full are disconnected. It can be called
+// on a nil Broker, in which case the event is discarded.
+func (b *Broker) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	for ch := range b.subscribers[event.DefinitionId] {
+		select {
+		case ch <- event:
+		default:
+			b.remove(event.DefinitionId, ch)
+		}
+	}
+	handlers := b.handlers
+	b.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Close function notifies the subscribers of a definition that its execution has ended by
+// closing their channels. It can be called on a nil Broker.
+func (b *Broker) Close(definitionId string) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for ch := range b.subscribers[definitionId] {
+		b.remove(definitionId, ch)
+	}
+}
+
+// remove function closes the channel of a subscriber and forgets it. It must be called with
+// the mutex held and ignores subscribers that have already been removed.
+func (b *Broker) remove(definitionId string, ch chan Event) {
+	if _, exists := b.subscribers[definitionId][ch]; !exists {
+		return
+	}
+	close(ch)
+	delete(b.subscribers[definitionId], ch)
+	if len(b.subscribers[definitionId]) == 0 {
+		delete(b.subscribers, definitionId)
+	}
+}
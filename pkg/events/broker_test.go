@@ -0,0 +1,87 @@
+package events
+
+import (
+	"dag/hector/golang/module/pkg/results"
+	"strconv"
+	"testing"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+
+	// Subscribers of two different definitions
+	events1, unsubscribe1 := broker.Subscribe("D1")
+	events2, _ := broker.Subscribe("D2")
+
+	broker.Publish(Event{DefinitionId: "D1", ResultJob: results.ResultJob{Id: "J1", Status: results.Running}})
+	broker.Publish(Event{DefinitionId: "D1", ResultJob: results.ResultJob{Id: "J1", Status: results.Done}})
+
+	var tests = []struct {
+		status results.Status
+	}{
+		{results.Running},
+		{results.Done},
+	}
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			event := <-events1
+			if event.ResultJob.Status != tt.status {
+				t.Error("got " + event.ResultJob.Status.String() + ", want " + tt.status.String())
+			}
+		})
+	}
+
+	// Events of other definitions are not received
+	select {
+	case event := <-events2:
+		t.Error("Unexpected event received for definition " + event.DefinitionId)
+	default:
+	}
+
+	// Cancelling a subscription closes its channel (and may be done twice)
+	unsubscribe1()
+	unsubscribe1()
+	if _, open := <-events1; open {
+		t.Error("The channel of a cancelled subscription must be closed")
+	}
+
+	// Closing a definition closes the channels of its subscribers
+	broker.Close("D2")
+	if _, open := <-events2; open {
+		t.Error("The channel of a finished definition must be closed")
+	}
+
+	// Subscribers that fall behind are disconnected instead of blocking the publisher
+	events3, _ := broker.Subscribe("D3")
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(Event{DefinitionId: "D3"})
+	}
+	received := 0
+	for range events3 {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Error("got ", received, " buffered events, want ", subscriberBuffer)
+	}
+}
+
+func TestBrokerHandlers(t *testing.T) {
+	broker := NewBroker()
+
+	// Handlers receive the events of every definition, even those without subscribers
+	var received []string
+	broker.Handle(func(event Event) {
+		received = append(received, event.DefinitionId)
+	})
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(Event{DefinitionId: "D" + strconv.Itoa(i%2)})
+	}
+
+	if len(received) != subscriberBuffer+1 {
+		t.Error("got ", len(received), " handled events, want ", subscriberBuffer+1)
+	}
+	if received[0] != "D0" || received[1] != "D1" {
+		t.Error("The events are not handled in the order in which they are published: ", received[:2])
+	}
+}
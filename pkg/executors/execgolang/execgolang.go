@@ -2,16 +2,19 @@ package execgolang
 
 import (
 	"context"
-	"fmt"
 	"io"
 
-	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/executors"
 	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/metrics"
 	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/tracing"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/sirupsen/logrus"
 )
 
 // readerToString function extracts the content of an io.ReadCloser variable and returns it as a string.
@@ -43,39 +46,34 @@ func checkIfAvailable(ctx context.Context, cli *client.Client, image string) (bo
 	return false, nil
 }
 
-// argumentsToSlice function takes Hector's own parameter definitions and converts
-// them into an array of strings by adding dashes to the tags.
-func argumentsToSlice(arguments *[]definitions.Parameter) []string {
-	var args []string
-	for _, arg := range *arguments {
-		args = append(args, "--"+arg.Name)
-		args = append(args, arg.Value.(string))
-	}
-	return args
+type ExecGolang struct {
+	Logger logrus.FieldLogger
 }
 
-type ExecGolang struct{}
-
 // NewExecGolang function creates a new instance of the ExecGolang type. It
 // returns a pointer to the constructed variable.
 func NewExecGolang() *ExecGolang {
-	return &ExecGolang{}
+	return &ExecGolang{Logger: logging.Default()}
 }
 
-// ExecuteJob function executes a job locally. It takes as input the pointer
-// of a given Job. It provides as output a pointer to the generated ResultJob
-// and an error variable in charge of notifying any problem.
+// ExecuteJob function executes a job locally. It takes as input the context that
+// allows to cancel the execution and the pointer of a given Job. If the context is
+// cancelled while the container is running, the container is killed. It provides as
+// output a pointer to the generated ResultJob and an error variable in charge of
+// notifying any problem.
 //
 // Based on: https://docs.docker.com/engine/api/sdk/#sdk-and-api-quickstart and https://docs.docker.com/engine/api/sdk/examples/
-func (eg *ExecGolang) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
+func (eg *ExecGolang) ExecuteJob(cancelCtx context.Context, job *jobs.Job) (*results.ResultJob, error) {
 
-	// We print the initialization message and display the job information
-	fmt.Printf("Started "+job.Name+" job. Info: \n\t %+v\n\n", *job)
+	// We log the initialization message and the image of the job
+	logger := logging.ForJob(eg.Logger, cancelCtx, job.Id, job.Name)
+	logger.WithField("image", job.Image).Info("job started")
+	defer metrics.StartJob("docker")()
 
 	// We create the variable logs to store all the information associated with the definition of the job
 	var logs string
 
-	// Start context
+	// Start context (the docker requests are not bound to the cancellation context, so that the container can be killed after it)
 	ctx := context.Background()
 
 	// Start docker client
@@ -90,11 +88,15 @@ func (eg *ExecGolang) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
 		return nil, err
 	}
 	if !available {
+		_, pullSpan := tracing.Start(cancelCtx, "ImagePull")
+		pullSpan.SetAttribute("image", job.Image)
 		reader, err := cli.ImagePull(ctx, job.Image, types.ImagePullOptions{})
 		if err != nil {
+			pullSpan.End(err)
 			return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: err.Error(), Status: results.Error}, nil
 		}
 		pullLogs, err := readerToString(&reader)
+		pullSpan.End(err)
 		if err != nil {
 			return nil, err
 		}
@@ -102,36 +104,110 @@ func (eg *ExecGolang) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
 	}
 
 	// We create the container by specifying the image and the job arguments
-	args := argumentsToSlice(&job.Arguments)
+	args := executors.ArgumentsToSlice(&job.Arguments)
 	resp, err := cli.ContainerCreate(ctx, &container.Config{
 		Image: job.Image,
 		Cmd:   args,
-	}, nil, nil, nil, "")
+	}, nil, nil, nil, containerName(job))
 	if err != nil {
 		return nil, err
 	}
 
+	// The container is removed once its logs have been collected, so that the job can be executed again
+	defer cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+
 	// We run the container
 	ContStartErr := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
 	if ContStartErr != nil {
 		return nil, ContStartErr
 	}
 
+	return followContainer(ctx, cancelCtx, cli, job, resp.ID, logs, logger)
+}
+
+// ReconcileJob function follows the container of a job started before a restart of the server. If
+// the container of the job still exists, it waits for it and collects its result as ExecuteJob does,
+// removing the container afterwards. It takes as input the context that allows to cancel the
+// execution and the pointer of the Job. It provides as output a pointer to the ResultJob (nil if
+// there is no container for the job) and an error variable in charge of notifying any problem.
+func (eg *ExecGolang) ReconcileJob(cancelCtx context.Context, job *jobs.Job) (*results.ResultJob, error) {
+	ctx := context.Background()
+
+	// Start docker client
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+
+	// We look for the container of the job
+	cont, err := cli.ContainerInspect(ctx, containerName(job))
+	if client.IsErrNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer metrics.StartJob("docker")()
+	defer cli.ContainerRemove(ctx, cont.ID, types.ContainerRemoveOptions{Force: true})
+	logger := logging.ForJob(eg.Logger, cancelCtx, job.Id, job.Name)
+	logger.WithField("container_id", cont.ID).Info("job reconciled with its container")
+
+	return followContainer(ctx, cancelCtx, cli, job, cont.ID, "", logger)
+}
+
+// Ping function checks that the docker daemon can be reached. It takes as input the context of the
+// check and returns an error variable in charge of notifying any problem.
+func (eg *ExecGolang) Ping(ctx context.Context) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	_, err = cli.Ping(ctx)
+	return err
+}
+
+// containerName function returns the name of the container of a job, which allows to find it
+// again after a restart of the server.
+func containerName(job *jobs.Job) string {
+	return "hector-" + job.Id
+}
+
+// followContainer function waits until the container of a job finishes and collects its result. If
+// the cancellation context is cancelled first, the container is killed. It takes as input the
+// context of the docker requests, the cancellation context, the docker client, the pointer of the
+// Job, the id of its container, the logs gathered so far and the logger of the job. It provides as output a pointer to the
+// generated ResultJob and an error variable in charge of notifying any problem.
+func followContainer(ctx context.Context, cancelCtx context.Context, cli *client.Client, job *jobs.Job, id string, logs string, logger *logrus.Entry) (*results.ResultJob, error) {
+
 	// We wait for its definition to be completed.
-	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	_, runSpan := tracing.Start(cancelCtx, "ContainerRun")
+	runSpan.SetAttribute("container_id", id)
+	statusCh, errCh := cli.ContainerWait(ctx, id, container.WaitConditionNotRunning)
 	select {
 	case err := <-errCh:
 		if err != nil {
+			runSpan.End(err)
 			return nil, err
 		}
 	case <-statusCh:
+	case <-cancelCtx.Done():
+		runSpan.End(cancelCtx.Err())
+		if err := cli.ContainerKill(ctx, id, "SIGKILL"); err != nil {
+			return nil, err
+		}
+		logger.Info("job stopped before completion")
+		logs += "The job was stopped before completion"
+		return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: logs, Status: results.Cancelled}, nil
 	}
 
-	// We print the finalization message
-	fmt.Println("Finished " + job.Name + " job\n")
+	// We log the finalization message
+	runSpan.End(nil)
+	logger.Info("job finished")
 
 	// If the definition has reported contents in the error stream, the definition is considered failed.
-	errorReader, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStderr: true})
+	errorReader, err := cli.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStderr: true})
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +221,7 @@ func (eg *ExecGolang) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
 	}
 
 	// Otherwise, the contents of the output stream are retrieved and the definition is considered successful.
-	execReader, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true})
+	execReader, err := cli.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true})
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package execgolang
 
 import (
+	"context"
 	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/jobs"
 	"dag/hector/golang/module/pkg/results"
@@ -78,7 +79,7 @@ func TestExecuteJob(t *testing.T) {
 
 		testname := "test_" + strconv.Itoa(i)
 		t.Run(testname, func(t *testing.T) {
-			resJob, err := nomad.ExecuteJob(tt.job)
+			resJob, err := nomad.ExecuteJob(context.Background(), tt.job)
 
 			if err != nil {
 				t.Error(err)
@@ -1,34 +1,48 @@
 package execmock
 
 import (
+	"context"
 	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/metrics"
 	"dag/hector/golang/module/pkg/results"
-	"fmt"
 	"math/rand"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
-type ExecMock struct{}
+type ExecMock struct {
+	Logger logrus.FieldLogger
+}
 
 // NewExecMock function creates a new instance of the ExecMock type. It
 // returns a pointer to the constructed variable.
 func NewExecMock() *ExecMock {
-	return &ExecMock{}
+	return &ExecMock{Logger: logging.Default()}
 }
 
-// ExecuteJob function simulates the execution of a job. It takes as input the pointer
-// of a given Job. It provides as output a pointer to the generated ResultJob and an
-// error variable in charge of notifying any problem.
-func (em *ExecMock) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
+// ExecuteJob function simulates the execution of a job. It takes as input the context
+// that allows to cancel the execution and the pointer of a given Job. It provides as
+// output a pointer to the generated ResultJob and an error variable in charge of
+// notifying any problem.
+func (em *ExecMock) ExecuteJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
 
-	// We print the initialization message and display the job information
-	fmt.Printf("Started "+job.Name+" job. Info: \n\t %+v\n\n", *job)
+	// We log the initialization message and the image of the job
+	logger := logging.ForJob(em.Logger, ctx, job.Id, job.Name)
+	logger.WithField("image", job.Image).Info("job started")
+	defer metrics.StartJob("mock")()
 
 	// Simulate job definition
-	time.Sleep(5 * time.Second)
+	select {
+	case <-time.After(5 * time.Second):
+	case <-ctx.Done():
+		logger.Info("job stopped before completion")
+		return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: "The job was stopped before completion", Status: results.Cancelled}, nil
+	}
 
-	// We print the finalization message
-	fmt.Println("Finished " + job.Name + " job\n")
+	// We log the finalization message
+	logger.Info("job finished")
 
 	// We return the result of the definition, occasionally simulating the production of an error during it.
 	if rand.Float64() < 0.5 {
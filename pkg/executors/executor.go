@@ -1,10 +1,37 @@
 package executors
 
 import (
+	"context"
+	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/jobs"
 	"dag/hector/golang/module/pkg/results"
+	"fmt"
 )
 
+// Executor runs jobs. When the context is cancelled before a job finishes, the executor must stop
+// it and return its result with the Cancelled status.
 type Executor interface {
-	ExecuteJob(job *jobs.Job) (*results.ResultJob, error)
+	ExecuteJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error)
+}
+
+// Reconciler is implemented by the executors whose jobs keep running outside of the server (in a
+// nomad cluster or a docker daemon), so that the jobs left running by a restart are followed again
+// instead of being executed twice. ReconcileJob waits for a job started before the restart, which
+// is identified by its id, and returns its result as ExecuteJob does. It returns a nil ResultJob if
+// the executor does not know the job, in which case it must be executed again.
+type Reconciler interface {
+	ReconcileJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error)
+}
+
+// ArgumentsToSlice function takes Hector's own parameter definitions and converts
+// them into an array of strings by adding dashes to the tags. It is the way in which
+// every executor passes the parameters of a job to its container.
+func ArgumentsToSlice(arguments *[]definitions.Parameter) []string {
+
+	var args []string
+	for _, arg := range *arguments {
+		args = append(args, "--"+arg.Name)
+		args = append(args, fmt.Sprintf("%v", arg.Value))
+	}
+	return args
 }
@@ -0,0 +1,72 @@
+package executors
+
+import (
+	"dag/hector/golang/module/pkg/definitions"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestArgumentsToSlice(t *testing.T) {
+	var tests = []struct {
+		arguments *[]definitions.Parameter
+		array     []string
+	}{
+		{
+			arguments: &[]definitions.Parameter{
+				{
+					Name:  "name-1",
+					Value: "value-1",
+				},
+			},
+			array: []string{"--name-1", "value-1"},
+		},
+		{
+			arguments: &[]definitions.Parameter{
+				{
+					Name:  "name-2",
+					Value: 2,
+				},
+			},
+			array: []string{"--name-2", "2"},
+		},
+		{
+			arguments: &[]definitions.Parameter{
+				{
+					Name:  "name-3",
+					Value: true,
+				},
+			},
+			array: []string{"--name-3", "true"},
+		},
+		{
+			arguments: &[]definitions.Parameter{
+				{
+					Name:  "name-1",
+					Value: "value-1",
+				},
+				{
+					Name:  "name-2",
+					Value: 2,
+				},
+				{
+					Name:  "name-3",
+					Value: true,
+				},
+			},
+			array: []string{"--name-1", "value-1", "--name-2", "2", "--name-3", "true"},
+		},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			args := ArgumentsToSlice(tt.arguments)
+			if !reflect.DeepEqual(args, tt.array) {
+				t.Error("got ", args, ", want ", tt.array)
+			}
+		})
+	}
+
+}
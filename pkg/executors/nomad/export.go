@@ -0,0 +1,352 @@
+package nomad
+
+import (
+	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/jobs"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// We declare the formats in which a definition can be exported.
+const (
+	HCL  = "hcl"
+	JSON = "json"
+)
+
+// We declare the prestart task that makes each task group wait for its dependencies, which polls
+// the summary of the job through the nomad api, together with the default image and address that it
+// uses when they are not configured.
+const (
+	AwaitTaskName       = "await-dependencies"
+	DefaultAwaitImage   = "curlimages/curl:8.5.0"
+	DefaultAwaitAddress = "http://${attr.unique.network.ip-address}:4646"
+)
+
+// awaitNotes documents the requirements of the await tasks in the meta of the exported jobs.
+const awaitNotes = "The await-dependencies tasks poll the summary of this job through the nomad api: the task api " +
+	"socket (NOMAD_UNIX_ADDR) when nomad provides it, or NOMAD_ADDR otherwise. When the ACLs are enabled they need " +
+	"a NOMAD_TOKEN allowed to read the job (e.g. from a workload identity), and NOMAD_CACERT when the api is served " +
+	"over TLS with a private certificate authority."
+
+// ExportOptions contains the settings of the cluster where the exported jobs are run. The empty
+// values keep the defaults (dc1, the region of the agent, DefaultAwaitImage and DefaultAwaitAddress).
+type ExportOptions struct {
+	Region       string
+	Datacenters  []string
+	AwaitImage   string // Image of the await tasks, which must provide sh, curl and grep
+	AwaitAddress string // Address of the nomad api seen from the await tasks (without task api socket)
+}
+
+// invalidGroupNameRegexp matches the characters that are removed from the names of the task groups,
+// which are also used by the await tasks to find them in the summary of the job.
+var invalidGroupNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// BuildDefinitionJob function is responsible for constructing a single nomad batch job that runs a
+// whole definition. Each Hector task becomes a task group, built as buildJob does, and the order of
+// the tasks is preserved through a prestart task in each group that waits until the groups of its
+// dependencies have completed (or fails if any of them has failed). It takes as input the pointer
+// to the Definition, the pointer to its jobs in topological order and the settings of the cluster.
+// Returns the pointer to the constructed nomad Job.
+func BuildDefinitionJob(definition *definitions.Definition, nestedJobs *[][]jobs.Job, options *ExportOptions) *api.Job {
+
+	// 1. Group names (the dependencies refer to them)
+	groupNames := map[string]string{}
+	taken := map[string]bool{}
+	for _, jobsGroup := range *nestedJobs {
+		for _, job := range jobsGroup {
+			base := strings.Trim(invalidGroupNameRegexp.ReplaceAllString(strings.ToLower(job.Name), "-"), "-")
+			if base == "" {
+				base = "task"
+			} else if base == AwaitTaskName {
+				base = "task-" + base
+			}
+			name := base
+			for i := 2; taken[name]; i++ {
+				name = base + "-" + strconv.Itoa(i)
+			}
+			taken[name] = true
+			groupNames[job.Name] = name
+		}
+	}
+
+	// 2. Task Groups
+	var taskGroups []*api.TaskGroup
+	meta := map[string]string{"hector_definition_id": definition.Id, "hector_specification_id": definition.SpecificationId}
+	for _, jobsGroup := range *nestedJobs {
+		for i := range jobsGroup {
+			job := jobsGroup[i]
+			groupName := groupNames[job.Name]
+			nomadTaskGroup := buildJob(&job, groupName, groupName).TaskGroups[0]
+			if len(job.Dependencies) > 0 {
+				var dependencies []string
+				for _, dependency := range job.Dependencies {
+					dependencies = append(dependencies, groupNames[dependency])
+				}
+				nomadTaskGroup.Tasks = append([]*api.Task{buildAwaitTask(dependencies, options)}, nomadTaskGroup.Tasks...)
+				meta["hector_await_notes"] = awaitNotes
+			}
+			taskGroups = append(taskGroups, nomadTaskGroup)
+		}
+	}
+
+	// 3. Job
+	nomadJob := &api.Job{
+		ID:          pkg.Ptr("hector-" + definition.Id),
+		Name:        pkg.Ptr(definition.Name),
+		Type:        pkg.Ptr("batch"),
+		Datacenters: []string{"dc1"},
+		Meta:        meta,
+		TaskGroups:  taskGroups,
+		Reschedule:  &api.ReschedulePolicy{Attempts: pkg.Ptr(0)},
+	}
+	if options.Region != "" {
+		nomadJob.Region = pkg.Ptr(options.Region)
+	}
+	if len(options.Datacenters) > 0 {
+		nomadJob.Datacenters = options.Datacenters
+	}
+	return nomadJob
+}
+
+// buildAwaitTask function is responsible for constructing the prestart task of a task group, which
+// waits until the groups of its dependencies have completed. The summary is requested through the
+// task api socket if nomad provides it (otherwise through NOMAD_ADDR), with the token and the
+// certificate authority of the environment if they are set. The requests that fail are retried, so
+// that the task only fails when a dependency fails. It takes as input the names of the groups and the
+// settings of the cluster. Returns the pointer to the constructed nomad Task.
+func buildAwaitTask(dependencies []string, options *ExportOptions) *api.Task {
+	image, address := options.AwaitImage, options.AwaitAddress
+	if image == "" {
+		image = DefaultAwaitImage
+	}
+	if address == "" {
+		address = DefaultAwaitAddress
+	}
+
+	// The braces are avoided in the script, since nomad would interpolate them
+	script := `address=$NOMAD_ADDR; ` +
+		`if [ -n "$NOMAD_UNIX_ADDR" ]; then set -- --unix-socket "$(echo "$NOMAD_UNIX_ADDR" | sed 's|^unix://||')"; address=http://localhost; fi; ` +
+		`if [ -n "$NOMAD_CACERT" ]; then set -- "$@" --cacert "$NOMAD_CACERT"; fi; ` +
+		"for group in " + strings.Join(dependencies, " ") + "; do " +
+		`until summary=$(curl -sSf "$@" -H "X-Nomad-Token: $NOMAD_TOKEN" "$address/v1/job/$NOMAD_JOB_ID/summary") && echo "$summary" | grep -q "\"$group\":{[^}]*\"Complete\":[1-9]"; do ` +
+		`if echo "$summary" | grep -q "\"$group\":{[^}]*\"Failed\":[1-9]"; then echo "the task group $group has failed"; exit 1; fi; ` +
+		"sleep 5; done; done"
+
+	return &api.Task{
+		Name:      AwaitTaskName,
+		Driver:    "docker",
+		Lifecycle: &api.TaskLifecycle{Hook: api.TaskLifecycleHookPrestart},
+		Env:       map[string]string{"NOMAD_ADDR": address},
+		Config: map[string]interface{}{
+			"image":   image,
+			"command": "/bin/sh",
+			"args":    []string{"-c", script},
+		},
+		RestartPolicy: &api.RestartPolicy{Attempts: pkg.Ptr(0)},
+	}
+}
+
+// RenderJob function renders a nomad job as a job specification in the given format: HCL, to be run
+// with nomad job run, or JSON, to be run with nomad job run -json. The output is deterministic so
+// that it can be reviewed and compared. It takes as input the pointer to the nomad Job and the format.
+// Returns the rendered specification and an error variable to report any problems.
+func RenderJob(nomadJob *api.Job, format string) ([]byte, error) {
+	switch format {
+	case HCL:
+		return []byte(renderHCL(nomadJob)), nil
+	case JSON:
+		return renderJSON(nomadJob)
+	default:
+		return nil, fmt.Errorf("unknown format %s (the valid ones are %s and %s)", format, HCL, JSON)
+	}
+}
+
+// renderJSON function renders a nomad job in the JSON format of the nomad api, leaving out the
+// fields that are not set.
+func renderJSON(nomadJob *api.Job) ([]byte, error) {
+	content, err := json.Marshal(map[string]*api.Job{"Job": nomadJob})
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	content, err = json.MarshalIndent(pruneNulls(document), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// pruneNulls function removes the null values (and the objects left empty) of a json document.
+func pruneNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field = pruneNulls(field); field == nil {
+				delete(v, key)
+			} else {
+				v[key] = field
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = pruneNulls(v[i])
+		}
+	}
+	return value
+}
+
+// hclWriter builds the HCL specification of a job, indenting the nested blocks.
+type hclWriter struct {
+	strings.Builder
+	depth int
+}
+
+// line function writes a line at the current indentation.
+func (w *hclWriter) line(format string, args ...any) {
+	if format != "" {
+		w.WriteString(strings.Repeat("  ", w.depth) + fmt.Sprintf(format, args...))
+	}
+	w.WriteString("\n")
+}
+
+// open function writes the header of a block and increases the indentation.
+func (w *hclWriter) open(header string) {
+	w.line("%s {", header)
+	w.depth++
+}
+
+// close function decreases the indentation and writes the end of a block.
+func (w *hclWriter) close() {
+	w.depth--
+	w.line("}")
+}
+
+// attributes function writes a set of attributes sorted by name, aligning their values.
+func (w *hclWriter) attributes(values map[string]string) {
+	names := make([]string, 0, len(values))
+	width := 0
+	for name := range values {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w.line("%-*s = %s", width, name, values[name])
+	}
+}
+
+// renderHCL function renders the fields of a nomad job that are set by BuildDefinitionJob as an HCL
+// job specification.
+func renderHCL(nomadJob *api.Job) string {
+	w := &hclWriter{}
+	w.open("job " + hclString(*nomadJob.ID))
+	attributes := map[string]string{"name": hclString(*nomadJob.Name), "type": hclString(*nomadJob.Type), "datacenters": hclList(nomadJob.Datacenters)}
+	if nomadJob.Region != nil {
+		attributes["region"] = hclString(*nomadJob.Region)
+	}
+	w.attributes(attributes)
+	if len(nomadJob.Meta) > 0 {
+		w.line("")
+		w.open("meta")
+		w.attributes(hclStrings(nomadJob.Meta))
+		w.close()
+	}
+	if nomadJob.Reschedule != nil && nomadJob.Reschedule.Attempts != nil {
+		w.line("")
+		w.open("reschedule")
+		w.attributes(map[string]string{"attempts": strconv.Itoa(*nomadJob.Reschedule.Attempts)})
+		w.close()
+	}
+
+	for _, taskGroup := range nomadJob.TaskGroups {
+		w.line("")
+		w.open("group " + hclString(*taskGroup.Name))
+		renderRestart(w, taskGroup.RestartPolicy)
+		for _, task := range taskGroup.Tasks {
+			w.line("")
+			w.open("task " + hclString(task.Name))
+			w.attributes(map[string]string{"driver": hclString(task.Driver)})
+			if task.Lifecycle != nil {
+				w.line("")
+				w.open("lifecycle")
+				w.attributes(map[string]string{"hook": hclString(task.Lifecycle.Hook)})
+				w.close()
+			}
+			if len(task.Env) > 0 {
+				w.line("")
+				w.open("env")
+				w.attributes(hclStrings(task.Env))
+				w.close()
+			}
+			w.line("")
+			w.open("config")
+			config := map[string]string{}
+			for name, value := range task.Config {
+				switch v := value.(type) {
+				case []string:
+					config[name] = hclList(v)
+				default:
+					config[name] = hclString(fmt.Sprintf("%v", v))
+				}
+			}
+			w.attributes(config)
+			w.close()
+			w.line("")
+			renderRestart(w, task.RestartPolicy)
+			w.close()
+		}
+		w.close()
+	}
+	w.close()
+	return w.String()
+}
+
+// renderRestart function writes the restart block of a task group or a task.
+func renderRestart(w *hclWriter, restartPolicy *api.RestartPolicy) {
+	if restartPolicy == nil || restartPolicy.Attempts == nil {
+		return
+	}
+	w.open("restart")
+	w.attributes(map[string]string{"attempts": strconv.Itoa(*restartPolicy.Attempts)})
+	w.close()
+}
+
+// hclString function quotes a string for an HCL specification. The runtime interpolations of
+// nomad (${...}) are kept, while the template directives (%{...}) are escaped.
+func hclString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "%{", "%%{").Replace(s)
+	return `"` + s + `"`
+}
+
+// hclList function renders a list of strings for an HCL specification.
+func hclList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = hclString(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// hclStrings function quotes the values of a map of strings for an HCL specification.
+func hclStrings(values map[string]string) map[string]string {
+	quoted := map[string]string{}
+	for name, value := range values {
+		quoted[name] = hclString(value)
+	}
+	return quoted
+}
@@ -0,0 +1,151 @@
+package nomad
+
+import (
+	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/jobs"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBuildDefinitionJob(t *testing.T) {
+	definition := definitions.Definition{Id: "Def-ID", Name: "Toy Definition", SpecificationId: "Spec-ID"}
+	nestedJobs := [][]jobs.Job{
+		{
+			{Id: "Job-1", Name: "Concat Messages 1", Image: "concat", Arguments: []definitions.Parameter{{Name: "message-1", Value: "Hello"}}},
+			{Id: "Job-2", Name: "Concat Messages 2", Image: "concat", Arguments: []definitions.Parameter{{Name: "message-1", Value: "Bye"}}},
+		},
+		{
+			{Id: "Job-3", Name: "Count Letters", Image: "count", Arguments: []definitions.Parameter{{Name: "times", Value: 2}}, Dependencies: []string{"Concat Messages 1", "Concat Messages 2"}},
+		},
+	}
+
+	options := ExportOptions{Region: "eu", Datacenters: []string{"eu-west-1"}, AwaitImage: "registry.local/curl:8", AwaitAddress: "https://nomad.service.consul:4646"}
+	nomadJob := BuildDefinitionJob(&definition, &nestedJobs, &options)
+
+	// The job is placed in the configured region and datacenters
+	if *nomadJob.Region != "eu" || fmt.Sprint(nomadJob.Datacenters) != "[eu-west-1]" {
+		t.Error("got region ", *nomadJob.Region, " and datacenters ", nomadJob.Datacenters)
+	}
+
+	// Each task is a task group, and the groups with dependencies wait for them
+	if *nomadJob.ID != "hector-Def-ID" || *nomadJob.Type != "batch" || len(nomadJob.TaskGroups) != 3 {
+		t.Fatal("got job ", *nomadJob.ID, " with ", len(nomadJob.TaskGroups), " task groups")
+	}
+	var tests = []struct {
+		group string
+		tasks []string
+		args  string
+	}{
+		{"concat-messages-1", []string{"concat-messages-1"}, "[--message-1 Hello]"},
+		{"concat-messages-2", []string{"concat-messages-2"}, "[--message-1 Bye]"},
+		{"count-letters", []string{AwaitTaskName, "count-letters"}, "[--times 2]"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			taskGroup := nomadJob.TaskGroups[i]
+			if *taskGroup.Name != tt.group || len(taskGroup.Tasks) != len(tt.tasks) {
+				t.Fatal("got group ", *taskGroup.Name, " with ", len(taskGroup.Tasks), " tasks, want ", tt.group, " with ", len(tt.tasks))
+			}
+			for j, task := range taskGroup.Tasks {
+				if task.Name != tt.tasks[j] {
+					t.Error("got task ", task.Name, ", want ", tt.tasks[j])
+				}
+			}
+			mainTask := taskGroup.Tasks[len(taskGroup.Tasks)-1]
+			if fmt.Sprint(mainTask.Config["args"]) != tt.args {
+				t.Error("got args ", mainTask.Config["args"], ", want ", tt.args)
+			}
+		})
+	}
+
+	// The await task polls the groups of the dependencies with the configured image and address, through
+	// the task api socket and with the token of its environment if they are provided
+	awaitTask := nomadJob.TaskGroups[2].Tasks[0]
+	script := awaitTask.Config["args"].([]string)[1]
+	if awaitTask.Lifecycle.Hook != "prestart" || !strings.Contains(script, "for group in concat-messages-1 concat-messages-2;") {
+		t.Error("got await task ", awaitTask.Config)
+	}
+	if awaitTask.Config["image"] != "registry.local/curl:8" || awaitTask.Env["NOMAD_ADDR"] != "https://nomad.service.consul:4646" {
+		t.Error("got await image ", awaitTask.Config["image"], " and env ", awaitTask.Env)
+	}
+	for _, fragment := range []string{"--unix-socket", "$NOMAD_UNIX_ADDR", "X-Nomad-Token: $NOMAD_TOKEN", "--cacert"} {
+		if !strings.Contains(script, fragment) {
+			t.Error("The await script does not contain ", fragment)
+		}
+	}
+	if strings.Contains(script, "${") {
+		t.Error("The await script must not contain braces, which are interpolated by nomad")
+	}
+	if nomadJob.Meta["hector_await_notes"] == "" {
+		t.Error("The requirements of the await tasks are not documented in the job")
+	}
+}
+
+func TestRenderJob(t *testing.T) {
+	definition := definitions.Definition{Id: "Def-ID", Name: "Toy Definition", SpecificationId: "Spec-ID"}
+	nestedJobs := [][]jobs.Job{{{Id: "Job-1", Name: "Say", Image: "alpine", Arguments: []definitions.Parameter{{Name: "message", Value: `a "quoted" %{value}`}}}}}
+	nomadJob := BuildDefinitionJob(&definition, &nestedJobs, &ExportOptions{})
+
+	hcl := `job "hector-Def-ID" {
+  datacenters = ["dc1"]
+  name        = "Toy Definition"
+  type        = "batch"
+
+  meta {
+    hector_definition_id    = "Def-ID"
+    hector_specification_id = "Spec-ID"
+  }
+
+  reschedule {
+    attempts = 0
+  }
+
+  group "say" {
+    restart {
+      attempts = 0
+    }
+
+    task "say" {
+      driver = "docker"
+
+      config {
+        args  = ["--message", "a \"quoted\" %%{value}"]
+        image = "alpine"
+      }
+
+      restart {
+        attempts = 0
+      }
+    }
+  }
+}
+`
+
+	var tests = []struct {
+		format string
+		want   string
+	}{
+		{HCL, hcl},
+		{JSON, "\"TaskGroups\": [\n      {\n        \"Name\": \"say\",\n        \"RestartPolicy\": {\n          \"Attempts\": 0\n        },"},
+		{"yaml", "unknown format yaml (the valid ones are hcl and json)"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			content, err := RenderJob(nomadJob, tt.format)
+			if err != nil {
+				content = []byte(err.Error())
+			}
+			if tt.format == HCL && string(content) != tt.want || !strings.Contains(string(content), tt.want) {
+				t.Error("got ", string(content), ", want ", tt.want)
+			}
+		})
+	}
+}
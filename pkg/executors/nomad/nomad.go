@@ -1,45 +1,88 @@
 package nomad
 
 import (
+	"context"
 	"dag/hector/golang/module/pkg"
-	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/executors"
 	"dag/hector/golang/module/pkg/jobs"
+	"dag/hector/golang/module/pkg/logging"
+	"dag/hector/golang/module/pkg/metrics"
 	"dag/hector/golang/module/pkg/results"
+	"dag/hector/golang/module/pkg/tracing"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/slices"
 )
 
+// notFoundResponse is the error reported by the nomad api for unknown elements.
+const notFoundResponse = "Unexpected response code: 404"
+
 type Nomad struct {
-	Client *api.Client
+	Client      *api.Client
+	Region      string
+	Datacenters []string
+	Logger      logrus.FieldLogger
 }
 
-// NewNomad function creates a new instance of the Nomad type. It returns a pointer to the
-// constructed variable.
-func NewNomad() *Nomad {
+// NewNomad function creates a new instance of the Nomad type. It takes as input the address of
+// the nomad agent, the region and the datacenters where the jobs are placed (the empty values keep
+// the defaults of the nomad client and dc1). It returns a pointer to the constructed variable and an
+// error variable in charge of notifying any problem with the configuration of the client.
+func NewNomad(address string, region string, datacenters []string) (*Nomad, error) {
 	cfg := api.DefaultConfig()
-	client, _ := api.NewClient(cfg)
-	return &Nomad{Client: client}
+	if address != "" {
+		cfg.Address = address
+	}
+	if region != "" {
+		cfg.Region = region
+	}
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Nomad{Client: client, Region: region, Datacenters: datacenters, Logger: logging.Default()}, nil
+}
+
+// Ping function checks that the nomad agent can be reached. It takes as input the context of the
+// check, which aborts the query when it is done, and returns an error variable in charge of notifying
+// any problem.
+func (no *Nomad) Ping(ctx context.Context) error {
+	// We query the agent endpoint directly, since Agent().Self() does not accept a context
+	var self api.AgentSelf
+	_, err := no.Client.Raw().Query("/v1/agent/self", &self, (&api.QueryOptions{}).WithContext(ctx))
+	return err
 }
 
 // ExecuteJob function is responsible for the execution of a Job. It takes as input the
-// pointer of a given Job. It provides as output a pointer to the generated ResultJob and
-// an error variable in charge of notifying any problem.
-func (no *Nomad) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
+// context that allows to cancel the execution and the pointer of a given Job. If the
+// context is cancelled, the job is deregistered from nomad (which stops its allocation).
+// It provides as output a pointer to the generated ResultJob and an error variable in
+// charge of notifying any problem.
+func (no *Nomad) ExecuteJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
 
-	// TODO: Replace fmt.Prints with loggers
-	// We print the initialization message
-	fmt.Printf("Started "+job.Name+" job. Info: \n\t %+v\n\n", *job)
+	// We log the initialization message
+	logging.ForJob(no.Logger, ctx, job.Id, job.Name).WithField("image", job.Image).Info("job started")
+	defer metrics.StartJob("nomad")()
 
 	// Build nomad job from our pointer
 	taskName := "Task-" + job.Id
 	taskGroupName := "Task-Group-" + job.Id
 	nomadJob := buildJob(job, taskName, taskGroupName)
+	if no.Region != "" {
+		nomadJob.Region = &no.Region
+	}
+	if len(no.Datacenters) > 0 {
+		nomadJob.Datacenters = no.Datacenters
+	}
 
 	// We start to execute the job
+	_, registerSpan := tracing.Start(ctx, "Register")
 	jobRegisterResponse, _, err := no.Client.Jobs().Register(nomadJob, nil)
+	registerSpan.End(err)
 	if err != nil {
 		return nil, err
 	}
@@ -50,15 +93,58 @@ func (no *Nomad) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
 	// Delete job after function execution
 	defer no.Client.Jobs().Deregister(job.Id, true, nil)
 
-	// We wait for the execution to finish
-	status, err := waitForJob(job.Id, taskGroupName, no.Client.Jobs().Summary)
+	return no.followJob(ctx, job, warnings)
+}
+
+// ReconcileJob function is responsible for following a job registered in nomad before a restart
+// of the server. If the job is still known by nomad, it waits for it and collects its result as
+// ExecuteJob does, deregistering it afterwards. It takes as input the context that allows to
+// cancel the execution and the pointer of the Job. It provides as output a pointer to the
+// ResultJob (nil if nomad does not know the job) and an error variable in charge of notifying
+// any problem.
+func (no *Nomad) ReconcileJob(ctx context.Context, job *jobs.Job) (*results.ResultJob, error) {
+
+	// We look for the job in nomad
+	if _, _, err := no.Client.Jobs().Info(job.Id, nil); err != nil {
+		if strings.Contains(err.Error(), notFoundResponse) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer metrics.StartJob("nomad")()
+	logging.ForJob(no.Logger, ctx, job.Id, job.Name).Info("job reconciled with nomad")
+
+	// Delete job after function execution
+	defer no.Client.Jobs().Deregister(job.Id, true, nil)
+
+	return no.followJob(ctx, job, "")
+}
+
+// followJob function waits until a registered job finishes and collects its result. It takes as
+// input the context that allows to cancel the execution, the pointer of the Job and the warnings
+// of its registration, which are prepended to the logs. It provides as output a pointer to the
+// generated ResultJob and an error variable in charge of notifying any problem.
+func (no *Nomad) followJob(ctx context.Context, job *jobs.Job, warnings string) (*results.ResultJob, error) {
+	taskName := "Task-" + job.Id
+	taskGroupName := "Task-Group-" + job.Id
+	logger := logging.ForJob(no.Logger, ctx, job.Id, job.Name)
+
+	// We wait for the execution to finish (the allocation of the job and its run)
+	_, waitSpan := tracing.Start(ctx, "WaitForJob")
+	status, err := waitForJob(ctx, job.Id, taskGroupName, no.Client.Jobs().Summary)
+	waitSpan.End(err)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: Replace fmt.Prints with loggers
-	// We print the finalization message
-	fmt.Println("Finished " + job.Name + " job\n")
+	// If the execution has been cancelled, the deferred deregistration stops the job
+	if status == results.Cancelled {
+		logger.Info("job stopped before completion")
+		return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: warnings + "The job was stopped before completion", Status: status}, nil
+	}
+
+	// We log the finalization message
+	logger.WithField("status", status.String()).Info("job finished")
 
 	// Obtain the allocation of our job in order to later access information about its execution.
 	alloc, err := getAllocation(job.Id, no.Client.Jobs().Allocations, no.Client.Allocations().Info)
@@ -84,18 +170,6 @@ func (no *Nomad) ExecuteJob(job *jobs.Job) (*results.ResultJob, error) {
 	return &results.ResultJob{Id: job.Id, Name: job.Name, Logs: warnings + logs, Status: status}, nil
 }
 
-// argumentsToSlice function takes Hector's own parameter definitions and converts
-// them into an array of strings by adding dashes to the tags.
-func argumentsToSlice(arguments *[]definitions.Parameter) []string {
-
-	var args []string
-	for _, arg := range *arguments {
-		args = append(args, "--"+arg.Name)
-		args = append(args, fmt.Sprintf("%v", arg.Value))
-	}
-	return args
-}
-
 // buildJob function is responsible for constructing the definition of a
 // nomad's own task from the Hector's own task pointer. It takes as input
 // the pointer of a Hector Job, the name of the task and the name of the
@@ -103,7 +177,7 @@ func argumentsToSlice(arguments *[]definitions.Parameter) []string {
 func buildJob(job *jobs.Job, taskName string, taskGroupName string) *api.Job {
 
 	// 1. Task
-	args := argumentsToSlice(&job.Arguments)
+	args := executors.ArgumentsToSlice(&job.Arguments)
 	nomadTask := &api.Task{
 		Name:   taskName,
 		Driver: "docker",
@@ -136,19 +210,25 @@ func buildJob(job *jobs.Job, taskName string, taskGroupName string) *api.Job {
 
 // waitForJob function is in charge of waiting for the execution
 // of the job whose id is provided as input parameter. It takes
-// as input the job identifier, the name of the task group and
-// the function in charge of extracting the status of the job
-// during its execution in nomad. Returns the final status of
-// the job and an error variable to report any problems.
+// as input the context that allows to stop waiting, the job
+// identifier, the name of the task group and the function in
+// charge of extracting the status of the job during its
+// execution in nomad. Returns the final status of the job
+// (Cancelled if the context is cancelled first) and an error
+// variable to report any problems.
 //
 // NOTE: https://github.com/hashicorp/nomad/issues/6818
-func waitForJob(jobId string, taskGroupName string, getSummary func(string, *api.QueryOptions) (*api.JobSummary, *api.QueryMeta, error)) (results.Status, error) {
+func waitForJob(ctx context.Context, jobId string, taskGroupName string, getSummary func(string, *api.QueryOptions) (*api.JobSummary, *api.QueryMeta, error)) (results.Status, error) {
 
 	status := results.Waiting
 	for status == results.Waiting {
 
 		// We establish pauses of 10 milliseconds
-		time.Sleep(10 * time.Millisecond)
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return results.Cancelled, nil
+		}
 
 		// We obtain the most summarized information of our job (minimum amount of information found so as not to overload the loop)
 		jobSummary, _, err := getSummary(jobId, nil)
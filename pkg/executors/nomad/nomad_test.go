@@ -1,13 +1,15 @@
 package nomad
 
 import (
+	"context"
 	"dag/hector/golang/module/pkg"
 	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/jobs"
 	"dag/hector/golang/module/pkg/results"
 	"fmt"
 	"math"
-	"reflect"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
@@ -16,70 +18,6 @@ import (
 	"github.com/hashicorp/nomad/api"
 )
 
-func TestArgumentsToSlice(t *testing.T) {
-	var tests = []struct {
-		arguments *[]definitions.Parameter
-		array     []string
-	}{
-		{
-			arguments: &[]definitions.Parameter{
-				{
-					Name:  "name-1",
-					Value: "value-1",
-				},
-			},
-			array: []string{"--name-1", "value-1"},
-		},
-		{
-			arguments: &[]definitions.Parameter{
-				{
-					Name:  "name-2",
-					Value: 2,
-				},
-			},
-			array: []string{"--name-2", "2"},
-		},
-		{
-			arguments: &[]definitions.Parameter{
-				{
-					Name:  "name-3",
-					Value: true,
-				},
-			},
-			array: []string{"--name-3", "true"},
-		},
-		{
-			arguments: &[]definitions.Parameter{
-				{
-					Name:  "name-1",
-					Value: "value-1",
-				},
-				{
-					Name:  "name-2",
-					Value: 2,
-				},
-				{
-					Name:  "name-3",
-					Value: true,
-				},
-			},
-			array: []string{"--name-1", "value-1", "--name-2", "2", "--name-3", "true"},
-		},
-	}
-
-	for i, tt := range tests {
-
-		testname := "test_" + strconv.Itoa(i)
-		t.Run(testname, func(t *testing.T) {
-			args := argumentsToSlice(tt.arguments)
-			if !reflect.DeepEqual(args, tt.array) {
-				t.Error("got ", args, ", want ", tt.array)
-			}
-		})
-	}
-
-}
-
 func TestBuildJob(t *testing.T) {
 	var tests = []struct {
 		job      *jobs.Job
@@ -250,7 +188,7 @@ func TestWaitForJob(t *testing.T) {
 
 		t.Run(testname, func(t *testing.T) {
 			start := time.Now()
-			status, err := waitForJob(tt.jobId, taskGroupName, getSummaryMock)
+			status, err := waitForJob(context.Background(), tt.jobId, taskGroupName, getSummaryMock)
 			end := time.Now()
 
 			var errMsg string
@@ -271,6 +209,60 @@ func TestWaitForJob(t *testing.T) {
 	}
 }
 
+func TestWaitForCancelledJob(t *testing.T) {
+	taskGroupName := "Task-Group-Job-Id-1"
+
+	// The job never finishes
+	getSummaryMock := func(jobId string, qo *api.QueryOptions) (*api.JobSummary, *api.QueryMeta, error) {
+		return &api.JobSummary{Summary: map[string]api.TaskGroupSummary{taskGroupName: {Running: 1}}}, nil, nil
+	}
+
+	// But the wait is cancelled after 30 milliseconds
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	status, err := waitForJob(ctx, "Job-Id-1", taskGroupName, getSummaryMock)
+	if err != nil {
+		t.Error("Unexpected error detected: " + err.Error())
+	}
+	if status != results.Cancelled {
+		t.Error("The execution status is not as expected. Wanted " + fmt.Sprintf("%v", results.Cancelled) + " got " + fmt.Sprintf("%v", status))
+	}
+}
+
+func TestPingCancelled(t *testing.T) {
+
+	// The agent never answers until the test ends
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	executor, err := NewNomad(server.URL, "", nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// But the check is cancelled after 30 milliseconds
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- executor.Ping(ctx) }()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Error("Expected an error when the check is cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Error("The check did not end when its context was cancelled")
+	}
+}
+
 func TestGetAllocation(t *testing.T) {
 	type errs struct {
 		getAllAllocations bool
@@ -494,13 +486,13 @@ func TestExecuteJob(t *testing.T) {
 		},
 	}
 
-	nomad := NewNomad()
+	nomad, _ := NewNomad("", "", nil)
 
 	for i, tt := range tests {
 
 		testname := "test_" + strconv.Itoa(i)
 		t.Run(testname, func(t *testing.T) {
-			resJob, err := nomad.ExecuteJob(tt.job)
+			resJob, err := nomad.ExecuteJob(context.Background(), tt.job)
 
 			if err != nil {
 				t.Error(err)
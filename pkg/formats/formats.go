@@ -0,0 +1,166 @@
+package formats
+
+import (
+	"encoding/json"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+type Format string
+
+// We declare the supported formats by their canonical media type.
+const (
+	JSON Format = "application/json"
+	YAML Format = "application/yaml"
+)
+
+// yamlMediaTypes contains the media types that are understood as YAML.
+var yamlMediaTypes = []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}
+
+// syntaxLineRegexp extracts the line reported by the YAML parser in its syntax errors.
+var syntaxLineRegexp = regexp.MustCompile(`line (\d+): `)
+
+// SyntaxErr reports a document that cannot be decoded, together with the line where the problem
+// was found (zero if it is unknown).
+type SyntaxErr struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+// Error function applied on a variable of type SyntaxErr
+// returns the corresponding error message in the form of string,
+// prefixed by the line of the problem if it is known.
+func (e *SyntaxErr) Error() string {
+	if e.Line > 0 {
+		return "line " + strconv.Itoa(e.Line) + ": " + e.Message
+	}
+	return e.Message
+}
+
+// FromMediaType function selects the format of a body given its media type (e.g. the Content-Type
+// header). It takes as input the media type, which may carry parameters. Returns YAML for the YAML
+// media types and JSON otherwise.
+func FromMediaType(mediaType string) Format {
+	name, _, _ := mime.ParseMediaType(mediaType)
+	for _, yamlMediaType := range yamlMediaTypes {
+		if name == yamlMediaType {
+			return YAML
+		}
+	}
+	return JSON
+}
+
+// FromFileName function selects the format of a file given its extension (.yaml and .yml files are
+// YAML, the rest JSON). It takes as input the name of the file and returns the format.
+func FromFileName(name string) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return YAML
+	default:
+		return JSON
+	}
+}
+
+// Negotiate function selects the format of a response given the Accept header of the request. The
+// supported media type with the highest quality is chosen (the first one in case of a tie), and JSON
+// is used if none of them is accepted explicitly. It takes as input the value of the header and
+// returns the format.
+func Negotiate(accept string) Format {
+	format, best := JSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		name, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		if quality <= best {
+			continue
+		}
+		switch {
+		case name == string(JSON):
+			format, best = JSON, quality
+		case FromMediaType(name) == YAML:
+			format, best = YAML, quality
+		}
+	}
+	return format
+}
+
+// Unmarshal function decodes a document in the given format into a variable, using the json tags
+// of its fields in both formats. It takes as input the content of the document, its format and the
+// pointer to the variable. Returns a SyntaxErr if the document is not valid, which reports the line
+// of the problem in YAML documents.
+func Unmarshal(content []byte, format Format, v any) error {
+	if format != YAML {
+		if err := json.Unmarshal(content, v); err != nil {
+			syntaxErr := &SyntaxErr{Message: err.Error()}
+			if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+				syntaxErr.Field = fieldPath(typeErr.Field)
+			}
+			return syntaxErr
+		}
+		return nil
+	}
+
+	// We translate the YAML document into JSON, which reports the line of the syntax errors
+	jsonContent, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		syntaxErr := &SyntaxErr{Message: err.Error()}
+		if match := syntaxLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+			syntaxErr.Line, _ = strconv.Atoi(match[1])
+			syntaxErr.Message = strings.Replace(err.Error(), match[0], "", 1)
+		}
+		return syntaxErr
+	}
+
+	// The type errors are located through the path of the field
+	if err := json.Unmarshal(jsonContent, v); err != nil {
+		syntaxErr := &SyntaxErr{Message: err.Error()}
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			syntaxErr.Field = fieldPath(typeErr.Field)
+			syntaxErr.Line = Line(content, syntaxErr.Field)
+		}
+		return syntaxErr
+	}
+	return nil
+}
+
+// fieldPath function translates the field reported by the json decoder, whose indexes may be given as
+// segments (e.g. inputs.0.type), into the notation of the validation errors (e.g. inputs[0].type).
+func fieldPath(field string) string {
+	segments := strings.Split(field, ".")
+	path := ""
+	for _, segment := range segments {
+		if _, err := strconv.Atoi(segment); err == nil && path != "" {
+			path += "[" + segment + "]"
+		} else if path == "" {
+			path = segment
+		} else {
+			path += "." + segment
+		}
+	}
+	return path
+}
+
+// Marshal function encodes a variable in the given format, using the json tags of its fields in both
+// formats. It takes as input the variable and the format. Returns the encoded document and an error
+// variable to report any problems.
+func Marshal(v any, format Format) ([]byte, error) {
+	if format == YAML {
+		return yaml.Marshal(v)
+	}
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
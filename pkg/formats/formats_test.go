@@ -0,0 +1,150 @@
+package formats
+
+import (
+	"strconv"
+	"testing"
+)
+
+// specification is a YAML document with nested mappings and sequences (some of them at the same
+// indentation as their key), flow collections, block scalars and comments.
+const specification = `# Specification
+id: Spec-ID
+name: Specification
+spec:
+  description: |
+    name: not a key
+  dag:
+    tasks:
+    - name: A
+      component: Comp-A
+    - name: B
+      dependencies: [A]
+      component: Comp-B
+
+    - name: C
+      dependencies:
+        - A
+        - B
+      component: {id: Comp-C}
+apiVersion: hector/v1
+`
+
+func TestLine(t *testing.T) {
+	var tests = []struct {
+		path string
+		want int
+	}{
+		{"id", 2},
+		{"spec.dag", 7},
+		{"spec.description.name", 5},
+		{"spec.dag.tasks[0]", 9},
+		{"spec.dag.tasks[1].component", 13},
+		{"spec.dag.tasks[1].dependencies[0]", 12},
+		{"spec.dag.tasks[2].name", 15},
+		{"spec.dag.tasks[2].dependencies[1]", 18},
+		{"spec.dag.tasks[2].component.id", 19},
+		{"spec.dag.tasks[3].name", 8},
+		{"apiVersion", 20},
+		{"unknown", 0},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := Line([]byte(specification), tt.path); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestLineFlowCollections(t *testing.T) {
+	document := "id: Comp-ID\ninputs: [\n  {name: input_1, type: string},\n  {name: input_2,\n   type: [string]}\n]\n"
+
+	var tests = []struct {
+		path string
+		want int
+	}{
+		{"inputs", 2},
+		{"inputs[0].type", 3},
+		{"inputs[1]", 4},
+		{"inputs[1].type", 5},
+		{"inputs[1].type[0]", 5},
+		{"inputs[2].name", 2},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := Line([]byte(document), tt.path); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type put struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	type component struct {
+		Id     string `json:"id"`
+		Inputs []put  `json:"inputs"`
+	}
+
+	var tests = []struct {
+		content string
+		format  Format
+		fails   bool
+		line    int
+	}{
+		{"id: Comp-ID\ninputs:\n- name: input_1\n  type: string\n", YAML, false, 0},
+		{`{"id": "Comp-ID", "inputs": [{"name": "input_1", "type": "string"}]}`, JSON, false, 0},
+		{"id: Comp-ID\ninputs:\n- name: input_1\n  type: [string]\n", YAML, true, 4},
+		{"id: Comp-ID\ninputs:\n  - name: input_1\n   type: string\n", YAML, true, 3},
+		{`{"id": "Comp-ID", "inputs": {}}`, JSON, true, 0},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			var c component
+			err := Unmarshal([]byte(tt.content), tt.format, &c)
+
+			line := 0
+			if syntaxErr, ok := err.(*SyntaxErr); ok {
+				line = syntaxErr.Line
+			}
+			if err == nil && (c.Id != "Comp-ID" || len(c.Inputs) != 1 || c.Inputs[0].Type != "string") {
+				t.Error("The document has not been decoded correctly: ", c)
+			}
+			if (err != nil) != tt.fails || line != tt.line {
+				t.Error("got ", line, ": ", err, ", want the line ", tt.line)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	var tests = []struct {
+		accept string
+		want   Format
+	}{
+		{"", JSON},
+		{"*/*", JSON},
+		{"application/yaml", YAML},
+		{"text/html, application/x-yaml", YAML},
+		{"application/json, application/yaml", JSON},
+		{"application/json;q=0.5, application/yaml", YAML},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := Negotiate(tt.accept); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
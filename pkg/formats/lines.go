@@ -0,0 +1,73 @@
+package formats
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Line function finds the line of a YAML document where a field is defined. The path follows the
+// notation of the validation errors (e.g. spec.dag.tasks[1].component); fields that cannot be found
+// are reported at the line of their closest ancestor. It takes as input the content of the document
+// and the path of the field. Returns the line number (starting at 1) or zero if it cannot be found.
+func Line(content []byte, path string) int {
+	lines := fieldLines(content)
+	for {
+		if line, found := lines[path]; found {
+			return line
+		}
+		if path == "" {
+			return 0
+		}
+		path = parentPath(path)
+	}
+}
+
+// parentPath function removes the last segment of a field path (a key or an index).
+func parentPath(path string) string {
+	if strings.HasSuffix(path, "]") {
+		return path[:strings.LastIndex(path, "[")]
+	}
+	if i := strings.LastIndex(path, "."); i != -1 {
+		return path[:i]
+	}
+	return ""
+}
+
+// fieldLines function decodes the nodes of a YAML document and records the line of every key and
+// sequence item. It takes as input the document and returns the line of each path (only the root
+// is recorded if the document cannot be decoded).
+func fieldLines(document []byte) map[string]int {
+	lines := map[string]int{"": 0}
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil || len(root.Content) == 0 {
+		return lines
+	}
+	walkNode(root.Content[0], "", lines)
+	return lines
+}
+
+// walkNode function records the lines of the keys and the items of a mapping or sequence node and
+// of its nested collections. The lines of a mapping entry and of a sequence item are those of the key
+// and of the item itself. It takes as input the node, its path and the lines recorded so far.
+func walkNode(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			lines[keyPath] = key.Line
+			walkNode(value, keyPath, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := path + "[" + strconv.Itoa(i) + "]"
+			lines[itemPath] = item.Line
+			walkNode(item, itemPath, lines)
+		}
+	}
+}
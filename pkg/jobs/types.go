@@ -6,6 +6,7 @@ type Job struct {
 	Id           string
 	Name         string
 	Image        string
+	Component    string
 	Arguments    []definitions.Parameter
 	Dependencies []string
 }
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// We declare the fields that correlate the lines of a run, from the request that submitted the
+// definition to the jobs run by the executor.
+const (
+	RequestIdField    = "request_id"
+	NamespaceField    = "namespace"
+	DefinitionIdField = "definition_id"
+	JobIdField        = "job_id"
+)
+
+// RequestIdHeader is the header that carries the identifier of a request. The api reuses the one
+// sent by the client (or a proxy) and returns it in the response.
+const RequestIdHeader = "X-Request-Id"
+
+// We declare the formats of the lines.
+const (
+	JSONFormat = "json"
+	TextFormat = "text"
+)
+
+// Formats contains the valid formats of the lines.
+var Formats = []string{JSONFormat, TextFormat}
+
+// NewLogger function creates a logger that writes leveled and structured lines. It takes as input
+// the minimum level of the lines (debug, info, warn or error), their format (json or text) and the
+// writer where they are written (os.Stderr if it is nil). Returns the pointer of the logger and an
+// error variable to report any problems.
+func NewLogger(level string, format string, out io.Writer) (*logrus.Logger, error) {
+	logger := logrus.New()
+	if out == nil {
+		out = os.Stderr
+	}
+	logger.SetOutput(out)
+
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	logger.SetLevel(parsedLevel)
+
+	switch format {
+	case JSONFormat:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case TextFormat:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+	return logger, nil
+}
+
+// Default function returns the logger used by the components whose logger has not been injected.
+func Default() logrus.FieldLogger {
+	return logrus.StandardLogger()
+}
+
+// Discard function returns a logger that drops all the lines, which is useful in tests.
+func Discard() logrus.FieldLogger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// fieldsKey is the key of the correlation fields in the contexts.
+type fieldsKey struct{}
+
+// WithFields function returns a copy of the context that carries the given correlation fields
+// together with those already carried by it. It takes as input the context and the fields.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := logrus.Fields{}
+	for key, value := range Fields(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Fields function returns the correlation fields carried by a context (nil if there is none).
+func Fields(ctx context.Context) logrus.Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// RequestId function returns the identifier of the request carried by a context (empty if there
+// is none).
+func RequestId(ctx context.Context) string {
+	id, _ := Fields(ctx)[RequestIdField].(string)
+	return id
+}
+
+// FromContext function returns a logger that adds the correlation fields of a context to its
+// lines. It takes as input the logger of the component and the context.
+func FromContext(logger logrus.FieldLogger, ctx context.Context) *logrus.Entry {
+	return logger.WithFields(Fields(ctx))
+}
+
+// ForJob function returns a logger that adds the correlation fields of a context and those of a
+// job to its lines. It takes as input the logger of the component, the context and the identifier
+// and the name of the job.
+func ForJob(logger logrus.FieldLogger, ctx context.Context, jobId string, jobName string) *logrus.Entry {
+	return FromContext(logger, ctx).WithFields(logrus.Fields{JobIdField: jobId, "job": jobName})
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNewLogger(t *testing.T) {
+	var tests = []struct {
+		level  string
+		format string
+		valid  bool
+	}{
+		{"info", JSONFormat, true},
+		{"debug", TextFormat, true},
+		{"verbose", JSONFormat, false},
+		{"info", "xml", false},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			_, err := NewLogger(tt.level, tt.format, nil)
+			if (err == nil) != tt.valid {
+				t.Error("got the error ", err, " for the level ", tt.level, " and the format ", tt.format)
+			}
+		})
+	}
+}
+
+func TestForJob(t *testing.T) {
+	var out bytes.Buffer
+	logger, _ := NewLogger("info", JSONFormat, &out)
+
+	// We add the fields in two steps, so that they are merged
+	ctx := WithFields(context.Background(), logrus.Fields{RequestIdField: "req-1", DefinitionIdField: "def-1"})
+	ctx = WithFields(ctx, logrus.Fields{DefinitionIdField: "def-2", NamespaceField: "ns"})
+	if RequestId(ctx) != "req-1" {
+		t.Error("got the request id ", RequestId(ctx))
+	}
+
+	ForJob(logger, ctx, "job-1", "Job 1").Info("job started")
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := map[string]string{RequestIdField: "req-1", DefinitionIdField: "def-2", NamespaceField: "ns", JobIdField: "job-1", "job": "Job 1", "msg": "job started"}
+	for field, value := range want {
+		if line[field] != value {
+			t.Error("got ", line[field], " in the field ", field, ", want ", value)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"dag/hector/golang/module/pkg/results"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry contains the series exposed by the api in /metrics, together with the ones of the Go
+// runtime and the process.
+var Registry = prometheus.NewRegistry()
+
+// We declare the series of the definitions.
+var (
+	DefinitionsStarted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hector_definitions_started_total",
+		Help: "Number of definitions whose invocation has started.",
+	})
+	DefinitionsCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hector_definitions_completed_total",
+		Help: "Number of definitions whose jobs have all been completed successfully.",
+	})
+	DefinitionsFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hector_definitions_failed_total",
+		Help: "Number of definitions that have ended with failed jobs or whose invocation has failed.",
+	})
+)
+
+// We declare the series of the jobs and the queue.
+var (
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hector_job_duration_seconds",
+		Help:    "Duration of the executions of the jobs by component and final status.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+	}, []string{"component", "status"})
+	QueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hector_queue_depth",
+		Help: "Number of definitions waiting in the execution queue.",
+	})
+	ActiveJobs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hector_active_jobs",
+		Help: "Number of jobs being run by each executor.",
+	}, []string{"executor"})
+)
+
+// DatastoreLatency contains the durations of the operations of the datastore.
+var DatastoreLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "hector_datastore_operation_duration_seconds",
+	Help:    "Duration of the operations of the datastore.",
+	Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+}, []string{"operation"})
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		DefinitionsStarted, DefinitionsCompleted, DefinitionsFailed,
+		JobDuration, QueueDepth, ActiveJobs,
+		DatastoreLatency,
+	)
+}
+
+// Handler function returns the handler that exposes the series of the Registry in the Prometheus
+// text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// DefinitionFinished function counts a definition whose invocation has ended. It takes as input the
+// final status of the definition; the pending ones (the invocation was interrupted) and the
+// cancelled ones are not counted.
+func DefinitionFinished(status results.Status) {
+	switch status {
+	case results.Done:
+		DefinitionsCompleted.Inc()
+	case results.Error:
+		DefinitionsFailed.Inc()
+	}
+}
+
+// StartJob function counts a job that an executor has started to run. It takes as input the name
+// of the executor and returns the function that must be called when the job ends.
+func StartJob(executor string) func() {
+	gauge := ActiveJobs.WithLabelValues(executor)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// ObserveDatastore function records the duration of an operation of the datastore. It is meant to
+// be deferred at the beginning of the operation. It takes as input the name of the operation and
+// the time at which it started.
+func ObserveDatastore(operation string, start time.Time) {
+	DatastoreLatency.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
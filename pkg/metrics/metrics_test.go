@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"dag/hector/golang/module/pkg/results"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+
+	// We record a completed and a failed definition, a running job and a datastore operation
+	DefinitionFinished(results.Done)
+	DefinitionFinished(results.Error)
+	DefinitionFinished(results.Cancelled)
+	end := StartJob("test")
+	ObserveDatastore("GetComponent", time.Now())
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	var tests = []string{
+		"hector_definitions_completed_total 1",
+		"hector_definitions_failed_total 1",
+		`hector_active_jobs{executor="test"} 1`,
+		`hector_datastore_operation_duration_seconds_count{operation="GetComponent"} 1`,
+		"hector_queue_depth 0",
+		"go_goroutines",
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if !strings.Contains(body, tt) {
+				t.Error("The series " + tt + " is not exposed")
+			}
+		})
+	}
+
+	// The job is no longer active once it ends
+	end()
+	recorder = httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(recorder.Body.String(), `hector_active_jobs{executor="test"} 0`) {
+		t.Error("The ended job is still active")
+	}
+}
@@ -0,0 +1,60 @@
+package namespaces
+
+import (
+	"dag/hector/golang/module/pkg/errors"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// We declare the namespaces with a special meaning
+const (
+	// Default is the namespace used when none is specified (e.g. by the command line tools).
+	Default = "default"
+	// Shared is the namespace whose components can be referenced from any other namespace.
+	Shared = "shared"
+)
+
+// Separator divides the namespace and the identifier in qualified references (e.g. shared/count-letters).
+const Separator = "/"
+
+// nameRegexp matches the valid namespace names (DNS labels).
+var nameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// Valid function reports whether a string is a valid namespace name, that is, a DNS label made
+// of lowercase alphanumeric characters and hyphens.
+func Valid(namespace string) bool {
+	return nameRegexp.MatchString(namespace)
+}
+
+// ValidNamespace function is responsible for validating that a field contains a valid namespace
+// name. It takes as input a variable type validator.FieldLevel and returns a boolean value.
+func ValidNamespace(fl validator.FieldLevel) bool {
+	return Valid(fl.Field().String())
+}
+
+// Check function ensures that a string is a valid namespace name. It takes as input the name and
+// returns an error variable to report any problems.
+func Check(namespace string) error {
+	if !Valid(namespace) {
+		return &errors.InvalidRequestErr{Field: "namespace", Message: "invalid namespace " + namespace + ", it must be a DNS label (lowercase alphanumeric characters and hyphens)"}
+	}
+	return nil
+}
+
+// ResolveComponent function resolves the reference to a component made from a specification of
+// the given namespace. Unqualified references point to the namespace of the specification, while
+// qualified ones (e.g. shared/count-letters) can only point to the shared namespace. It takes as
+// input the namespace of the specification and the reference. Returns the namespace and the
+// identifier of the component and an error variable to report any problems.
+func ResolveComponent(namespace string, reference string) (string, string, error) {
+	refNamespace, id, qualified := strings.Cut(reference, Separator)
+	if !qualified {
+		return namespace, reference, nil
+	}
+	if refNamespace != Shared && refNamespace != namespace {
+		return "", "", &errors.ValidationErr{Code: "invalid_reference", Message: "component " + reference + " belongs to the namespace " + refNamespace + ", but only components of the namespace " + namespace + " or the " + Shared + " namespace can be used"}
+	}
+	return refNamespace, id, nil
+}
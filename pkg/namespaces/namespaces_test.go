@@ -0,0 +1,61 @@
+package namespaces
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	var tests = []struct {
+		namespace string
+		want      bool
+	}{
+		{"team-a", true},
+		{"default", true},
+		{"", false},
+		{"Team-A", false},
+		{"team_a", false},
+		{"team/a", false},
+		{"-team", false},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := Valid(tt.namespace); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveComponent(t *testing.T) {
+	var tests = []struct {
+		reference string
+		namespace string
+		id        string
+		err       string
+	}{
+		{"count-letters", "team-a", "count-letters", ""},
+		{"shared/count-letters", "shared", "count-letters", ""},
+		{"team-a/count-letters", "team-a", "count-letters", ""},
+		{"team-b/count-letters", "", "", "component team-b/count-letters belongs to the namespace team-b, but only components of the namespace team-a or the shared namespace can be used"},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			namespace, id, err := ResolveComponent("team-a", tt.reference)
+			if err == nil {
+				err = fmt.Errorf("")
+			}
+
+			if err.Error() != tt.err {
+				t.Error("got ", err, ", want ", tt.err)
+			} else if namespace != tt.namespace || id != tt.id {
+				t.Error("got ", namespace, "/", id, ", want ", tt.namespace, "/", tt.id)
+			}
+		})
+	}
+}
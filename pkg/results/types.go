@@ -1,6 +1,9 @@
 package results
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 type Status int64
 
@@ -9,8 +12,38 @@ const (
 	Done
 	Error
 	Cancelled
+	Running
 )
 
+// statusNames contains the textual representation of each status.
+var statusNames = []string{"Waiting", "Done", "Error", "Cancelled", "Running"}
+
+// String function is applied to Status variables and returns their name.
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return fmt.Sprintf("Status(%d)", int64(s))
+	}
+	return statusNames[s]
+}
+
+// Pending function is applied to Status variables and reports whether the job has not finished
+// yet, either because it is waiting for its turn or because it is being executed.
+func (s Status) Pending() bool {
+	return s == Waiting || s == Running
+}
+
+// ParseStatus function converts the name of a status into the corresponding Status value. It
+// takes as input the name of the status. Returns the status and an error variable to report
+// any problems.
+func ParseStatus(name string) (Status, error) {
+	for i, statusName := range statusNames {
+		if statusName == name {
+			return Status(i), nil
+		}
+	}
+	return Waiting, fmt.Errorf("unknown status %s", name)
+}
+
 type ResultJob struct {
 	Id     string
 	Name   string
@@ -20,8 +53,10 @@ type ResultJob struct {
 
 type ResultDefinition struct {
 	Id              string
+	Namespace       string
 	Name            string
 	SpecificationId string
+	SubmittedBy     string
 	ResultJobs      []ResultJob
 }
 
@@ -30,3 +65,30 @@ func (rdef *ResultDefinition) String() string {
 	s, _ := json.MarshalIndent(rdef, "", "  ")
 	return string(s)
 }
+
+// Status function is applied to ResultDefinition variables and returns their overall status. A
+// result definition is Running while any of its jobs is running and Waiting while any of its jobs
+// is waiting. Otherwise, it takes the status Error if any job has failed, Cancelled if any job has
+// been cancelled and Done in any other case.
+func (rdef *ResultDefinition) Status() Status {
+	status := Done
+	waiting := false
+	for _, resultJob := range rdef.ResultJobs {
+		switch resultJob.Status {
+		case Running:
+			return Running
+		case Waiting:
+			waiting = true
+		case Error:
+			status = Error
+		case Cancelled:
+			if status != Error {
+				status = Cancelled
+			}
+		}
+	}
+	if waiting {
+		return Waiting
+	}
+	return status
+}
@@ -2,8 +2,10 @@ package specifications
 
 import (
 	"dag/hector/golang/module/pkg"
+	"dag/hector/golang/module/pkg/formats"
 
 	"encoding/json"
+	"fmt"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -47,6 +49,7 @@ type Spec struct {
 
 type Specification struct {
 	Id         string `json:"id" validate:"required"`
+	Namespace  string `json:"namespace" validate:"omitempty,namespace"`
 	Name       string `json:"name" validate:"required"`
 	ApiVersion string `json:"apiVersion" validate:"required"`
 	Spec       Spec   `json:"spec" validate:"required"`
@@ -59,15 +62,16 @@ func (spec *Specification) String() string {
 }
 
 // FromFile function is applied on variables of type Specification and it is in charge of dumping
-// the content of a file in this variable. It takes as input the path of the file and returns
-// an error type variable in charge of notifying any problem.
+// the content of a file (JSON or, if its extension is .yaml or .yml, YAML) in this variable. It
+// takes as input the path of the file and returns an error type variable in charge of notifying
+// any problem.
 func (spec *Specification) FromFile(file string) error {
 	content, err := pkg.ReadFile(file)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(content, spec); err != nil {
-		return err
+	if err := formats.Unmarshal(content, formats.FromFileName(file), spec); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
 	}
 	return nil
 }
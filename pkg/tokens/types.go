@@ -0,0 +1,82 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/rs/xid"
+	"golang.org/x/exp/slices"
+)
+
+// secretPrefix makes the secrets of the tokens easy to identify (e.g. by secret scanners).
+const secretPrefix = "hector_"
+
+type Role string
+
+// We declare the roles from the least to the most privileged. Each role is granted the
+// permissions of the previous ones.
+const (
+	Viewer    Role = "viewer"
+	Submitter Role = "submitter"
+	Admin     Role = "admin"
+)
+
+// roleRanks contains the privilege level of each role.
+var roleRanks = map[Role]int{Viewer: 1, Submitter: 2, Admin: 3}
+
+// Allows function is applied to Role variables and reports whether the role is granted the
+// permissions of the required one.
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// ParseRole function converts the name of a role into the corresponding Role value. It takes
+// as input the name of the role. Returns the role and an error variable to report any problems.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, exists := roleRanks[role]; !exists {
+		return "", fmt.Errorf("unknown role %s", name)
+	}
+	return role, nil
+}
+
+// Token grants access to the api to an identity with a given role. Only the hash of its secret is
+// stored, so the secret cannot be recovered once it has been handed to the client. The token can
+// be restricted to some namespaces (if none is given, it is valid in all of them).
+type Token struct {
+	Id         string   `json:"id" validate:"isdefault"`
+	Name       string   `json:"name" validate:"required"`
+	Role       Role     `json:"role" validate:"required,oneof=viewer submitter admin"`
+	Namespaces []string `json:"namespaces,omitempty" validate:"dive,namespace"`
+	Hash       string   `json:"hash,omitempty" validate:"isdefault"`
+}
+
+// Unrestricted function is applied to Token variables and reports whether the token is valid in
+// all the namespaces.
+func (t *Token) Unrestricted() bool {
+	return len(t.Namespaces) == 0
+}
+
+// AllowsNamespace function is applied to Token variables and reports whether the token is valid
+// in the given namespace.
+func (t *Token) AllowsNamespace(namespace string) bool {
+	return t.Unrestricted() || slices.Contains(t.Namespaces, namespace)
+}
+
+// NewToken function creates a new token for the given identity and role together with its secret.
+// It takes as input the name of the identity and the role. Returns the pointer to the constructed
+// token and the secret that must be handed to the client.
+func NewToken(name string, role Role) (*Token, string) {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	secret := secretPrefix + hex.EncodeToString(bytes)
+	return &Token{Id: xid.New().String(), Name: name, Role: role, Hash: HashSecret(secret)}, secret
+}
+
+// HashSecret function returns the hash under which the token with the given secret is stored.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
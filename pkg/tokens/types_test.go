@@ -0,0 +1,67 @@
+package tokens
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRoleAllows(t *testing.T) {
+	var tests = []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{Viewer, Viewer, true},
+		{Viewer, Submitter, false},
+		{Submitter, Viewer, true},
+		{Submitter, Admin, false},
+		{Admin, Submitter, true},
+		{Role("unknown"), Viewer, false},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := tt.role.Allows(tt.required); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, secret := NewToken("alice", Submitter)
+
+	if !strings.HasPrefix(secret, secretPrefix) {
+		t.Error("The secret must start with " + secretPrefix)
+	}
+	if token.Hash != HashSecret(secret) || strings.Contains(token.Hash, secret) {
+		t.Error("The token must only store the hash of its secret")
+	}
+	if other, otherSecret := NewToken("alice", Submitter); other.Id == token.Id || otherSecret == secret {
+		t.Error("Each token must have its own identifier and secret")
+	}
+}
+
+func TestAllowsNamespace(t *testing.T) {
+	var tests = []struct {
+		namespaces []string
+		namespace  string
+		want       bool
+	}{
+		{nil, "team-a", true},
+		{[]string{"team-a", "shared"}, "team-a", true},
+		{[]string{"team-a", "shared"}, "team-b", false},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			token := Token{Name: "alice", Role: Viewer, Namespaces: tt.namespaces}
+			if got := token.AllowsNamespace(tt.namespace); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}
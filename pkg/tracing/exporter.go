@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// JSONFileExporter writes the spans to a file as JSON lines (one span per line), so that the
+// traces can be inspected offline or loaded by other tools.
+type JSONFileExporter struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewJSONFileExporter function creates a new instance of the JSONFileExporter type. The spans are
+// appended to the file, which is created if it does not exist. It takes as input the path of the
+// file and returns the pointer to the constructed variable and an error variable to report any
+// problems.
+func NewJSONFileExporter(path string) (*JSONFileExporter, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONFileExporter{file: file}, nil
+}
+
+// Export function writes a span in a line of the file. It takes as input the pointer of the span
+// and returns an error variable to report any problems.
+func (je *JSONFileExporter) Export(span *Span) error {
+	line, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+
+	je.mutex.Lock()
+	defer je.mutex.Unlock()
+	_, err = je.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close function closes the file. It returns an error variable to report any problems.
+func (je *JSONFileExporter) Close() error {
+	je.mutex.Lock()
+	defer je.mutex.Unlock()
+	return je.file.Close()
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	exporter, err := NewJSONFileExporter(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	tracer := NewTracer(exporter)
+
+	// We record a span with a child that fails
+	ctx, root := tracer.Start(context.Background(), "root")
+	root.SetAttribute("definition_id", "Def-ID")
+	_, child := Start(ctx, "child")
+	child.End(errors.New("image not found"))
+	root.End(nil)
+	root.End(nil)
+	exporter.Close()
+
+	// The spans are written in the order in which they end, once each
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer file.Close()
+	var spans []*Span
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		span := &Span{}
+		if err := json.Unmarshal(scanner.Bytes(), span); err != nil {
+			t.Fatal(err.Error())
+		}
+		spans = append(spans, span)
+	}
+
+	if len(spans) != 2 {
+		t.Fatal("got ", len(spans), " spans, want 2")
+	}
+	if spans[0].Name != "child" || spans[0].Error != "image not found" || spans[0].ParentId != root.SpanId {
+		t.Error("got the child span ", spans[0].Name, spans[0].Error, spans[0].ParentId)
+	}
+	if spans[1].Name != "root" || spans[1].Attributes["definition_id"] != "Def-ID" || spans[1].EndTime.Before(spans[1].StartTime) {
+		t.Error("got the root span ", spans[1].Name, spans[1].Attributes)
+	}
+}
@@ -0,0 +1,210 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"dag/hector/golang/module/pkg/logging"
+	"encoding/hex"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TraceparentHeader is the header that propagates the trace context of a request, following the
+// W3C Trace Context format (https://www.w3.org/TR/trace-context/).
+const TraceparentHeader = "traceparent"
+
+// validTraceparent contains the trace contexts accepted from the clients (version 00).
+var validTraceparent = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// Exporter is implemented by the destinations of the spans. The spans are exported once they end.
+type Exporter interface {
+	Export(span *Span) error
+}
+
+// Tracer creates the spans of the components and sends them to its exporter once they end.
+type Tracer struct {
+	Exporter Exporter
+
+	// Logger writes the spans that cannot be exported.
+	Logger logrus.FieldLogger
+}
+
+// NewTracer function creates a new instance of the Tracer type. It takes as input the exporter of
+// the spans and returns the pointer to the constructed variable.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{Exporter: exporter, Logger: logging.Default()}
+}
+
+// SpanContext identifies a span within its trace, so that it can be the parent of other spans.
+type SpanContext struct {
+	TraceId string
+	SpanId  string
+}
+
+// Span is a timed operation of a trace. A nil span (created without a tracer) ignores all the
+// calls, so that the components do not have to check whether tracing is enabled.
+type Span struct {
+	TraceId    string            `json:"traceId"`
+	SpanId     string            `json:"spanId"`
+	ParentId   string            `json:"parentId,omitempty"`
+	Name       string            `json:"name"`
+	StartTime  time.Time         `json:"start"`
+	EndTime    time.Time         `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	tracer *Tracer
+	mutex  sync.Mutex
+	ended  bool
+}
+
+// We declare the keys of the span and the remote parent carried by the contexts.
+type spanKey struct{}
+type remoteKey struct{}
+
+// Start function creates a span that is the child of the span carried by the context, or of the
+// remote parent carried by it (a new trace is started otherwise). It takes as input the context and
+// the name of the span. Returns a copy of the context that carries the new span and the span itself
+// (nil if the tracer is nil).
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{Name: name, SpanId: newId(8), StartTime: time.Now(), tracer: t}
+	if parent, ok := parentContext(ctx); ok {
+		span.TraceId = parent.TraceId
+		span.ParentId = parent.SpanId
+	} else {
+		span.TraceId = newId(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Start function creates a span that is the child of the span carried by the context, with the same
+// tracer. It allows the components that are called within a span (e.g. the executors) to add their
+// own spans without holding a tracer. It takes as input the context and the name of the span.
+// Returns a copy of the context that carries the new span and the span itself (nil if the context
+// does not carry a span).
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name)
+}
+
+// FromContext function returns the span carried by a context (nil if there is none).
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttribute function records an attribute of the span. It takes as input the key and the value
+// of the attribute.
+func (s *Span) SetAttribute(key string, value string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+}
+
+// End function ends the span and exports it. Only the first call has effect. It takes as input the
+// error of the operation (nil if it has succeeded).
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	if err != nil {
+		s.Error = err.Error()
+	}
+	s.mutex.Unlock()
+
+	if s.tracer.Exporter == nil {
+		return
+	}
+	if exportErr := s.tracer.Exporter.Export(s); exportErr != nil && s.tracer.Logger != nil {
+		s.tracer.Logger.WithError(exportErr).WithField("span", s.Name).Warn("cannot export the span")
+	}
+}
+
+// Context function returns the identifiers of the span within its trace.
+func (s *Span) Context() SpanContext {
+	return SpanContext{TraceId: s.TraceId, SpanId: s.SpanId}
+}
+
+// WithRemoteParent function returns a copy of the context whose new spans are children of a span
+// created by another process (e.g. the client of a request). It takes as input the context and the
+// trace context of the parent in the traceparent format. The context is returned unchanged if the
+// trace context is not valid.
+func WithRemoteParent(ctx context.Context, traceparent string) context.Context {
+	parent, ok := ParseTraceparent(traceparent)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, parent)
+}
+
+// Traceparent function returns the trace context of the span carried by a context in the
+// traceparent format, so that it can be propagated (empty if the context does not carry a span).
+func Traceparent(ctx context.Context) string {
+	span := FromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return "00-" + span.TraceId + "-" + span.SpanId + "-01"
+}
+
+// ParseTraceparent function extracts the identifiers of a span from its trace context in the
+// traceparent format. It takes as input the trace context and returns the identifiers and whether
+// the trace context is valid.
+func ParseTraceparent(traceparent string) (SpanContext, bool) {
+	matches := validTraceparent.FindStringSubmatch(traceparent)
+	if matches == nil || isZero(matches[1]) || isZero(matches[2]) {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceId: matches[1], SpanId: matches[2]}, true
+}
+
+// parentContext function returns the identifiers of the parent of the spans created with a context:
+// the span carried by it or, if there is none, its remote parent.
+func parentContext(ctx context.Context) (SpanContext, bool) {
+	if span := FromContext(ctx); span != nil {
+		return span.Context(), true
+	}
+	parent, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return parent, ok
+}
+
+// newId function generates a random identifier of the given number of bytes, encoded in hexadecimal.
+func newId(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// isZero function checks whether an identifier only contains zeros, which is not valid.
+func isZero(id string) bool {
+	for _, c := range id {
+		if c != '0' {
+			return false
+		}
+	}
+	return true
+}
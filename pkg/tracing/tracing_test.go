@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	var tests = []struct {
+		traceparent string
+		want        SpanContext
+		valid       bool
+	}{
+		{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", SpanContext{TraceId: "0af7651916cd43dd8448eb211c80319c", SpanId: "b7ad6b7169203331"}, true},
+		{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00", SpanContext{TraceId: "0af7651916cd43dd8448eb211c80319c", SpanId: "b7ad6b7169203331"}, true},
+		{"", SpanContext{}, false},
+		{"01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", SpanContext{}, false},
+		{"00-0AF7651916CD43DD8448EB211C80319C-b7ad6b7169203331-01", SpanContext{}, false},
+		{"00-00000000000000000000000000000000-b7ad6b7169203331-01", SpanContext{}, false},
+		{"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01", SpanContext{}, false},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			got, valid := ParseTraceparent(tt.traceparent)
+			if valid != tt.valid || got != tt.want {
+				t.Error("got ", got, valid, ", want ", tt.want, tt.valid)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	tracer := NewTracer(nil)
+
+	t.Run("test_0", func(t *testing.T) {
+
+		// A span without parent starts a new trace, and its children belong to it
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := Start(ctx, "child")
+		if root.ParentId != "" || len(root.TraceId) != 32 || len(root.SpanId) != 16 {
+			t.Error("got the root span ", root.Context(), root.ParentId)
+		}
+		if child.TraceId != root.TraceId || child.ParentId != root.SpanId {
+			t.Error("got the child span ", child.Context(), child.ParentId)
+		}
+		if parent, _ := ParseTraceparent(Traceparent(ctx)); parent != root.Context() {
+			t.Error("got the trace context ", Traceparent(ctx))
+		}
+	})
+
+	t.Run("test_1", func(t *testing.T) {
+
+		// A span whose parent was created by another process belongs to its trace
+		ctx := WithRemoteParent(context.Background(), "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		_, span := tracer.Start(ctx, "span")
+		if span.TraceId != "0af7651916cd43dd8448eb211c80319c" || span.ParentId != "b7ad6b7169203331" {
+			t.Error("got the span ", span.Context(), span.ParentId)
+		}
+	})
+
+	t.Run("test_2", func(t *testing.T) {
+
+		// Without a tracer nothing is recorded, and the spans can be used anyway
+		var disabled *Tracer
+		ctx, span := disabled.Start(context.Background(), "span")
+		_, child := Start(ctx, "child")
+		span.SetAttribute("key", "value")
+		span.End(nil)
+		if span != nil || child != nil || Traceparent(ctx) != "" {
+			t.Error("got the spans ", span, child)
+		}
+	})
+}
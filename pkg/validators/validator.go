@@ -1,11 +1,16 @@
 package validators
 
 import (
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/components"
 	"dag/hector/golang/module/pkg/definitions"
+	"dag/hector/golang/module/pkg/errors"
+	"dag/hector/golang/module/pkg/namespaces"
 	"dag/hector/golang/module/pkg/specifications"
+	"dag/hector/golang/module/pkg/tokens"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"golang.org/x/exp/slices"
@@ -23,6 +28,7 @@ func NewValidator() *Validator {
 	v := validator.New()
 	v.RegisterValidation("representsType", components.RepresentsType)
 	v.RegisterValidation("validDependencies", specifications.ValidDependencies)
+	v.RegisterValidation("namespace", namespaces.ValidNamespace)
 
 	val.Validator = v
 
@@ -30,58 +36,179 @@ func NewValidator() *Validator {
 }
 
 // ValidateComponentStruct function is responsible for validating the content of a Component. It takes
-// as input the pointer to the Component and returns an error variable in charge of notifying any problem.
+// as input the pointer to the Component and returns an error variable in charge of notifying all the violations.
 func (val *Validator) ValidateComponentStruct(component *components.Component) error {
 	v := val.Validator
 	componentErr := v.Struct(*component)
-	return componentErr
+	return structViolations(component, componentErr)
 }
 
 // ValidateSpecificationStruct function is responsible for validating the content of a Specification. It takes
-// as input the pointer to the Specification and returns an error variable in charge of notifying any problem.
+// as input the pointer to the Specification and returns an error variable in charge of notifying all the violations.
 func (val *Validator) ValidateSpecificationStruct(specification *specifications.Specification) error {
 	v := val.Validator
 	specificationErr := v.Struct(*specification)
-	return specificationErr
+	return structViolations(specification, specificationErr)
 }
 
 // ValidateDefinitionStruct function is responsible for validating the content of a Definition. It takes
-// as input the pointer to the Definition and returns an error variable in charge of notifying any problem.
+// as input the pointer to the Definition and returns an error variable in charge of notifying all the violations.
 func (val *Validator) ValidateDefinitionStruct(definition *definitions.Definition) error {
 	v := val.Validator
 	definitionErr := v.Struct(*definition)
-	return definitionErr
+	return structViolations(definition, definitionErr)
+}
+
+// ValidateTokenStruct function is responsible for validating the content of a Token. It takes
+// as input the pointer to the Token and returns an error variable in charge of notifying all the violations.
+func (val *Validator) ValidateTokenStruct(token *tokens.Token) error {
+	v := val.Validator
+	tokenErr := v.Struct(*token)
+	return structViolations(token, tokenErr)
+}
+
+// ValidateBundleStruct function is responsible for validating the content of all the elements of a
+// Bundle. It takes as input the pointer to the Bundle and returns an error variable in charge of
+// notifying all the violations, whose pointers are relative to the bundle (e.g. /components/1/name).
+func (val *Validator) ValidateBundleStruct(bundle *bundles.Bundle) error {
+	if bundle.Empty() {
+		return Violations{{Pointer: "", Code: EmptyBundleCode, Message: "the bundle does not contain any element"}}
+	}
+	var violations Violations
+	for i := range bundle.Components {
+		if err := violations.Merge(val.ValidateComponentStruct(&bundle.Components[i]), fmt.Sprintf("/components/%d", i)); err != nil {
+			return err
+		}
+	}
+	if bundle.Specification != nil {
+		if err := violations.Merge(val.ValidateSpecificationStruct(bundle.Specification), "/specification"); err != nil {
+			return err
+		}
+	}
+	if bundle.Definition != nil {
+		if err := violations.Merge(val.ValidateDefinitionStruct(bundle.Definition), "/definition"); err != nil {
+			return err
+		}
+	}
+	return violations.Err()
+}
+
+// ValidateDefinitionTasksStruct function is responsible for validating the content of an array of
+// definition tasks sent outside of a Definition. It takes as input the pointer to the array and
+// returns an error variable in charge of notifying all the violations, whose pointers are relative
+// to the array.
+func (val *Validator) ValidateDefinitionTasksStruct(tasks *[]definitions.DefinitionTask) error {
+	v := val.Validator
+	var violations Violations
+	for i, task := range *tasks {
+		if err := violations.Merge(structViolations(&task, v.Struct(task)), fmt.Sprintf("/%d", i)); err != nil {
+			return err
+		}
+	}
+	return violations.Err()
 }
 
 // ValidateDefinitionTaskNames function ensures the concordance between the name of the tasks provided
 // in the Definition and those stored in the corresponding Specification. It takes as input a pointer
 // to the array of tasks from the definition and a pointer to the array of tasks from the specification.
-// It returns an error variable in charge of notifying any problem.
+// It returns an error variable in charge of notifying all the missing tasks.
 func (val *Validator) ValidateDefinitionTaskNames(definitionTaskArray *[]definitions.DefinitionTask, specificationTaskArray *[]specifications.SpecificationTask) error {
+	var violations Violations
 	for _, specificationTask := range *specificationTaskArray {
 		idxDefinitionTask := slices.IndexFunc(*definitionTaskArray, func(t definitions.DefinitionTask) bool { return t.Name == specificationTask.Name })
 		if idxDefinitionTask == -1 {
-			return fmt.Errorf("task %s is required in the selected specification but is not present in the definition file", specificationTask.Name)
+			violations = append(violations, Violation{Pointer: "/data/tasks", Code: MissingTaskCode, Message: fmt.Sprintf("task %s is required in the selected specification but is not present in the definition file", specificationTask.Name)})
 		}
 	}
-	return nil
+	return violations.Err()
+}
+
+// ValidateComponentReferences function ensures that the tasks of a Specification only reference
+// components of its own namespace or of the shared one. It takes as input the pointer to the
+// Specification and returns an error variable in charge of notifying all the invalid references.
+func (val *Validator) ValidateComponentReferences(specification *specifications.Specification) error {
+	var violations Violations
+	for i, task := range specification.Spec.Dag.Tasks {
+		if _, _, err := namespaces.ResolveComponent(specification.Namespace, task.Component); err != nil {
+			validationErr, ok := err.(*errors.ValidationErr)
+			if !ok {
+				return err
+			}
+			violations = append(violations, Violation{Pointer: fmt.Sprintf("/spec/dag/tasks/%d/component", i), Code: validationErr.Code, Message: validationErr.Message})
+		}
+	}
+	return violations.Err()
 }
 
 // ValidateDefinitionParameters function checks the agreement between the parameters set in the definition
 // with those stored in the corresponding specification. It ensures the proper presence of names and that
 // the value entered in the definition is of the appropriate type. It takes as input a pointer to the array
 // of parameters from the definition and a pointer to the array of parameters from the specification. It
-// returns an error variable in charge of notifying any problem.
+// returns an error variable in charge of notifying all the violations, whose pointers are relative to the
+// array of parameters from the definition.
 func (val *Validator) ValidateDefinitionParameters(definitionParameterArray *[]definitions.Parameter, specificationPutArray *[]components.Put) error {
+	var violations Violations
 	for _, componentPut := range *specificationPutArray {
 		idxDefinitionParameter := slices.IndexFunc(*definitionParameterArray, func(p definitions.Parameter) bool { return p.Name == componentPut.Name })
 		if idxDefinitionParameter == -1 {
-			return fmt.Errorf("parameter %s is required but is not present in the definition file", componentPut.Name)
+			violations = append(violations, Violation{Pointer: "", Code: MissingParameterCode, Message: fmt.Sprintf("parameter %s is required but is not present in the definition file", componentPut.Name)})
+			continue
 		}
 		definitionParameter := (*definitionParameterArray)[idxDefinitionParameter]
 		if reflect.TypeOf(definitionParameter.Value).String() != componentPut.Type {
-			return fmt.Errorf("parameter %s has an invalid value in the definition file", componentPut.Name)
+			violations = append(violations, Violation{Pointer: fmt.Sprintf("/%d/value", idxDefinitionParameter), Code: InvalidTypeCode, Message: fmt.Sprintf("parameter %s has an invalid value in the definition file", componentPut.Name)})
+		}
+	}
+	return violations.Err()
+}
+
+// structViolations function converts the error reported by the go-playground validator into the
+// violations of the element, one for each invalid field, whose pointers are the json paths of the
+// fields. It takes as input the pointer to the validated element and the error reported. Returns the
+// converted error.
+func structViolations(element any, err error) error {
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok || len(validationErrs) == 0 {
+		return err
+	}
+	violations := make(Violations, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		violations = append(violations, ruleViolation(jsonPointer(reflect.TypeOf(element), fieldErr.StructNamespace()), fieldErr))
+	}
+	return violations
+}
+
+// jsonPointer function translates the namespace of a struct field (e.g. Component.Inputs[1].Type) into
+// the pointer of the field in the json document (e.g. /inputs/1/type). It takes as input the type of
+// the root struct and the namespace. Returns the json pointer.
+func jsonPointer(t reflect.Type, namespace string) string {
+	var pointer string
+	for _, segment := range strings.Split(namespace, ".")[1:] {
+
+		// Separate the name of the field from its indexes (if any)
+		name, indexes := segment, ""
+		if i := strings.Index(segment, "["); i != -1 {
+			name, indexes = segment[:i], segment[i:]
+		}
+		indexes = strings.NewReplacer("[", "/", "]", "").Replace(indexes)
+
+		// Search the field in the current struct type
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		field, found := t.FieldByName(name)
+		if !found {
+			pointer += "/" + name + indexes
+			continue
+		}
+
+		// Use the json name of the field
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "" {
+			jsonName = name
 		}
+		pointer += "/" + jsonName + indexes
+		t = field.Type
 	}
-	return nil
+	return pointer
 }
@@ -1,6 +1,7 @@
 package validators
 
 import (
+	"dag/hector/golang/module/pkg/bundles"
 	"dag/hector/golang/module/pkg/components"
 	"dag/hector/golang/module/pkg/definitions"
 	"dag/hector/golang/module/pkg/specifications"
@@ -8,6 +9,8 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+
+	"golang.org/x/exp/slices"
 )
 
 func TestValidateComponentStruct(t *testing.T) {
@@ -49,13 +52,19 @@ func TestValidateComponentStruct(t *testing.T) {
 	json.Unmarshal(strGoodComponent, &badComponent3)
 	badComponent3.Outputs[0].Type = "bad type"
 
+	badComponent4 := components.Component{}
+	json.Unmarshal(strGoodComponent, &badComponent4)
+	badComponent4.Inputs[1].Type = ""
+	badComponent4.ContainerImage = ""
+
 	var tests = []struct {
 		component *components.Component
 		want      string
 	}{
-		{&badComponent1, "Key: 'Component.Inputs[1].Type' Error:Field validation for 'Type' failed on the 'required' tag"},
-		{&badComponent2, "Key: 'Component.ContainerImage' Error:Field validation for 'ContainerImage' failed on the 'required' tag"},
-		{&badComponent3, "Key: 'Component.Outputs[0].Type' Error:Field validation for 'Type' failed on the 'representsType' tag"},
+		{&badComponent1, "type is required"},
+		{&badComponent2, "containerImage is required"},
+		{&badComponent3, "type must be one of the types string, int, float or bool"},
+		{&badComponent4, "type is required; containerImage is required"},
 		{&goodComponent, ""},
 	}
 
@@ -126,9 +135,9 @@ func TestValidateSpecificationStruct(t *testing.T) {
 		specification *specifications.Specification
 		want          string
 	}{
-		{&badSpecification1, "Key: 'Specification.Spec.Dag.Tasks[1].Component' Error:Field validation for 'Component' failed on the 'required' tag"},
-		{&badSpecification2, "Key: 'Specification.Id' Error:Field validation for 'Id' failed on the 'required' tag"},
-		{&badSpecification3, "Key: 'Specification.Spec.Dag.Tasks' Error:Field validation for 'Tasks' failed on the 'validDependencies' tag"},
+		{&badSpecification1, "component is required"},
+		{&badSpecification2, "id is required"},
+		{&badSpecification3, "tasks contain dependencies on tasks that are not defined"},
 		{&goodSpecification, ""},
 	}
 
@@ -224,9 +233,9 @@ func TestValidateDefinitionStruct(t *testing.T) {
 		definition *definitions.Definition
 		want       string
 	}{
-		{&badDefinition1, "Key: 'Definition.Data.Tasks[0].Inputs[1].Name' Error:Field validation for 'Name' failed on the 'required' tag"},
-		{&badDefinition2, "Key: 'Definition.Data.Tasks[2].Inputs[0].Value' Error:Field validation for 'Value' failed on the 'required' tag"},
-		{&badDefinition3, "Key: 'Definition.Data.Tasks[1].Name' Error:Field validation for 'Name' failed on the 'required' tag"},
+		{&badDefinition1, "name is required"},
+		{&badDefinition2, "value is required"},
+		{&badDefinition3, "name is required"},
 		{&goodDefinition, ""},
 	}
 
@@ -293,12 +302,17 @@ func TestValidateDefinitionTaskNames(t *testing.T) {
 	json.Unmarshal(strGoodDefinitionTaskNames, &badDefinitionTaskNames2)
 	badDefinitionTaskNames2.Data.Tasks[1].Name = "Bad Task Name"
 
+	badDefinitionTaskNames3 := definitions.Definition{}
+	json.Unmarshal(strGoodDefinitionTaskNames, &badDefinitionTaskNames3)
+	badDefinitionTaskNames3.Data.Tasks = nil
+
 	var tests = []struct {
 		definition *definitions.Definition
 		want       string
 	}{
 		{&badDefinitionTaskNames1, "task A is required in the selected specification but is not present in the definition file"},
 		{&badDefinitionTaskNames2, "task B is required in the selected specification but is not present in the definition file"},
+		{&badDefinitionTaskNames3, "task A is required in the selected specification but is not present in the definition file; task B is required in the selected specification but is not present in the definition file"},
 		{&goodDefinitionTaskNames, ""},
 	}
 
@@ -386,6 +400,11 @@ func TestValidateDefinitionParameters(t *testing.T) {
 	badTaskDefinition3.Inputs[1].Value = int(badTaskDefinition3.Inputs[1].Value.(float64))
 	badTaskDefinition3.Outputs[0] = definitions.Parameter{}
 
+	badTaskDefinition4 := definitions.DefinitionTask{}
+	json.Unmarshal(strGoodTaskDefinition, &badTaskDefinition4)
+	badTaskDefinition4.Inputs[0].Name = "Bad Name"
+	badTaskDefinition4.Inputs[1].Value = "test"
+
 	var tests = []struct {
 		definitionTask *definitions.DefinitionTask
 		want           string
@@ -393,6 +412,7 @@ func TestValidateDefinitionParameters(t *testing.T) {
 		{&badTaskDefinition1, "parameter input_1 is required but is not present in the definition file"},
 		{&badTaskDefinition2, "parameter input_2 has an invalid value in the definition file"},
 		{&badTaskDefinition3, "parameter output_1 is required but is not present in the definition file"},
+		{&badTaskDefinition4, "parameter input_1 is required but is not present in the definition file; parameter input_2 has an invalid value in the definition file"},
 		{&goodTaskDefinition, ""},
 	}
 
@@ -415,3 +435,107 @@ func TestValidateDefinitionParameters(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateDefinitionTasksStruct(t *testing.T) {
+
+	var tests = []struct {
+		tasks    []definitions.DefinitionTask
+		pointers []string
+	}{
+		{[]definitions.DefinitionTask{{Name: "A", Inputs: []definitions.Parameter{{Name: "input_1", Value: "value"}}}}, nil},
+		{[]definitions.DefinitionTask{{Name: "A"}, {Inputs: []definitions.Parameter{{Name: "input_1", Value: "value"}}}}, []string{"/1/name"}},
+		{[]definitions.DefinitionTask{{Name: "A", Inputs: []definitions.Parameter{{Name: "input_1"}}}}, []string{"/0/inputs/0/value"}},
+		{[]definitions.DefinitionTask{{Inputs: []definitions.Parameter{{Value: "value"}}}, {Name: "B", Outputs: []definitions.Parameter{{Name: "output_1"}}}}, []string{"/0/name", "/0/inputs/0/name", "/1/outputs/0/value"}},
+	}
+
+	validator := NewValidator()
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			tasksValidatorErr := validator.ValidateDefinitionTasksStruct(&tt.tasks)
+
+			var pointers []string
+			if violations, ok := tasksValidatorErr.(Violations); ok {
+				for _, violation := range violations {
+					pointers = append(pointers, violation.Pointer)
+				}
+			} else if tasksValidatorErr != nil {
+				t.Fatal("unexpected error ", tasksValidatorErr)
+			}
+			if !slices.Equal(pointers, tt.pointers) {
+				t.Error("got ", pointers, ", want ", tt.pointers)
+			}
+		})
+	}
+}
+
+func TestValidateBundleStruct(t *testing.T) {
+	goodComponent := components.Component{
+		Id:                  "Component ID",
+		Name:                "Component Name",
+		ApiVersion:          "hector/v1",
+		ContainerDockerfile: "components/component_file.dockerfile",
+		ContainerImage:      "image/name",
+	}
+	badComponent := goodComponent
+	badComponent.Name = ""
+
+	var tests = []struct {
+		bundle  bundles.Bundle
+		pointer string
+		code    string
+		want    string
+	}{
+		{bundles.Bundle{Components: []components.Component{goodComponent}}, "", "", ""},
+		{bundles.Bundle{}, "", EmptyBundleCode, "the bundle does not contain any element"},
+		{bundles.Bundle{Components: []components.Component{goodComponent, badComponent}}, "/components/1/name", "required", "name is required"},
+		{bundles.Bundle{Definition: &definitions.Definition{Name: "Definition Name", ApiVersion: "hector/v1"}}, "/definition/specificationId", "required", "specificationId is required"},
+	}
+
+	validator := NewValidator()
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			bundleErr := validator.ValidateBundleStruct(&tt.bundle)
+
+			var pointer, code string
+			if violations, ok := bundleErr.(Violations); ok {
+				pointer, code = violations[0].Pointer, violations[0].Code
+			}
+			if bundleErr == nil {
+				bundleErr = fmt.Errorf("")
+			}
+			if bundleErr.Error() != tt.want || pointer != tt.pointer || code != tt.code {
+				t.Error("got ", pointer, " ", code, ": ", bundleErr, ", want ", tt.pointer, " ", tt.code, ": ", tt.want)
+			}
+		})
+	}
+}
+
+func TestViolationField(t *testing.T) {
+
+	var tests = []struct {
+		pointer string
+		field   string
+	}{
+		{"", ""},
+		{"/specificationId", "specificationId"},
+		{"/data/tasks/2/inputs/0/value", "data.tasks[2].inputs[0].value"},
+		{"/components/1/name", "components[1].name"},
+	}
+
+	for i, tt := range tests {
+
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			field := Violation{Pointer: tt.pointer}.Field()
+			if field != tt.field {
+				t.Error("got ", field, ", want ", tt.field)
+			}
+		})
+	}
+}
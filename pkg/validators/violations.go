@@ -0,0 +1,169 @@
+package validators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// We declare the codes of the violations found by the validator beyond the rules of the structs,
+// whose violations are identified by the name of the rule (e.g. required or represents_type).
+const (
+	EmptyBundleCode      = "empty_bundle"
+	MissingTaskCode      = "missing_task"
+	MissingParameterCode = "missing_parameter"
+	InvalidTypeCode      = "invalid_type"
+	InvalidReferenceCode = "invalid_reference"
+	InvalidValueCode     = "invalid_value"
+	NotFoundCode         = "not_found"
+)
+
+// Violation is each one of the problems found in a validated document.
+type Violation struct {
+	Pointer string `json:"pointer"` // JSON pointer of the invalid value (e.g. /data/tasks/2/inputs/0/value)
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"` // Line of the value in YAML documents (zero if it is unknown)
+}
+
+// Field function returns the path of the invalid value in the notation of the validation errors
+// (e.g. data.tasks[2].inputs[0].value), which allows to locate it in YAML documents.
+func (v Violation) Field() string {
+	var field string
+	for _, token := range splitPointer(v.Pointer) {
+		if _, err := strconv.Atoi(token); err == nil {
+			field += "[" + token + "]"
+		} else if field == "" {
+			field = token
+		} else {
+			field += "." + token
+		}
+	}
+	return field
+}
+
+// Violations is the error reported when a document is not valid. It contains all the problems found
+// in the document, so that they can be fixed at once.
+type Violations []Violation
+
+// Error function applied on a variable of type Violations returns the messages of all the violations
+// in the form of string.
+func (v Violations) Error() string {
+	var messages []string
+	for _, violation := range v {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Err function returns the violations as an error, or nil if there is none.
+func (v Violations) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Prefix function returns a copy of the violations whose pointers are relative to a part of the
+// document, completed with the pointer of that part (e.g. /data/tasks/2/inputs). It takes as input
+// the pointer of the part.
+func (v Violations) Prefix(pointer string) Violations {
+	prefixed := make(Violations, len(v))
+	for i, violation := range v {
+		violation.Pointer = pointer + violation.Pointer
+		prefixed[i] = violation
+	}
+	return prefixed
+}
+
+// Merge function adds the violations reported by a validation to the current ones. It takes as input
+// the error of the validation and the pointer of the validated part of the document, which completes
+// the pointers of its violations. Returns the error itself if it is not caused by violations (nil
+// otherwise).
+func (v *Violations) Merge(err error, pointer string) error {
+	if err == nil {
+		return nil
+	}
+	violations, ok := err.(Violations)
+	if !ok {
+		return err
+	}
+	*v = append(*v, violations.Prefix(pointer)...)
+	return nil
+}
+
+// Prefix function completes the pointers of the violations reported by a validation whose document is
+// a part of a larger one. It takes as input the error of the validation and the pointer of the part.
+// Returns the completed error (other errors are returned unchanged).
+func Prefix(err error, pointer string) error {
+	if violations, ok := err.(Violations); ok {
+		return violations.Prefix(pointer)
+	}
+	return err
+}
+
+// splitPointer function returns the unescaped reference tokens of a JSON pointer.
+func splitPointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens
+}
+
+// ruleViolation function converts the failure of a rule reported by the go-playground validator into
+// a violation. It takes as input the pointer of the invalid field and the failure. Returns the violation.
+func ruleViolation(pointer string, fieldErr validator.FieldError) Violation {
+	tokens := splitPointer(pointer)
+	name := "the document"
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if _, err := strconv.Atoi(tokens[i]); err != nil {
+			name = tokens[i]
+			break
+		}
+	}
+
+	var message string
+	switch fieldErr.Tag() {
+	case "required":
+		message = name + " is required"
+	case "isdefault":
+		message = name + " cannot be set, it is assigned by the server"
+	case "representsType":
+		message = name + " must be one of the types string, int, float or bool"
+	case "validDependencies":
+		message = name + " contain dependencies on tasks that are not defined"
+	case "namespace":
+		message = name + " must be a DNS label (lowercase alphanumeric characters and hyphens)"
+	case "url":
+		message = name + " must be a valid url"
+	case "min":
+		message = name + " must contain at least " + fieldErr.Param() + " element(s)"
+	case "oneof":
+		message = name + " must be one of " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	default:
+		message = fmt.Sprintf("%s does not satisfy the rule %s", name, fieldErr.Tag())
+	}
+	return Violation{Pointer: pointer, Code: snakeCase(fieldErr.Tag()), Message: message}
+}
+
+// snakeCase function converts the name of a rule (e.g. representsType) into a machine code (e.g.
+// represents_type).
+func snakeCase(name string) string {
+	var code strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				code.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		code.WriteRune(r)
+	}
+	return code.String()
+}
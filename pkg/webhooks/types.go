@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"dag/hector/golang/module/pkg/results"
+	"encoding/hex"
+	"time"
+)
+
+// We declare the headers sent together with each payload, so that the receivers can check
+// its origin and discard the deliveries that they have already processed.
+const (
+	SignatureHeader = "X-Hector-Signature"
+	EventHeader     = "X-Hector-Event"
+	DeliveryHeader  = "X-Hector-Delivery"
+)
+
+type Event string
+
+// We declare the kinds of events notified by the webhooks.
+const (
+	JobEvent        Event = "job"
+	DefinitionEvent Event = "definition"
+)
+
+// Payload is the body of the requests sent to the webhooks when a job or a whole definition
+// reaches a final status (Done, Error or Cancelled).
+type Payload struct {
+	Event            Event                     `json:"event"`
+	Namespace        string                    `json:"namespace"`
+	DefinitionId     string                    `json:"definitionId"`
+	Status           string                    `json:"status"`
+	ResultJob        *results.ResultJob        `json:"resultJob,omitempty"`
+	ResultDefinition *results.ResultDefinition `json:"resultDefinition,omitempty"`
+	Timestamp        time.Time                 `json:"timestamp"`
+}
+
+// Delivery is a payload pending to be sent to a webhook. It is kept in the datastore until the
+// webhook accepts it, so that failed deliveries can be retried later. Deliveries that exhaust
+// their attempts are kept as failed until their retention period expires.
+type Delivery struct {
+	Id          string    `json:"id"`
+	Url         string    `json:"url"`
+	Event       Event     `json:"event"`
+	Body        string    `json:"body"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+	Failed      bool      `json:"failed"`
+	FailedAt    time.Time `json:"failedAt,omitempty"`
+}
+
+// Sign function computes the signature of a payload, which is sent in the SignatureHeader
+// header. It takes as input the shared secret and the body of the request. Returns the
+// hexadecimal HMAC-SHA256 of the body prefixed by the name of the algorithm (sha256=...).
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify function reports whether a signature corresponds to a payload. It takes as input the
+// shared secret, the body of the request and the received signature.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
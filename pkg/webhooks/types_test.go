@@ -0,0 +1,32 @@
+package webhooks
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"job"}`)
+	signature := Sign("secret", body)
+
+	var tests = []struct {
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"secret", body, signature, true},
+		{"other secret", body, signature, false},
+		{"secret", []byte(`{"event":"definition"}`), signature, false},
+		{"secret", body, "", false},
+	}
+
+	for i, tt := range tests {
+		testname := "test_" + strconv.Itoa(i)
+		t.Run(testname, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Error("got ", got, ", want ", tt.want)
+			}
+		})
+	}
+}